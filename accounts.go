@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ============================================================================
+// 多账户持仓
+// ============================================================================
+
+// 账户名称常量
+const (
+	defaultAccountName = "default" // 默认账户，数据保存在 data/portfolio.json（兼容旧版）
+	allAccountsName    = "all"     // 合并视图（所有账户按股票代码合并，只读）
+	maxAccountNameLen  = 32
+)
+
+// accountFile 获取账户的持仓文件路径
+func accountFile(name string) string {
+	if name == "" || name == defaultAccountName {
+		return dataFile
+	}
+	return filepath.Join(portfoliosDir, name+".json")
+}
+
+// listAccounts 列出所有账户（默认账户在前，其余按名称排序）
+func listAccounts() []string {
+	accounts := []string{defaultAccountName}
+	entries, err := os.ReadDir(portfoliosDir)
+	if err != nil {
+		return accounts
+	}
+	for _, entry := range entries {
+		name, isJSON := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !isJSON || name == defaultAccountName || name == allAccountsName {
+			continue
+		}
+		accounts = append(accounts, name)
+	}
+	slices.Sort(accounts[1:])
+	return accounts
+}
+
+// accountExists 判断账户是否存在（合并视图视为存在）
+func accountExists(name string) bool {
+	return name == allAccountsName || slices.Contains(listAccounts(), name)
+}
+
+// validateAccountName 校验新账户名称（用作文件名）
+func validateAccountName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("name is empty")
+	case len([]rune(name)) > maxAccountNameLen:
+		return fmt.Errorf("name longer than %d characters", maxAccountNameLen)
+	case name == defaultAccountName || name == allAccountsName:
+		return fmt.Errorf("%q is reserved", name)
+	case strings.ContainsAny(name, `/\:*?"<>|`) || strings.HasPrefix(name, "."):
+		return fmt.Errorf("name contains invalid characters")
+	case accountExists(name):
+		return fmt.Errorf("account %q already exists", name)
+	}
+	return nil
+}
+
+// loadAccountPortfolio 加载账户持仓，合并视图时加载并合并所有账户
+func loadAccountPortfolio(name string) Portfolio {
+	if name != allAccountsName {
+		return loadPortfolio(name)
+	}
+	var portfolios []Portfolio
+	for _, account := range listAccounts() {
+		portfolios = append(portfolios, loadPortfolio(account))
+	}
+	return mergePortfolios(portfolios)
+}
+
+// mergePortfolios 按股票代码合并多个账户的持仓（保持首次出现的顺序）
+// 合并后的持仓汇总由各账户分别核算后相加，不受成本结转方法在账户间串用的影响
+func mergePortfolios(portfolios []Portfolio) Portfolio {
+	merged := Portfolio{Stocks: []Stock{}}
+	index := make(map[string]int)
+
+	for _, portfolio := range portfolios {
+		for i := range portfolio.Stocks {
+			stock := &portfolio.Stocks[i]
+			summary := stock.PositionSummary()
+
+			pos, exists := index[stock.Code]
+			if !exists {
+				index[stock.Code] = len(merged.Stocks)
+				merged.Stocks = append(merged.Stocks, Stock{
+					Code:         stock.Code,
+					Name:         stock.Name,
+					Transactions: slices.Clone(stock.Transactions),
+					consolidated: &summary,
+				})
+				continue
+			}
+
+			target := &merged.Stocks[pos]
+			target.Transactions = append(target.Transactions, stock.Transactions...)
+			total := target.consolidated
+			total.Quantity += summary.Quantity
+			total.CostBasis += summary.CostBasis
+			total.RealizedProfit += summary.RealizedProfit
+			total.TotalFees += summary.TotalFees
+		}
+	}
+
+	for i := range merged.Stocks {
+		stock := &merged.Stocks[i]
+		sortTransactions(stock.Transactions)
+		if summary := stock.consolidated; summary.Quantity > 0 {
+			summary.AverageCost = summary.CostBasis / float64(summary.Quantity)
+		} else {
+			summary.AverageCost = 0
+		}
+		stock.CostPrice = stock.consolidated.AverageCost
+		stock.Quantity = stock.consolidated.Quantity
+	}
+	return merged
+}
+
+// currentAccount 获取当前账户名称
+func (m *Model) currentAccount() string {
+	if m.config.Portfolio.Account == "" {
+		return defaultAccountName
+	}
+	return m.config.Portfolio.Account
+}
+
+// isConsolidatedView 是否处于所有账户合并视图
+func (m *Model) isConsolidatedView() bool {
+	return m.config.Portfolio.Account == allAccountsName
+}
+
+// accountDisplayName 获取账户的显示名称
+func (m *Model) accountDisplayName(name string) string {
+	switch name {
+	case "", defaultAccountName:
+		return m.getText("account.default")
+	case allAccountsName:
+		return m.getText("account.all")
+	}
+	return name
+}
+
+// rejectInConsolidatedView 合并视图只读，尝试修改持仓时提示切换到具体账户
+func (m *Model) rejectInConsolidatedView() bool {
+	if !m.isConsolidatedView() {
+		return false
+	}
+	m.message = m.getText("account.readOnly")
+	return true
+}
+
+// reloadPortfolio 重新加载当前账户的持仓
+func (m *Model) reloadPortfolio() {
+	m.portfolio = loadAccountPortfolio(m.currentAccount())
+}
+
+// switchAccount 切换当前账户（保存当前持仓，并将选择写入配置）
+func (m *Model) switchAccount(name string) {
+	m.savePortfolio()
+	m.config.Portfolio.Account = name
+	if err := saveConfig(m.config); err != nil {
+		logWarn("log.account.saveConfigFail", err)
+	}
+	m.reloadPortfolio()
+	m.portfolioIsSorted = false
+	m.resetPortfolioCursor()
+	logInfo("log.account.switched", name, len(m.portfolio.Stocks))
+	m.message = fmt.Sprintf(m.getText("account.switched"), m.accountDisplayName(name))
+}
+
+// createAccount 创建空账户
+func createAccount(name string) error {
+	if err := validateAccountName(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(portfoliosDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(accountFile(name), []byte("{\n  \"stocks\": []\n}"), 0644)
+}
+
+// ============================================================================
+// 账户切换界面
+// ============================================================================
+
+// accountOptions 账户切换列表：合并视图 + 所有账户
+func (m *Model) accountOptions() []string {
+	return append([]string{allAccountsName}, listAccounts()...)
+}
+
+// enterAccountSwitching 打开账户切换界面（光标定位到当前账户）
+func (m *Model) enterAccountSwitching() {
+	logInfo("log.action.enterAccounts")
+	m.previousState = m.state
+	m.state = AccountSwitching
+	m.accountInputMode = false
+	m.accountCursor = max(slices.Index(m.accountOptions(), m.currentAccount()), 0)
+	m.refreshAccountCounts()
+	m.input = ""
+	m.inputCursor = 0
+	m.message = ""
+}
+
+// refreshAccountCounts 统计各账户的持仓数量（用于切换列表显示）
+func (m *Model) refreshAccountCounts() {
+	m.accountCounts = make(map[string]int)
+	for _, name := range m.accountOptions() {
+		m.accountCounts[name] = len(loadAccountPortfolio(name).Stocks)
+	}
+}
+
+// leaveAccountSwitching 返回进入账户切换前的界面
+func (m *Model) leaveAccountSwitching() (tea.Model, tea.Cmd) {
+	m.accountInputMode = false
+	if m.previousState == Monitoring {
+		m.state = Monitoring
+		return m, m.tickCmd()
+	}
+	m.state = MainMenu
+	return m, nil
+}
+
+func (m *Model) handleAccountSwitching(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	options := m.accountOptions()
+
+	if m.accountInputMode {
+		switch msg.String() {
+		case "esc":
+			m.accountInputMode = false
+			m.input = ""
+			m.inputCursor = 0
+			m.message = ""
+		case "enter":
+			name := strings.TrimSpace(m.input)
+			if err := createAccount(name); err != nil {
+				m.message = fmt.Sprintf(m.getText("account.createFail"), err)
+				return m, nil
+			}
+			logInfo("log.account.created", name)
+			m.switchAccount(name)
+			return m.leaveAccountSwitching()
+		default:
+			handleTextInput(msg, &m.input, &m.inputCursor)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.message = ""
+		return m.leaveAccountSwitching()
+	case "up", "k", "w":
+		if m.accountCursor > 0 {
+			m.accountCursor--
+		}
+	case "down", "j", "s":
+		if m.accountCursor < len(options)-1 {
+			m.accountCursor++
+		}
+	case "n":
+		m.accountInputMode = true
+		m.input = ""
+		m.inputCursor = 0
+		m.message = ""
+	case "enter", " ":
+		m.switchAccount(options[m.accountCursor])
+		return m.leaveAccountSwitching()
+	}
+	return m, nil
+}
+
+func (m *Model) viewAccountSwitching() string {
+	s := m.getText("account.title") + "\n\n"
+
+	for i, name := range m.accountOptions() {
+		prefix := "  "
+		if i == m.accountCursor {
+			prefix = "► "
+		}
+		current := ""
+		if name == m.currentAccount() {
+			current = " " + m.getText("account.current")
+		}
+		positions := fmt.Sprintf(m.getText("account.positions"), m.accountCounts[name])
+		s += fmt.Sprintf("%s%s (%s)%s\n", prefix, m.accountDisplayName(name), positions, current)
+		if name == allAccountsName {
+			s += "  ──────────\n"
+		}
+	}
+
+	if m.accountInputMode {
+		s += "\n" + m.getText("account.newPrompt") + formatTextWithCursor(m.input, m.inputCursor) + "\n"
+		s += "\n" + m.getText("account.inputHelp") + "\n"
+	} else {
+		s += "\n" + m.getText("account.help") + "\n"
+	}
+
+	if m.message != "" {
+		s += "\n" + m.message + "\n"
+	}
+	return s
+}
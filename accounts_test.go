@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+// TestMergePortfolios 测试按股票代码合并多个账户的持仓
+func TestMergePortfolios(t *testing.T) {
+	personal := Portfolio{Stocks: []Stock{
+		{Code: "SH600000", Name: "浦发银行", Transactions: []Transaction{
+			{Type: TransactionBuy, Date: "2025-01-02", Price: 10, Quantity: 100},
+			{Type: TransactionSell, Date: "2025-03-01", Price: 12, Quantity: 50},
+		}},
+		{Code: "AAPL", Name: "Apple Inc.", Transactions: []Transaction{
+			{Type: TransactionBuy, Date: "2025-02-01", Price: 150, Quantity: 10},
+		}},
+	}}
+	margin := Portfolio{Stocks: []Stock{
+		{Code: "SH600000", Name: "浦发银行", Transactions: []Transaction{
+			{Type: TransactionBuy, Date: "2025-02-10", Price: 11, Quantity: 150},
+		}},
+	}}
+
+	merged := mergePortfolios([]Portfolio{personal, margin})
+	if len(merged.Stocks) != 2 {
+		t.Fatalf("合并后持股数量 = %d, expected 2", len(merged.Stocks))
+	}
+
+	stock := merged.Stocks[0]
+	if stock.Code != "SH600000" || stock.Quantity != 200 {
+		t.Fatalf("合并持仓 = %s %d, expected SH600000 200", stock.Code, stock.Quantity)
+	}
+	// 各账户分别核算: 50*10 + 150*11 = 2150，已实现 50*(12-10) = 100
+	summary := stock.PositionSummary()
+	if !almostEqual(summary.CostBasis, 2150) || !almostEqual(stock.CostPrice, 10.75) {
+		t.Errorf("合并成本 = %.2f (均价 %.4f), expected 2150 (10.75)", summary.CostBasis, stock.CostPrice)
+	}
+	if !almostEqual(stock.CalculateRealizedProfit(), 100) {
+		t.Errorf("合并已实现盈亏 = %.2f, expected 100", stock.CalculateRealizedProfit())
+	}
+	if len(stock.Transactions) != 3 || stock.Transactions[1].Date != "2025-02-10" {
+		t.Errorf("合并交易记录应按日期排序: %+v", stock.Transactions)
+	}
+
+	// 原账户持仓不受影响
+	if len(personal.Stocks[0].Transactions) != 2 {
+		t.Error("合并不应修改原账户的交易记录")
+	}
+}
+
+func TestValidateAccountName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"margin", false},
+		{"家庭账户", false},
+		{"", true},
+		{defaultAccountName, true},
+		{allAccountsName, true},
+		{"../escape", true},
+		{".hidden", true},
+	}
+
+	for _, tt := range tests {
+		if err := validateAccountName(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("validateAccountName(%q) err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// 价格提醒
+// ============================================================================
+
+// AlertType 提醒规则类型
+type AlertType string
+
+const (
+	AlertPriceAbove    AlertType = "price_above"    // 现价 >= 阈值
+	AlertPriceBelow    AlertType = "price_below"    // 现价 <= 阈值
+	AlertChangePercent AlertType = "change_percent" // |涨跌幅| >= 阈值（%）
+	AlertVolumeSpike   AlertType = "volume_spike"   // 本次成交量增量 >= 阈值 * 近期平均增量
+	AlertCrossCost     AlertType = "cross_cost"     // 现价上穿或下穿持仓成本价（无阈值）
+)
+
+// alertTypes 新建规则时可选的类型（按显示顺序）
+var alertTypes = []AlertType{AlertPriceAbove, AlertPriceBelow, AlertChangePercent, AlertVolumeSpike, AlertCrossCost}
+
+// 成交量放大检测参数
+const (
+	volumeSpikeWindow     = 20 // 计算平均增量的最近更新次数
+	volumeSpikeMinSamples = 5  // 至少积累的增量样本数
+	alertBannerDuration   = 30 * time.Second
+	maxAlertBannerLines   = 3
+)
+
+// AlertRule 单条提醒规则
+type AlertRule struct {
+	ID              string    `json:"id"`
+	Code            string    `json:"code"`
+	Name            string    `json:"name"`
+	Type            AlertType `json:"type"`
+	Threshold       float64   `json:"threshold,omitempty"`
+	CooldownMinutes int       `json:"cooldown_minutes,omitempty"` // 0 表示使用配置的默认冷却时间
+	Enabled         bool      `json:"enabled"`
+	LastTriggered   time.Time `json:"last_triggered,omitempty"`
+}
+
+// AlertList 提醒规则列表（data/alerts.json）
+type AlertList struct {
+	Rules []AlertRule `json:"rules"`
+}
+
+// AlertEvent 规则触发事件（同时作为 webhook 的 JSON 内容）
+type AlertEvent struct {
+	RuleID        string    `json:"rule_id"`
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	Type          AlertType `json:"type"`
+	Threshold     float64   `json:"threshold"`
+	Price         float64   `json:"price"`
+	ChangePercent float64   `json:"change_percent"`
+	Volume        int64     `json:"volume"`
+	Message       string    `json:"message"`
+	Time          time.Time `json:"time"`
+}
+
+// volumeTracker 记录股票成交量的增量（用于检测成交量放大）
+type volumeTracker struct {
+	lastVolume int64
+	increments []int64
+}
+
+// observe 记录最新累计成交量，返回本次增量和此前的平均增量
+func (v *volumeTracker) observe(volume int64) (int64, float64, bool) {
+	if v.lastVolume == 0 || volume < v.lastVolume {
+		// 首次记录或跨日成交量重置
+		v.lastVolume = volume
+		v.increments = nil
+		return 0, 0, false
+	}
+	increment := volume - v.lastVolume
+	v.lastVolume = volume
+	if increment == 0 {
+		// 数据未变化（收盘后或接口缓存），不计入样本
+		return 0, 0, false
+	}
+
+	var average float64
+	ready := len(v.increments) >= volumeSpikeMinSamples
+	if ready {
+		var sum int64
+		for _, inc := range v.increments {
+			sum += inc
+		}
+		average = float64(sum) / float64(len(v.increments))
+	}
+	v.increments = append(v.increments, increment)
+	if len(v.increments) > volumeSpikeWindow {
+		v.increments = v.increments[1:]
+	}
+	return increment, average, ready
+}
+
+// loadAlerts 加载提醒规则
+func loadAlerts() AlertList {
+	data, err := os.ReadFile(alertsFile)
+	if err != nil {
+		return AlertList{Rules: []AlertRule{}}
+	}
+	var alerts AlertList
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		logWarn("log.alert.loadFail", err)
+		return AlertList{Rules: []AlertRule{}}
+	}
+	return alerts
+}
+
+// saveAlerts 保存提醒规则（包括触发时间，重启后冷却时间仍然有效）
+func (m *Model) saveAlerts() {
+	data, err := json.MarshalIndent(m.alerts, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(alertsFile, data, 0644); err != nil {
+		logWarn("log.alert.saveFail", err)
+	}
+}
+
+// cooldown 获取规则的冷却时间
+func (r AlertRule) cooldown(defaultMinutes int) time.Duration {
+	minutes := r.CooldownMinutes
+	if minutes <= 0 {
+		minutes = defaultMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// newAlertRuleID 生成规则 ID（代码-类型-时间戳）
+func newAlertRuleID(code string, alertType AlertType, now time.Time) string {
+	return fmt.Sprintf("%s-%s-%d", code, alertType, now.UnixNano())
+}
+
+// alertConditionMet 判断规则条件是否满足
+// prevPrice 为上一次更新的价格（0 表示没有），costPrice 为持仓成本价（0 表示未持有）
+func alertConditionMet(rule AlertRule, data *StockData, prevPrice, costPrice float64, volumeIncrement int64, volumeAverage float64, volumeReady bool) bool {
+	if data == nil || data.Price <= 0 {
+		return false
+	}
+	switch rule.Type {
+	case AlertPriceAbove:
+		return data.Price >= rule.Threshold
+	case AlertPriceBelow:
+		return data.Price <= rule.Threshold
+	case AlertChangePercent:
+		return rule.Threshold > 0 && math.Abs(data.ChangePercent) >= rule.Threshold
+	case AlertVolumeSpike:
+		return volumeReady && rule.Threshold > 0 && volumeAverage > 0 && float64(volumeIncrement) >= rule.Threshold*volumeAverage
+	case AlertCrossCost:
+		if prevPrice <= 0 || costPrice <= 0 {
+			return false
+		}
+		return (prevPrice < costPrice && data.Price >= costPrice) || (prevPrice > costPrice && data.Price <= costPrice)
+	}
+	return false
+}
+
+// portfolioCostPrice 获取当前账户中股票的持仓成本价（未持有返回 0）
+func (m *Model) portfolioCostPrice(code string) float64 {
+	for i := range m.portfolio.Stocks {
+		stock := &m.portfolio.Stocks[i]
+		if stock.Code == code {
+			if summary := stock.PositionSummary(); summary.Quantity > 0 {
+				return summary.AverageCost
+			}
+		}
+	}
+	return 0
+}
+
+// evaluateAlerts 股价更新后检查该股票的提醒规则，返回触发的事件
+func (m *Model) evaluateAlerts(symbol string, data *StockData, now time.Time) []AlertEvent {
+	if m.alertPrevPrices == nil {
+		m.alertPrevPrices = make(map[string]float64)
+	}
+	if m.alertVolumes == nil {
+		m.alertVolumes = make(map[string]*volumeTracker)
+	}
+
+	prevPrice := m.alertPrevPrices[symbol]
+	m.alertPrevPrices[symbol] = data.Price
+	tracker, exists := m.alertVolumes[symbol]
+	if !exists {
+		tracker = &volumeTracker{}
+		m.alertVolumes[symbol] = tracker
+	}
+	volumeIncrement, volumeAverage, volumeReady := tracker.observe(data.Volume)
+
+	var events []AlertEvent
+	costPrice := -1.0 // 按需计算
+	for i := range m.alerts.Rules {
+		rule := &m.alerts.Rules[i]
+		if !rule.Enabled || rule.Code != symbol {
+			continue
+		}
+		if !rule.LastTriggered.IsZero() && now.Sub(rule.LastTriggered) < rule.cooldown(m.config.Alerts.CooldownMinutes) {
+			continue
+		}
+		if rule.Type == AlertCrossCost && costPrice < 0 {
+			costPrice = m.portfolioCostPrice(symbol)
+		}
+		if !alertConditionMet(*rule, data, prevPrice, costPrice, volumeIncrement, volumeAverage, volumeReady) {
+			continue
+		}
+
+		rule.LastTriggered = now
+		name := rule.Name
+		if name == "" {
+			name = data.Name
+		}
+		event := AlertEvent{
+			RuleID:        rule.ID,
+			Code:          rule.Code,
+			Name:          name,
+			Type:          rule.Type,
+			Threshold:     rule.Threshold,
+			Price:         data.Price,
+			ChangePercent: data.ChangePercent,
+			Volume:        data.Volume,
+			Time:          now,
+		}
+		event.Message = fmt.Sprintf(m.getText("alert.triggered"), name, rule.Code, m.describeAlertRule(*rule), data.Price, data.ChangePercent)
+		events = append(events, event)
+		logInfo("log.alert.triggered", rule.Code, rule.Type, rule.Threshold, data.Price)
+	}
+	if len(events) > 0 {
+		m.saveAlerts()
+	}
+	return events
+}
+
+// handleAlertEvents 显示提醒横幅，并返回响铃和 webhook 通知命令
+func (m *Model) handleAlertEvents(events []AlertEvent, now time.Time) tea.Cmd {
+	if len(events) == 0 {
+		return nil
+	}
+	if now.Sub(m.alertBannerTime) > alertBannerDuration {
+		m.alertBanner = nil
+	}
+	for _, event := range events {
+		m.alertBanner = append(m.alertBanner, event.Message)
+	}
+	if len(m.alertBanner) > maxAlertBannerLines {
+		m.alertBanner = m.alertBanner[len(m.alertBanner)-maxAlertBannerLines:]
+	}
+	m.alertBannerTime = now
+
+	var cmds []tea.Cmd
+	if m.config.Alerts.Bell {
+		cmds = append(cmds, ringTerminalBell)
+	}
+	if url := strings.TrimSpace(m.config.Alerts.WebhookURL); url != "" {
+		for _, event := range events {
+			cmds = append(cmds, postAlertWebhookCmd(url, event))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// ringTerminalBell 终端响铃
+func ringTerminalBell() tea.Msg {
+	fmt.Fprint(os.Stdout, "\a")
+	return nil
+}
+
+// postAlertWebhookCmd 将触发事件以 JSON 形式 POST 到配置的本地 webhook
+func postAlertWebhookCmd(url string, event AlertEvent) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := postAlertWebhook(ctx, url, event); err != nil {
+			logWarn("log.alert.webhookFail", url, err)
+		}
+		return nil
+	}
+}
+
+// postAlertWebhook 发送 webhook 请求
+func postAlertWebhook(ctx context.Context, url string, event AlertEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// viewAlertBanner 渲染提醒横幅（超过显示时长后自动隐藏）
+func (m *Model) viewAlertBanner() string {
+	if len(m.alertBanner) == 0 || time.Since(m.alertBannerTime) > alertBannerDuration {
+		return ""
+	}
+	style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("11"))
+	var b strings.Builder
+	for _, line := range m.alertBanner {
+		b.WriteString(style.Render(" 🔔 "+line+" ") + "\n")
+	}
+	return b.String() + "\n"
+}
+
+// describeAlertRule 规则的显示文本
+func (m *Model) describeAlertRule(rule AlertRule) string {
+	switch rule.Type {
+	case AlertPriceAbove, AlertPriceBelow, AlertChangePercent, AlertVolumeSpike:
+		return fmt.Sprintf(m.getText("alert.type."+string(rule.Type)), rule.Threshold)
+	case AlertCrossCost:
+		return m.getText("alert.type.cross_cost")
+	}
+	return string(rule.Type)
+}
+
+// ============================================================================
+// 提醒规则管理界面
+// ============================================================================
+
+// enterAlertManaging 打开某只股票的提醒规则列表
+func (m *Model) enterAlertManaging(code, name string) {
+	logInfo("log.action.enterAlerts", code)
+	m.previousState = m.state
+	m.state = AlertManaging
+	m.alertStockCode = code
+	m.alertStockName = name
+	m.alertCursor = 0
+	m.alertInputMode = false
+	m.alertTypeIndex = 0
+	m.input = ""
+	m.inputCursor = 0
+	m.message = ""
+}
+
+// stockAlertIndexes 当前股票的规则在规则列表中的下标
+func (m *Model) stockAlertIndexes() []int {
+	var indexes []int
+	for i, rule := range m.alerts.Rules {
+		if rule.Code == m.alertStockCode {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func (m *Model) handleAlertManaging(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.alertInputMode {
+		return m.handleAlertInput(msg)
+	}
+
+	indexes := m.stockAlertIndexes()
+	switch msg.String() {
+	case "esc", "q":
+		m.state = m.previousState
+		m.message = ""
+		if m.state == Monitoring || m.state == WatchlistViewing {
+			m.lastUpdate = time.Now()
+			return m, m.tickCmd()
+		}
+	case "up", "k", "w":
+		if m.alertCursor > 0 {
+			m.alertCursor--
+		}
+	case "down", "j", "s":
+		if m.alertCursor < len(indexes)-1 {
+			m.alertCursor++
+		}
+	case "a", "n":
+		m.alertInputMode = true
+		m.alertTypeIndex = 0
+		m.input = ""
+		m.inputCursor = 0
+		m.message = ""
+	case "d":
+		if len(indexes) == 0 {
+			return m, nil
+		}
+		removed := m.alerts.Rules[indexes[m.alertCursor]]
+		m.alerts.Rules = slices.Delete(m.alerts.Rules, indexes[m.alertCursor], indexes[m.alertCursor]+1)
+		m.saveAlerts()
+		if m.alertCursor >= len(indexes)-1 && m.alertCursor > 0 {
+			m.alertCursor--
+		}
+		logInfo("log.alert.removed", removed.Code, removed.Type)
+		m.message = fmt.Sprintf(m.getText("alert.removed"), m.describeAlertRule(removed))
+	case " ", "enter":
+		// 启用/停用规则
+		if len(indexes) == 0 {
+			return m, nil
+		}
+		rule := &m.alerts.Rules[indexes[m.alertCursor]]
+		rule.Enabled = !rule.Enabled
+		m.saveAlerts()
+	}
+	return m, nil
+}
+
+// handleAlertInput 新建规则：←/→ 选择类型，输入阈值后回车保存
+func (m *Model) handleAlertInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.alertInputMode = false
+		m.input = ""
+		m.inputCursor = 0
+		m.message = ""
+	case "tab", "up", "down":
+		if msg.String() == "up" {
+			m.alertTypeIndex = (m.alertTypeIndex + len(alertTypes) - 1) % len(alertTypes)
+		} else {
+			m.alertTypeIndex = (m.alertTypeIndex + 1) % len(alertTypes)
+		}
+	case "enter":
+		alertType := alertTypes[m.alertTypeIndex]
+		var threshold float64
+		if alertType != AlertCrossCost {
+			value, err := strconv.ParseFloat(strings.TrimSpace(m.input), 64)
+			if err != nil || value <= 0 {
+				m.message = m.getText("alert.invalidThreshold")
+				return m, nil
+			}
+			threshold = value
+		}
+		now := time.Now()
+		rule := AlertRule{
+			ID:        newAlertRuleID(m.alertStockCode, alertType, now),
+			Code:      m.alertStockCode,
+			Name:      m.alertStockName,
+			Type:      alertType,
+			Threshold: threshold,
+			Enabled:   true,
+		}
+		m.alerts.Rules = append(m.alerts.Rules, rule)
+		m.saveAlerts()
+		logInfo("log.alert.added", rule.Code, rule.Type, rule.Threshold)
+		m.alertInputMode = false
+		m.alertCursor = len(m.stockAlertIndexes()) - 1
+		m.input = ""
+		m.inputCursor = 0
+		m.message = fmt.Sprintf(m.getText("alert.added"), m.describeAlertRule(rule))
+	default:
+		handleTextInput(msg, &m.input, &m.inputCursor)
+	}
+	return m, nil
+}
+
+func (m *Model) viewAlertManaging() string {
+	s := fmt.Sprintf(m.getText("alert.title"), m.alertStockName, m.alertStockCode) + "\n\n"
+
+	m.stockPriceMutex.RLock()
+	if entry, exists := m.stockPriceCache[m.alertStockCode]; exists && entry.Data != nil {
+		s += fmt.Sprintf(m.getText("alert.currentPrice"), entry.Data.Price, entry.Data.ChangePercent) + "\n"
+	}
+	m.stockPriceMutex.RUnlock()
+	if cost := m.portfolioCostPrice(m.alertStockCode); cost > 0 {
+		s += fmt.Sprintf(m.getText("alert.costPrice"), cost) + "\n"
+	}
+	s += "\n"
+
+	indexes := m.stockAlertIndexes()
+	if len(indexes) == 0 {
+		s += m.getText("alert.empty") + "\n"
+	}
+	for i, index := range indexes {
+		rule := m.alerts.Rules[index]
+		prefix := "  "
+		if i == m.alertCursor && !m.alertInputMode {
+			prefix = "► "
+		}
+		status := "[✓]"
+		if !rule.Enabled {
+			status = "[ ]"
+		}
+		lastTriggered := m.getText("alert.never")
+		if !rule.LastTriggered.IsZero() {
+			lastTriggered = rule.LastTriggered.Format("01-02 15:04")
+		}
+		cooldown := int(rule.cooldown(m.config.Alerts.CooldownMinutes).Minutes())
+		s += fmt.Sprintf("%s%s %s  "+m.getText("alert.ruleInfo")+"\n", prefix, status, m.describeAlertRule(rule), cooldown, lastTriggered)
+	}
+
+	if m.alertInputMode {
+		alertType := alertTypes[m.alertTypeIndex]
+		s += "\n" + fmt.Sprintf(m.getText("alert.typePrompt"), m.getText("alert.typeName."+string(alertType))) + "\n"
+		if alertType != AlertCrossCost {
+			s += m.getText("alert.thresholdPrompt."+string(alertType)) + formatTextWithCursor(m.input, m.inputCursor) + "\n"
+		}
+		s += "\n" + m.getText("alert.inputHelp") + "\n"
+	} else {
+		s += "\n" + m.getText("alert.help") + "\n"
+	}
+
+	if m.message != "" {
+		s += "\n" + m.message + "\n"
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAlertConditionMet(t *testing.T) {
+	data := &StockData{Price: 10.5, ChangePercent: -3.2}
+	tests := []struct {
+		name      string
+		rule      AlertRule
+		prevPrice float64
+		costPrice float64
+		want      bool
+	}{
+		{"价格高于", AlertRule{Type: AlertPriceAbove, Threshold: 10}, 0, 0, true},
+		{"价格未达到", AlertRule{Type: AlertPriceAbove, Threshold: 11}, 0, 0, false},
+		{"价格低于", AlertRule{Type: AlertPriceBelow, Threshold: 10.5}, 0, 0, true},
+		{"跌幅超过阈值", AlertRule{Type: AlertChangePercent, Threshold: 3}, 0, 0, true},
+		{"涨跌幅未超过", AlertRule{Type: AlertChangePercent, Threshold: 5}, 0, 0, false},
+		{"上穿成本价", AlertRule{Type: AlertCrossCost}, 10.2, 10.4, true},
+		{"下穿成本价", AlertRule{Type: AlertCrossCost}, 10.8, 10.6, true},
+		{"未穿越成本价", AlertRule{Type: AlertCrossCost}, 10.8, 10, false},
+		{"没有上次价格", AlertRule{Type: AlertCrossCost}, 0, 10.4, false},
+	}
+
+	for _, tt := range tests {
+		if got := alertConditionMet(tt.rule, data, tt.prevPrice, tt.costPrice, 0, 0, false); got != tt.want {
+			t.Errorf("%s: alertConditionMet = %v, expected %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestVolumeTrackerSpike 测试按成交量增量检测放大
+func TestVolumeTrackerSpike(t *testing.T) {
+	tracker := &volumeTracker{}
+	rule := AlertRule{Type: AlertVolumeSpike, Threshold: 3}
+	data := &StockData{Price: 10}
+
+	volume := int64(10000)
+	tracker.observe(volume)
+	for i := 0; i < volumeSpikeMinSamples; i++ {
+		volume += 100
+		increment, average, ready := tracker.observe(volume)
+		if alertConditionMet(rule, data, 0, 0, increment, average, ready) {
+			t.Fatalf("样本不足或增量正常时不应触发（第 %d 次）", i+1)
+		}
+	}
+
+	volume += 500
+	increment, average, ready := tracker.observe(volume)
+	if !ready || !almostEqual(average, 100) || increment != 500 {
+		t.Fatalf("observe = %d, %.2f, %v, expected 500, 100, true", increment, average, ready)
+	}
+	if !alertConditionMet(rule, data, 0, 0, increment, average, ready) {
+		t.Error("成交量增量为平均值的 5 倍，应触发")
+	}
+
+	// 成交量回落（跨日重置）时重新积累样本
+	if _, _, ready := tracker.observe(100); ready {
+		t.Error("成交量重置后不应就绪")
+	}
+}
+
+// TestEvaluateAlertsCooldown 测试规则触发后在冷却时间内不重复提醒
+func TestEvaluateAlertsCooldown(t *testing.T) {
+	useTempDataDir(t)
+
+	m := &Model{config: getDefaultConfig(), language: English}
+	m.alerts.Rules = []AlertRule{
+		{ID: "r1", Code: "SH600000", Type: AlertPriceAbove, Threshold: 10, Enabled: true},
+		{ID: "r2", Code: "SH600000", Type: AlertPriceBelow, Threshold: 5, Enabled: true},
+		{ID: "r3", Code: "SH600000", Type: AlertPriceAbove, Threshold: 1, Enabled: false},
+		{ID: "r4", Code: "AAPL", Type: AlertPriceAbove, Threshold: 1, Enabled: true},
+	}
+	data := &StockData{Name: "浦发银行", Price: 10.5}
+	now := time.Now()
+
+	events := m.evaluateAlerts("SH600000", data, now)
+	if len(events) != 1 || events[0].RuleID != "r1" || events[0].Name != "浦发银行" {
+		t.Fatalf("events = %+v, expected r1 only", events)
+	}
+	if events := m.evaluateAlerts("SH600000", data, now.Add(5*time.Second)); len(events) != 0 {
+		t.Errorf("冷却时间内不应重复触发: %+v", events)
+	}
+	cooldown := time.Duration(m.config.Alerts.CooldownMinutes) * time.Minute
+	if events := m.evaluateAlerts("SH600000", data, now.Add(cooldown)); len(events) != 1 {
+		t.Errorf("冷却时间结束后应再次触发, got %d", len(events))
+	}
+
+	// 触发时间已保存，重新加载后冷却时间仍然有效
+	if loaded := loadAlerts(); len(loaded.Rules) != 4 || loaded.Rules[0].LastTriggered.IsZero() {
+		t.Errorf("触发时间未保存: %+v", loaded.Rules)
+	}
+}
+
+func TestPostAlertWebhook(t *testing.T) {
+	received := make(chan AlertEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event AlertEvent
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&event) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- event
+	}))
+	defer server.Close()
+
+	event := AlertEvent{RuleID: "r1", Code: "AAPL", Type: AlertPriceAbove, Threshold: 200, Price: 201.5}
+	if err := postAlertWebhook(context.Background(), server.URL, event); err != nil {
+		t.Fatalf("postAlertWebhook: %v", err)
+	}
+	if got := <-received; got.RuleID != "r1" || !almostEqual(got.Price, 201.5) {
+		t.Errorf("webhook 收到 %+v", got)
+	}
+
+	if err := postAlertWebhook(context.Background(), server.URL+"/missing", AlertEvent{}); err == nil {
+		t.Error("非 2xx 响应应返回错误")
+	}
+}
+
+// useTempDataDir 切换到临时工作目录（data/ 下的文件写入临时目录）
+func useTempDataDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.MkdirAll("data", 0755); err != nil {
+		t.Fatal(err)
+	}
+}
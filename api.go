@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -62,6 +65,11 @@ func isHKStock(symbol string) bool {
 func getMarketType(symbol string) MarketType {
 	symbol = strings.ToUpper(strings.TrimSpace(symbol))
 
+	// 汇率代码识别（FX:USDCNY）
+	if strings.HasPrefix(symbol, fxSymbolPrefix) {
+		return MarketFX
+	}
+
 	// A股识别 (上海、深圳)
 	if strings.HasPrefix(symbol, "SH") || strings.HasPrefix(symbol, "SZ") ||
 		(len(symbol) == 6 && (strings.HasPrefix(symbol, "0") ||
@@ -88,6 +96,12 @@ func searchStockBySymbol(symbol string) *StockData {
 	symbol = strings.ToUpper(strings.TrimSpace(symbol))
 	logDebug("log.api.symbolSearch", symbol)
 
+	// 策略0: 本地代码表
+	if result := lookupSymbolDirectory(symbol); result != nil {
+		logInfo("log.symbols.searchSuccess", result.Name, result.Symbol)
+		return result
+	}
+
 	// 策略1: 使用TwelveData搜索API
 	result := searchStockByTwelveDataAPI(symbol)
 	if result != nil && result.Price > 0 {
@@ -118,6 +132,12 @@ func searchChineseStock(chineseName string) *StockData {
 	chineseName = strings.TrimSpace(chineseName)
 	logDebug("log.api.chineseSearch", chineseName)
 
+	// 策略0: 本地代码表
+	if result := lookupSymbolDirectory(chineseName); result != nil {
+		logInfo("log.symbols.searchSuccess", result.Name, result.Symbol)
+		return result
+	}
+
 	// 策略1: 使用腾讯搜索API
 	result := searchStockByTencentAPI(chineseName)
 	if result != nil && result.Price > 0 {
@@ -148,12 +168,17 @@ func searchChineseStock(chineseName string) *StockData {
 // TwelveData API
 // ============================================================================
 
-// searchStockByTwelveDataAPI 使用TwelveData搜索API查找股票
+// searchStockByTwelveDataAPI 使用TwelveData搜索API查找股票（取最匹配且能获取报价的一只）
 func searchStockByTwelveDataAPI(keyword string) *StockData {
+	return quoteBestCandidate(keyword, fetchTwelveDataSearchCandidates(keyword))
+}
+
+// fetchTwelveDataSearchCandidates 使用TwelveData搜索API查找候选股票（只保留美股）
+func fetchTwelveDataSearchCandidates(keyword string) []SearchCandidate {
 	logDebug("log.api.twelveDataSearchStart", keyword)
 
 	// 先尝试符号搜索
-	searchUrl := fmt.Sprintf("https://api.twelvedata.com/symbol_search?symbol=%s&apikey=demo", keyword)
+	searchUrl := fmt.Sprintf("%s/symbol_search?symbol=%s&apikey=demo", apiEndpoints.TwelveData, keyword)
 	logDebug("log.api.twelveDataSearchUrl", searchUrl)
 
 	client := &http.Client{Timeout: 8 * time.Second}
@@ -186,54 +211,52 @@ func searchStockByTwelveDataAPI(keyword string) *StockData {
 		return nil
 	}
 
-	if len(searchResult.Data) == 0 {
-		logDebug("log.api.twelveDataSearchNoMatch")
-		return nil
-	}
-
-	// 选择第一个匹配的结果，优先选择美国市场的股票
-	var selectedSymbol, selectedName string
+	// 其他国家的交易所无法获取报价，只保留美国市场
+	var candidates []SearchCandidate
 	for _, item := range searchResult.Data {
-		if item.Country == "United States" && item.Exchange == "NASDAQ" {
-			selectedSymbol = item.Symbol
-			selectedName = item.InstrumentName
-			break
+		if item.Country != "United States" || item.Symbol == "" {
+			continue
 		}
+		candidates = append(candidates, SearchCandidate{
+			Symbol:   strings.ToUpper(item.Symbol),
+			Name:     item.InstrumentName,
+			Market:   MarketUS,
+			Exchange: item.Exchange,
+		})
 	}
 
-	// 如果没有找到美国NASDAQ的，就用第一个结果
-	if selectedSymbol == "" {
-		selectedSymbol = searchResult.Data[0].Symbol
-		selectedName = searchResult.Data[0].InstrumentName
+	if len(candidates) == 0 {
+		logDebug("log.api.twelveDataSearchNoMatch")
 	}
-
-	logDebug("log.api.twelveDataSearchSelect", selectedName, selectedSymbol)
-
-	// 获取股票报价
-	return tryTwelveDataAPI(selectedSymbol)
+	return candidates
 }
 
 // tryTwelveDataAPI 使用TwelveData API获取股票报价
-func tryTwelveDataAPI(symbol string) *StockData {
-	convertedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
+func tryTwelveDataAPI(ctx context.Context, symbol string) (*StockData, error) {
+	convertedSymbol := twelveDataQuoteSymbol(symbol)
 	logDebug("log.api.twelveDataConvert", symbol, convertedSymbol)
 
 	// 使用TwelveData API获取股票报价
-	url := fmt.Sprintf("https://api.twelvedata.com/quote?symbol=%s&apikey=demo", convertedSymbol)
+	url := fmt.Sprintf("%s/quote?symbol=%s&apikey=demo", apiEndpoints.TwelveData, convertedSymbol)
 	logDebug("log.api.twelveDataUrl", url)
 
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("twelvedata: %w", err)
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		logError("log.api.twelveDataHttpFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logError("log.api.twelveDataReadFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: %w", err)
 	}
 
 	logDebug("log.api.twelveDataResponse", string(body))
@@ -241,13 +264,13 @@ func tryTwelveDataAPI(symbol string) *StockData {
 	var result map[string]any
 	if err := json.Unmarshal(body, &result); err != nil {
 		logError("log.api.twelveDataJsonFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: %w", err)
 	}
 
 	// 检查是否有错误信息
 	if errMsg, hasErr := result["message"]; hasErr {
 		logError("log.api.twelveDataApiError", errMsg)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: api error: %v", errMsg)
 	}
 
 	// 解析股票数据
@@ -261,24 +284,24 @@ func tryTwelveDataAPI(symbol string) *StockData {
 
 	if !closeOk || !prevOk {
 		logDebug("log.api.twelveDataInvalidData")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: missing close/previous_close for %s", symbol)
 	}
 
 	current, err := strconv.ParseFloat(closeStr, 64)
 	if err != nil {
 		logError("log.api.twelveDataPriceFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: %w", err)
 	}
 
 	previous, err := strconv.ParseFloat(prevCloseStr, 64)
 	if err != nil {
 		logError("log.api.twelveDataPrevCloseFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: %w", err)
 	}
 
 	if current <= 0 {
 		logDebug("log.api.twelveDataInvalidPrice")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("twelvedata: invalid price %.3f for %s", current, symbol)
 	}
 
 	// 解析开盘价、最高价、最低价、成交量
@@ -319,19 +342,24 @@ func tryTwelveDataAPI(symbol string) *StockData {
 		PrevClose:     previous,
 		TurnoverRate:  0, // TwelveData不提供换手率
 		Volume:        volume,
-	}
+	}, nil
 }
 
 // ============================================================================
 // 腾讯 API
 // ============================================================================
 
-// searchStockByTencentAPI 使用腾讯搜索API查找股票
+// searchStockByTencentAPI 使用腾讯搜索API查找股票（取最匹配且能获取报价的一只）
 func searchStockByTencentAPI(keyword string) *StockData {
+	return quoteBestCandidate(keyword, fetchTencentSearchCandidates(keyword))
+}
+
+// fetchTencentSearchCandidates 使用腾讯搜索API查找候选股票
+func fetchTencentSearchCandidates(keyword string) []SearchCandidate {
 	logDebug("log.api.tencentSearchStart", keyword)
 
 	// 腾讯股票搜索API URL - 使用t=all支持A股、港股、美股搜索
-	url := fmt.Sprintf("https://smartbox.gtimg.cn/s3/?q=%s&t=all", keyword)
+	url := fmt.Sprintf("%s/s3/?q=%s&t=all", apiEndpoints.TencentSearch, keyword)
 	logDebug("log.api.tencentSearchUrl", url)
 
 	client := &http.Client{Timeout: 10 * time.Second}
@@ -373,22 +401,90 @@ func searchStockByTencentAPI(keyword string) *StockData {
 	logDebug("log.api.tencentSearchResponse", content[:min(300, len(content))])
 
 	// 解析搜索结果
-	return parseSearchResults(content, keyword)
+	return parseSearchResults(content)
 }
 
 // tryTencentAPI 使用腾讯API获取股票价格
-func tryTencentAPI(symbol string) *StockData {
+func tryTencentAPI(ctx context.Context, symbol string) (*StockData, error) {
 	tencentSymbol := convertStockSymbolForTencent(symbol)
 	logDebug("log.api.tencentConvert", symbol, tencentSymbol)
 
-	url := fmt.Sprintf("https://qt.gtimg.cn/q=%s", tencentSymbol)
+	content, err := fetchTencentQuoteContent(ctx, tencentSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(content, "~") {
+		logError("log.api.tencentFormatError")
+		return nil, fmt.Errorf("tencent: unexpected response format for %s", symbol)
+	}
+
+	return parseTencentQuoteFields(symbol, strings.Split(content, "~"))
+}
+
+// tryTencentBatchAPI 使用腾讯API一次获取多只股票价格
+// 腾讯接口支持逗号分隔的多个代码: q=sh600000,hk00700
+func tryTencentBatchAPI(ctx context.Context, symbols []string) (map[string]*StockData, error) {
+	results := make(map[string]*StockData)
+	var errs []error
+
+	for start := 0; start < len(symbols); start += tencentBatchSize {
+		chunk := symbols[start:min(start+tencentBatchSize, len(symbols))]
+
+		// 腾讯代码 -> 原始代码，用于把响应行映射回请求的股票
+		symbolMap := make(map[string]string, len(chunk))
+		tencentSymbols := make([]string, 0, len(chunk))
+		for _, symbol := range chunk {
+			tencentSymbol := convertStockSymbolForTencent(symbol)
+			symbolMap[tencentSymbol] = symbol
+			tencentSymbols = append(tencentSymbols, tencentSymbol)
+		}
+		logDebug("log.api.tencentBatchStart", len(chunk))
+
+		content, err := fetchTencentQuoteContent(ctx, strings.Join(tencentSymbols, ","))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// 响应格式: v_sh600000="1~浦发银行~600000~...";\nv_hk00700="100~腾讯控股~...";
+		for _, line := range strings.Split(content, ";") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "v_") {
+				continue
+			}
+			eqPos := strings.Index(line, "=")
+			if eqPos == -1 {
+				continue
+			}
+			symbol, exists := symbolMap[line[len("v_"):eqPos]]
+			if !exists {
+				continue
+			}
+
+			data, err := parseTencentQuoteFields(symbol, strings.Split(strings.Trim(line[eqPos+1:], "\""), "~"))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			results[symbol] = data
+		}
+	}
+
+	logDebug("log.api.tencentBatchDone", len(results), len(symbols))
+	return results, errors.Join(errs...)
+}
+
+// fetchTencentQuoteContent 请求腾讯行情接口并返回UTF-8编码的响应内容
+func fetchTencentQuoteContent(ctx context.Context, query string) (string, error) {
+	url := fmt.Sprintf("%s/q=%s", apiEndpoints.TencentQuote, query)
 	logDebug("log.api.tencentUrl", url)
 
 	client := &http.Client{Timeout: 8 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		logError("log.api.tencentReqFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return "", fmt.Errorf("tencent: %w", err)
 	}
 
 	// 添加必要的请求头，与搜索API保持一致
@@ -400,14 +496,14 @@ func tryTencentAPI(symbol string) *StockData {
 	resp, err := client.Do(req)
 	if err != nil {
 		logError("log.api.tencentHttpFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return "", fmt.Errorf("tencent: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logError("log.api.tencentReadFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return "", fmt.Errorf("tencent: %w", err)
 	}
 
 	content, err := gbkToUtf8(body)
@@ -417,15 +513,14 @@ func tryTencentAPI(symbol string) *StockData {
 	}
 	logDebug("log.api.tencentResponse", content[:min(100, len(content))])
 
-	if !strings.Contains(content, "~") {
-		logError("log.api.tencentFormatError")
-		return &StockData{Symbol: symbol, Price: 0}
-	}
+	return content, nil
+}
 
-	fields := strings.Split(content, "~")
+// parseTencentQuoteFields 解析腾讯行情的 ~ 分隔字段
+func parseTencentQuoteFields(symbol string, fields []string) (*StockData, error) {
 	if len(fields) < 5 {
 		logError("log.api.tencentFieldsError")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("tencent: not enough fields (%d) for %s", len(fields), symbol)
 	}
 
 	stockName := fields[1]
@@ -433,13 +528,13 @@ func tryTencentAPI(symbol string) *StockData {
 	price, err := strconv.ParseFloat(fields[3], 64)
 	if err != nil || price <= 0 {
 		logError("log.api.tencentPriceError", fields[3])
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("tencent: invalid price %q for %s", fields[3], symbol)
 	}
 
 	previousClose, err := strconv.ParseFloat(fields[4], 64)
 	if err != nil || previousClose <= 0 {
 		logError("log.api.tencentPrevCloseError", fields[4])
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("tencent: invalid previous close %q for %s", fields[4], symbol)
 	}
 
 	// 解析开盘价、最高价、最低价、换手率、成交量
@@ -480,7 +575,7 @@ func tryTencentAPI(symbol string) *StockData {
 		PrevClose:     previousClose,
 		TurnoverRate:  turnoverRate,
 		Volume:        volume,
-	}
+	}, nil
 }
 
 // convertStockSymbolForTencent 转换股票代码为腾讯API格式
@@ -527,37 +622,27 @@ func padHKStockCode(code string) string {
 // 腾讯搜索结果解析
 // ============================================================================
 
-// parseSearchResults 解析腾讯搜索结果
-func parseSearchResults(content, keyword string) *StockData {
+// parseSearchResults 解析腾讯搜索结果，返回全部候选股票
+func parseSearchResults(content string) []SearchCandidate {
 	logDebug("log.api.parseSearchStart")
 
 	// 尝试解析新的腾讯格式 (v_hint=)
-	result := parseTencentHintFormat(content)
-	if result != nil {
-		return result
+	if strings.Contains(content, "v_hint=") {
+		return parseTencentHintFormat(content)
 	}
 
 	// 尝试解析JSON格式的响应
-	result = parseJSONSearchResults(content, keyword)
-	if result != nil {
-		return result
+	if candidates := parseJSONSearchResults(content); len(candidates) > 0 {
+		return candidates
 	}
 
 	// 如果JSON解析失败，尝试解析旧格式
-	return parseLegacySearchResults(content, keyword)
+	return parseLegacySearchResults(content)
 }
 
 // parseTencentHintFormat 解析腾讯Hint格式的搜索结果
-func parseTencentHintFormat(content string) *StockData {
-	// 格式: v_hint="sz~000880~潍柴重机~wczj~GP-A"
-	logDebug("log.api.tryTencentHint")
-
-	// 查找v_hint=
-	if !strings.Contains(content, "v_hint=") {
-		return nil
-	}
-
-	// 提取引号内的内容
+func parseTencentHintFormat(content string) []SearchCandidate {
+	// 格式: v_hint="sz~000880~潍柴重机~wczj~GP-A^hk~00700~腾讯控股~txkg~GP^us~aapl.oq~苹果~pg~GP"
 	startPos := strings.Index(content, "v_hint=\"")
 	if startPos == -1 {
 		return nil
@@ -569,56 +654,59 @@ func parseTencentHintFormat(content string) *StockData {
 		return nil
 	}
 
+	// 无结果时返回 v_hint="N"
 	data := content[startPos : startPos+endPos]
-	logDebug("log.api.extractedData", data)
-
-	// 按^分割多个结果，取第一个
-	results := strings.Split(data, "^")
-	if len(results) == 0 {
-		logDebug("log.api.noSearchResult")
+	if data == "" || data == "N" {
 		return nil
 	}
 
-	// 处理第一个结果
-	firstResult := results[0]
-	fields := strings.Split(firstResult, "~")
-	if len(fields) < 3 {
-		logDebug("log.api.fieldsNotEnough", len(fields))
-		return nil
-	}
-
-	market := fields[0] // sz, sh, hk
-	code := fields[1]   // 000880
-	name := fields[2]   // 潍柴重机（可能是Unicode编码）
-
-	// 尝试解码Unicode字符串
-	decodedName, err := strconv.Unquote(`"` + name + `"`)
-	if err == nil {
-		name = decodedName
-	}
+	// 按^分割多个结果
+	var candidates []SearchCandidate
+	for _, item := range strings.Split(data, "^") {
+		fields := strings.Split(item, "~")
+		if len(fields) < 3 {
+			continue
+		}
 
-	logDebug("log.api.parseResultDetail", market, code, name)
+		market := fields[0] // sz, sh, hk, us
+		code := fields[1]   // 000880
+		name := fields[2]   // 潍柴重机（可能是Unicode编码）
 
-	// 对于搜索结果，直接返回第一个匹配项（因为用户输入的关键词已经被API处理过了）
-	if true {
-		// 转换为标准格式
-		standardCode := strings.ToUpper(market) + code
-		logDebug("log.api.tencentHintFound", name, standardCode)
+		// 尝试解码Unicode字符串
+		if decodedName, err := strconv.Unquote(`"` + name + `"`); err == nil {
+			name = decodedName
+		}
 
-		// 获取详细信息
-		stockData := getStockPrice(standardCode)
-		if stockData != nil && stockData.Price > 0 {
-			stockData.Symbol = standardCode
-			stockData.Name = name
-			return stockData
+		symbol, exchange := convertTencentHintCode(market, code)
+		if symbol == "" {
+			continue // 基金、北交所等暂不支持获取报价的市场
+		}
+		candidate := SearchCandidate{Symbol: symbol, Name: name, Exchange: exchange}
+		if len(fields) > 3 {
+			candidate.Pinyin = strings.ToLower(fields[3])
 		}
+		candidates = append(candidates, candidate)
 	}
+	return candidates
+}
 
-	return nil
+// convertTencentHintCode 将腾讯Hint格式的市场和代码转换为标准代码，美股同时返回交易所
+func convertTencentHintCode(market, code string) (string, string) {
+	switch strings.ToLower(market) {
+	case "sh", "sz":
+		return strings.ToUpper(market) + code, ""
+	case "hk":
+		return "HK" + padHKStockCode(code), ""
+	case "us":
+		// 美股代码带交易所后缀: aapl.oq (NASDAQ) / ko.n (NYSE)
+		ticker, suffix, _ := strings.Cut(code, ".")
+		return strings.ToUpper(ticker), tencentUSExchanges[strings.ToLower(suffix)]
+	}
+	return "", ""
 }
 
 // parseJSONSearchResults 解析JSON格式的搜索结果
-func parseJSONSearchResults(content, keyword string) *StockData {
+func parseJSONSearchResults(content string) []SearchCandidate {
 	// 尝试解析为JSON
 	var searchResult map[string]interface{}
 	if err := json.Unmarshal([]byte(content), &searchResult); err != nil {
@@ -639,6 +727,7 @@ func parseJSONSearchResults(content, keyword string) *StockData {
 		return nil
 	}
 
+	var candidates []SearchCandidate
 	for _, item := range dataArray {
 		itemMap, ok := item.(map[string]interface{})
 		if !ok {
@@ -652,35 +741,17 @@ func parseJSONSearchResults(content, keyword string) *StockData {
 		if code == "" || name == "" {
 			continue
 		}
-
-		// 检查名称是否匹配关键词
-		if strings.Contains(name, keyword) {
-			logDebug("log.api.jsonFormatFound", name, code)
-
-			// 转换为标准格式
-			standardCode := convertJSONCodeToStandard(code)
-
-			// 获取详细信息
-			stockData := getStockPrice(standardCode)
-			if stockData != nil && stockData.Price > 0 {
-				stockData.Symbol = standardCode
-				stockData.Name = name
-				return stockData
-			}
-		}
+		candidates = append(candidates, SearchCandidate{Symbol: convertJSONCodeToStandard(code), Name: name})
 	}
-
-	return nil
+	return candidates
 }
 
 // parseLegacySearchResults 解析旧格式的搜索结果
-func parseLegacySearchResults(content, keyword string) *StockData {
-	logDebug("log.api.useLegacyFormat")
+func parseLegacySearchResults(content string) []SearchCandidate {
 	// 腾讯搜索结果格式分析
 	// 格式类似: v_s_关键词="sz002415~海康威视~002415~7.450~-0.160~-2.105~15270~7705~7565~7.610"
-	lines := strings.Split(content, "\n")
-
-	for _, line := range lines {
+	var candidates []SearchCandidate
+	for _, line := range strings.Split(content, "\n") {
 		if !strings.Contains(line, "~") {
 			continue
 		}
@@ -693,9 +764,7 @@ func parseLegacySearchResults(content, keyword string) *StockData {
 		}
 
 		// 提取数据部分
-		data := line[startPos+1 : endPos]
-		fields := strings.Split(data, "~")
-
+		fields := strings.Split(line[startPos+1:endPos], "~")
 		if len(fields) < 4 {
 			continue
 		}
@@ -704,25 +773,9 @@ func parseLegacySearchResults(content, keyword string) *StockData {
 		code := fields[0]
 		name := fields[1]
 		shortCode := fields[2]
-
-		// 检查名称是否匹配关键词
-		if strings.Contains(name, keyword) {
-			logDebug("log.api.legacyFormatFound", name, code)
-
-			// 转换为标准格式
-			standardCode := convertToStandardCode(code, shortCode)
-
-			// 获取详细信息
-			stockData := getStockPrice(standardCode)
-			if stockData != nil && stockData.Price > 0 {
-				stockData.Symbol = standardCode
-				stockData.Name = name
-				return stockData
-			}
-		}
+		candidates = append(candidates, SearchCandidate{Symbol: convertToStandardCode(code, shortCode), Name: name})
 	}
-
-	return nil
+	return candidates
 }
 
 // convertJSONCodeToStandard 转换JSON格式的股票代码为标准格式
@@ -766,12 +819,17 @@ func convertToStandardCode(code, shortCode string) string {
 // 新浪 API
 // ============================================================================
 
-// searchStockBySinaAPI 使用新浪财经搜索API查找股票
+// searchStockBySinaAPI 使用新浪财经搜索API查找股票（取最匹配且能获取报价的一只）
 func searchStockBySinaAPI(keyword string) *StockData {
+	return quoteBestCandidate(keyword, fetchSinaSearchCandidates(keyword))
+}
+
+// fetchSinaSearchCandidates 使用新浪财经搜索API查找候选股票
+func fetchSinaSearchCandidates(keyword string) []SearchCandidate {
 	logDebug("log.api.sinaSearchStart", keyword)
 
 	// 新浪财经搜索API URL
-	url := fmt.Sprintf("https://suggest3.sinajs.cn/suggest/type=11,12,13,14,15&key=%s", keyword)
+	url := fmt.Sprintf("%s/suggest/type=11,12,13,14,15&key=%s", apiEndpoints.SinaSearch, keyword)
 	logDebug("log.api.sinaRequestUrl", url)
 
 	client := &http.Client{Timeout: 8 * time.Second}
@@ -792,54 +850,51 @@ func searchStockBySinaAPI(keyword string) *StockData {
 	logDebug("log.api.sinaResponse", content)
 
 	// 解析新浪搜索结果
-	return parseSinaSearchResults(content, keyword)
+	return parseSinaSearchResults(content)
 }
 
 // parseSinaSearchResults 解析新浪搜索结果
-func parseSinaSearchResults(content, keyword string) *StockData {
+func parseSinaSearchResults(content string) []SearchCandidate {
 	// 新浪返回格式类似: var suggestvalue="sz000858,五粮液;sh600519,贵州茅台;";
-	lines := strings.Split(content, ";")
-
-	for _, line := range lines {
-		if !strings.Contains(line, ",") {
-			continue
+	// 完整格式每项为: 名称,类型,代码,完整代码,名称,... (类型 11=A股 31=港股 41=美股)
+	if start := strings.Index(content, "\""); start != -1 {
+		if end := strings.LastIndex(content, "\""); end > start {
+			content = content[start+1 : end]
 		}
+	}
 
+	var candidates []SearchCandidate
+	for _, line := range strings.Split(content, ";") {
 		// 提取股票信息
 		parts := strings.Split(line, ",")
 		if len(parts) < 2 {
 			continue
 		}
+		for i := range parts {
+			// 清理代码和名称中的特殊字符
+			parts[i] = strings.Trim(strings.TrimSpace(parts[i]), "\"'")
+		}
 
-		code := strings.TrimSpace(parts[0])
-		name := strings.TrimSpace(parts[1])
-
-		// 清理代码和名称中的特殊字符
-		code = strings.Trim(code, "\"'")
-		name = strings.Trim(name, "\"'")
-
+		code, name := parts[0], parts[1]
+		if len(parts) >= 5 {
+			name = parts[4]
+			switch parts[1] {
+			case "31":
+				code = "hk" + parts[2]
+			case "41":
+				code = parts[2]
+			default:
+				code = parts[3]
+			}
+		}
 		if code == "" || name == "" {
 			continue
 		}
 
-		// 检查名称是否匹配关键词
-		if strings.Contains(name, keyword) {
-			logDebug("log.api.sinaSearchFound", name, code)
-
-			// 转换为标准格式
-			standardCode := convertSinaCodeToStandard(code)
-
-			// 获取详细信息
-			stockData := getStockPrice(standardCode)
-			if stockData != nil && stockData.Price > 0 {
-				stockData.Symbol = standardCode
-				stockData.Name = name
-				return stockData
-			}
-		}
+		logDebug("log.api.sinaSearchFound", name, code)
+		candidates = append(candidates, SearchCandidate{Symbol: convertSinaCodeToStandard(code), Name: name})
 	}
-
-	return nil
+	return candidates
 }
 
 // convertSinaCodeToStandard 转换新浪的股票代码为标准格式
@@ -945,19 +1000,19 @@ func generateSearchKeywords(name string) []string {
 // ============================================================================
 
 // tryFMPFreeAPI 使用免费的Financial Modeling Prep API (不需要API key的基础功能)
-func tryFMPFreeAPI(symbol string) *StockData {
+func tryFMPFreeAPI(ctx context.Context, symbol string) (*StockData, error) {
 	convertedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
 	logDebug("log.api.fmpSearch", convertedSymbol)
 
 	// 尝试使用免费的实时报价接口
-	url := fmt.Sprintf("https://financialmodelingprep.com/api/v3/quote/%s", convertedSymbol)
+	url := fmt.Sprintf("%s/api/v3/quote/%s", apiEndpoints.FMP, convertedSymbol)
 	logDebug("log.api.fmpRequestUrl", url)
 
 	client := &http.Client{Timeout: 8 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		logError("log.api.fmpRequestFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("fmp: %w", err)
 	}
 
 	// 添加用户代理避免被阻止
@@ -966,14 +1021,14 @@ func tryFMPFreeAPI(symbol string) *StockData {
 	resp, err := client.Do(req)
 	if err != nil {
 		logError("log.api.fmpHttpFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("fmp: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logError("log.api.fmpReadFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("fmp: %w", err)
 	}
 
 	logDebug("log.api.fmpResponse", string(body))
@@ -981,18 +1036,18 @@ func tryFMPFreeAPI(symbol string) *StockData {
 	// 检查是否是错误响应
 	if strings.Contains(string(body), "Error Message") {
 		logError("log.api.fmpError")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("fmp: error response for %s", symbol)
 	}
 
 	var results []map[string]any
 	if err := json.Unmarshal(body, &results); err != nil {
 		logError("log.api.fmpJsonFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("fmp: %w", err)
 	}
 
 	if len(results) == 0 {
 		logDebug("log.api.fmpNoData")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("fmp: no data for %s", symbol)
 	}
 
 	result := results[0]
@@ -1030,7 +1085,7 @@ func tryFMPFreeAPI(symbol string) *StockData {
 
 	if price <= 0 {
 		logDebug("log.api.fmpPriceInvalid")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("fmp: invalid price for %s", symbol)
 	}
 
 	change := price - previousClose
@@ -1053,27 +1108,147 @@ func tryFMPFreeAPI(symbol string) *StockData {
 		PrevClose:     previousClose,
 		TurnoverRate:  0,
 		Volume:        volume,
-	}
+	}, nil
 }
 
 // ============================================================================
 // Yahoo Finance API
 // ============================================================================
 
+// yahooChartResult Yahoo chart/spark 接口中单只股票的结果
+type yahooChartResult struct {
+	Meta struct {
+		Symbol               string  `json:"symbol"`
+		LongName             string  `json:"longName"`
+		ShortName            string  `json:"shortName"`
+		RegularMarketPrice   float64 `json:"regularMarketPrice"`
+		ChartPreviousClose   float64 `json:"chartPreviousClose"`
+		RegularMarketDayHigh float64 `json:"regularMarketDayHigh"`
+		RegularMarketDayLow  float64 `json:"regularMarketDayLow"`
+		RegularMarketVolume  int64   `json:"regularMarketVolume"`
+	} `json:"meta"`
+	Indicators struct {
+		Quote []struct {
+			Open   []float64 `json:"open"`
+			High   []float64 `json:"high"`
+			Low    []float64 `json:"low"`
+			Close  []float64 `json:"close"`
+			Volume []int64   `json:"volume"`
+		} `json:"quote"`
+	} `json:"indicators"`
+}
+
 // tryYahooFinanceAPI 使用Yahoo Finance API作为备用方案
-func tryYahooFinanceAPI(symbol string) *StockData {
-	convertedSymbol := strings.ToUpper(strings.TrimSpace(symbol))
+func tryYahooFinanceAPI(ctx context.Context, symbol string) (*StockData, error) {
+	convertedSymbol := yahooQuoteSymbol(symbol)
 	logDebug("log.api.yahooSearch", convertedSymbol)
 
 	// 使用Yahoo Finance的chart API接口，这个接口更稳定
-	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1d&range=1d", convertedSymbol)
+	url := fmt.Sprintf("%s/v8/finance/chart/%s?interval=1d&range=1d", apiEndpoints.Yahoo, convertedSymbol)
+	body, err := fetchYahooJSON(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var yahooResp struct {
+		Chart struct {
+			Result []yahooChartResult `json:"result"`
+			Error  any                `json:"error"`
+		} `json:"chart"`
+	}
+
+	if err := json.Unmarshal(body, &yahooResp); err != nil {
+		logError("log.api.yahooJsonFail", err)
+		return nil, fmt.Errorf("yahoo: %w", err)
+	}
+
+	if yahooResp.Chart.Error != nil {
+		logError("log.api.yahooError", yahooResp.Chart.Error)
+		return nil, fmt.Errorf("yahoo: api error: %v", yahooResp.Chart.Error)
+	}
+
+	if len(yahooResp.Chart.Result) == 0 {
+		logDebug("log.api.yahooNoData")
+		return nil, fmt.Errorf("yahoo: no data for %s", symbol)
+	}
+
+	return parseYahooChartResult(symbol, yahooResp.Chart.Result[0])
+}
+
+// tryYahooBatchAPI 使用Yahoo spark接口一次获取多只股票价格
+// spark 接口每次最多支持 yahooBatchSize 个代码
+func tryYahooBatchAPI(ctx context.Context, symbols []string) (map[string]*StockData, error) {
+	results := make(map[string]*StockData)
+	var errs []error
+
+	for start := 0; start < len(symbols); start += yahooBatchSize {
+		chunk := symbols[start:min(start+yahooBatchSize, len(symbols))]
+
+		// Yahoo 代码 -> 原始代码
+		symbolMap := make(map[string]string, len(chunk))
+		yahooSymbols := make([]string, 0, len(chunk))
+		for _, symbol := range chunk {
+			convertedSymbol := yahooQuoteSymbol(symbol)
+			symbolMap[convertedSymbol] = symbol
+			yahooSymbols = append(yahooSymbols, convertedSymbol)
+		}
+
+		url := fmt.Sprintf("%s/v7/finance/spark?symbols=%s&interval=1d&range=1d",
+			apiEndpoints.Yahoo, strings.Join(yahooSymbols, ","))
+		body, err := fetchYahooJSON(ctx, url)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var sparkResp struct {
+			Spark struct {
+				Result []struct {
+					Symbol   string             `json:"symbol"`
+					Response []yahooChartResult `json:"response"`
+				} `json:"result"`
+				Error any `json:"error"`
+			} `json:"spark"`
+		}
+
+		if err := json.Unmarshal(body, &sparkResp); err != nil {
+			logError("log.api.yahooJsonFail", err)
+			errs = append(errs, fmt.Errorf("yahoo: %w", err))
+			continue
+		}
+
+		if sparkResp.Spark.Error != nil {
+			logError("log.api.yahooError", sparkResp.Spark.Error)
+			errs = append(errs, fmt.Errorf("yahoo: api error: %v", sparkResp.Spark.Error))
+			continue
+		}
+
+		for _, item := range sparkResp.Spark.Result {
+			symbol, exists := symbolMap[strings.ToUpper(item.Symbol)]
+			if !exists || len(item.Response) == 0 {
+				continue
+			}
+			data, err := parseYahooChartResult(symbol, item.Response[0])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			results[symbol] = data
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// fetchYahooJSON 请求Yahoo接口并返回响应内容
+func fetchYahooJSON(ctx context.Context, url string) ([]byte, error) {
 	logDebug("log.api.yahooRequestUrl", url)
 
 	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		logError("log.api.yahooRequestFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("yahoo: %w", err)
 	}
 
 	// 添加完整的浏览器请求头以避免被阻止
@@ -1085,71 +1260,32 @@ func tryYahooFinanceAPI(symbol string) *StockData {
 	resp, err := client.Do(req)
 	if err != nil {
 		logError("log.api.yahooHttpFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("yahoo: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 429 {
 		logDebug("log.api.yahooRateLimit")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("yahoo: rate limited (HTTP %d)", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logError("log.api.yahooReadFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("yahoo: %w", err)
 	}
 
 	logDebug("log.api.yahooResponse", string(body))
+	return body, nil
+}
 
-	var yahooResp struct {
-		Chart struct {
-			Result []struct {
-				Meta struct {
-					Symbol               string  `json:"symbol"`
-					LongName             string  `json:"longName"`
-					ShortName            string  `json:"shortName"`
-					RegularMarketPrice   float64 `json:"regularMarketPrice"`
-					ChartPreviousClose   float64 `json:"chartPreviousClose"`
-					RegularMarketDayHigh float64 `json:"regularMarketDayHigh"`
-					RegularMarketDayLow  float64 `json:"regularMarketDayLow"`
-					RegularMarketVolume  int64   `json:"regularMarketVolume"`
-				} `json:"meta"`
-				Indicators struct {
-					Quote []struct {
-						Open   []float64 `json:"open"`
-						High   []float64 `json:"high"`
-						Low    []float64 `json:"low"`
-						Close  []float64 `json:"close"`
-						Volume []int64   `json:"volume"`
-					} `json:"quote"`
-				} `json:"indicators"`
-			} `json:"result"`
-			Error any `json:"error"`
-		} `json:"chart"`
-	}
-
-	if err := json.Unmarshal(body, &yahooResp); err != nil {
-		logError("log.api.yahooJsonFail", err)
-		return &StockData{Symbol: symbol, Price: 0}
-	}
-
-	if yahooResp.Chart.Error != nil {
-		logError("log.api.yahooError", yahooResp.Chart.Error)
-		return &StockData{Symbol: symbol, Price: 0}
-	}
-
-	if len(yahooResp.Chart.Result) == 0 {
-		logDebug("log.api.yahooNoData")
-		return &StockData{Symbol: symbol, Price: 0}
-	}
-
-	result := yahooResp.Chart.Result[0]
+// parseYahooChartResult 将Yahoo chart结果转换为 StockData
+func parseYahooChartResult(symbol string, result yahooChartResult) (*StockData, error) {
 	meta := result.Meta
 
 	if meta.RegularMarketPrice <= 0 {
 		logDebug("log.api.yahooPriceInvalid")
-		return &StockData{Symbol: symbol, Price: 0}
+		return nil, fmt.Errorf("yahoo: invalid price for %s", symbol)
 	}
 
 	// 获取开盘价、最高价、最低价
@@ -1208,67 +1344,175 @@ func tryYahooFinanceAPI(symbol string) *StockData {
 		PrevClose:     meta.ChartPreviousClose,
 		TurnoverRate:  0,
 		Volume:        volume,
-	}
+	}, nil
 }
 
 // ============================================================================
 // 主要价格获取入口
 // ============================================================================
 
-// getStockPrice 获取股票价格（带多API降级策略）
+// getStockPrice 获取股票价格（按配置的数据源顺序降级）
 func getStockPrice(symbol string) *StockData {
-	if isChinaStock(symbol) || isHKStock(symbol) {
-		data := tryTencentAPI(symbol)
-		if data.Price > 0 {
-			if isHKStock(symbol) && data.TurnoverRate == 0 {
-				logDebug("log.api.hkTurnoverMissing", symbol)
-				turnover, volume, err := tryEastMoneyHKTurnover(symbol)
-				if err == nil {
-					data.TurnoverRate = turnover
-					if volume > 0 {
-						data.Volume = volume
+	data, err := fetchQuote(context.Background(), symbol)
+	if err != nil {
+		logError("log.api.allApiFail", err)
+		return nil
+	}
+	return data
+}
+
+// fetchQuote 依次尝试该市场配置的行情数据源，返回第一个成功的结果
+// 所有数据源都失败时返回合并后的错误
+func fetchQuote(ctx context.Context, symbol string) (*StockData, error) {
+	market := getMarketType(symbol)
+	providers := getQuoteProviderRegistry().ProvidersFor(market)
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no quote provider configured for market %s", market)
+	}
+
+	var errs []error
+	for _, provider := range providers {
+		data, err := provider.Fetch(ctx, symbol)
+		if err != nil {
+			logWarn("log.api.providerFail", provider.Name(), symbol, err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		if market == MarketHongKong && data.TurnoverRate == 0 {
+			enrichHKTurnover(symbol, data)
+		}
+		return data, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// quoteFetchWorkers 不支持批量的数据源同时进行的最大请求数
+const quoteFetchWorkers = 8
+
+// fetchQuotesBatch 批量获取多只股票报价
+// 按市场分组后按配置顺序依次尝试该市场的数据源，每个数据源只请求前面的数据源仍缺失的代码：
+// 支持批量的数据源每组只发一次请求，其余数据源逐个请求（并发数受限）。返回成功获取的数据以及每只失败股票的错误
+func fetchQuotesBatch(ctx context.Context, symbols []string) (map[string]*StockData, map[string]error) {
+	results := make(map[string]*StockData)
+	failures := make(map[string][]error)
+
+	for market, group := range groupSymbolsByMarket(symbols) {
+		remaining := group
+		for _, provider := range getQuoteProviderRegistry().ProvidersFor(market) {
+			if len(remaining) == 0 {
+				break
+			}
+
+			data, errs := fetchQuotesWithProvider(ctx, provider, remaining)
+			var next []string
+			for _, symbol := range remaining {
+				if stockData, ok := data[symbol]; ok && stockData != nil {
+					if market == MarketHongKong && stockData.TurnoverRate == 0 {
+						enrichHKTurnover(symbol, stockData)
 					}
-					logDebug("log.api.hkTurnoverEnhanced", symbol, turnover)
-				} else {
-					logError("log.api.hkTurnoverFallbackFail", err)
+					results[symbol] = stockData
+					continue
 				}
+				failures[symbol] = append(failures[symbol], fmt.Errorf("%s: %w", provider.Name(), errs[symbol]))
+				next = append(next, symbol)
 			}
-			return data
+			logDebug("log.api.providerBatchDone", provider.Name(), market, len(remaining)-len(next), len(remaining))
+			remaining = next
 		}
-		logError("log.api.tencentFail")
-	}
 
-	data := tryFinnhubAPI(symbol)
-	if data.Price > 0 {
-		return data
+		for _, symbol := range remaining {
+			if len(failures[symbol]) == 0 {
+				failures[symbol] = append(failures[symbol], fmt.Errorf("no quote provider configured for market %s", market))
+			}
+		}
 	}
 
-	logError("log.api.allApiFail")
-	return nil
+	errs := make(map[string]error, len(failures))
+	for symbol, symbolErrs := range failures {
+		if _, ok := results[symbol]; !ok {
+			errs[symbol] = errors.Join(symbolErrs...)
+		}
+	}
+	return results, errs
 }
 
-// tryFinnhubAPI 尝试美股API（实际上是多API降级策略）
-func tryFinnhubAPI(symbol string) *StockData {
-	// 策略1: 尝试TwelveData API
-	data := tryTwelveDataAPI(symbol)
-	if data != nil && data.Price > 0 {
-		return data
-	}
+// fetchQuotesWithProvider 使用单个数据源获取一组股票报价
+// 批量数据源只发起一次请求，普通数据源对每只股票并发请求（最多 quoteFetchWorkers 个同时进行）
+func fetchQuotesWithProvider(ctx context.Context, provider QuoteProvider, symbols []string) (map[string]*StockData, map[string]error) {
+	errs := make(map[string]error)
 
-	// 策略2: 尝试免费的 FMP API (无需API key的基础数据)
-	data = tryFMPFreeAPI(symbol)
-	if data != nil && data.Price > 0 {
-		return data
+	if batchProvider, ok := provider.(BatchQuoteProvider); ok {
+		data, err := batchProvider.FetchBatch(ctx, symbols)
+		if err != nil {
+			logWarn("log.api.providerFail", provider.Name(), strings.Join(symbols, ","), err)
+		}
+		if data == nil {
+			data = make(map[string]*StockData)
+		}
+		for _, symbol := range symbols {
+			if _, ok := data[symbol]; ok {
+				continue
+			}
+			if err != nil {
+				errs[symbol] = err
+			} else {
+				errs[symbol] = fmt.Errorf("no data returned for %s", symbol)
+			}
+		}
+		return data, errs
+	}
+
+	data := make(map[string]*StockData)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, quoteFetchWorkers)
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func(symbol string) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			stockData, err := provider.Fetch(ctx, symbol)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logWarn("log.api.providerFail", provider.Name(), symbol, err)
+				errs[symbol] = err
+				return
+			}
+			data[symbol] = stockData
+		}(symbol)
 	}
+	wg.Wait()
+	return data, errs
+}
 
-	// 策略3: 尝试Yahoo Finance API
-	data = tryYahooFinanceAPI(symbol)
-	if data != nil && data.Price > 0 {
-		return data
+// groupSymbolsByMarket 按市场类型分组股票代码（组内保持原有顺序）
+func groupSymbolsByMarket(symbols []string) map[MarketType][]string {
+	groups := make(map[MarketType][]string)
+	for _, symbol := range symbols {
+		market := getMarketType(symbol)
+		groups[market] = append(groups[market], symbol)
 	}
+	return groups
+}
 
-	logError("log.api.allUsApiFail")
-	return &StockData{Symbol: symbol, Price: 0}
+// enrichHKTurnover 使用东方财富补充港股换手率和成交量
+func enrichHKTurnover(symbol string, data *StockData) {
+	logDebug("log.api.hkTurnoverMissing", symbol)
+	turnover, volume, err := tryEastMoneyHKTurnover(symbol)
+	if err != nil {
+		logError("log.api.hkTurnoverFallbackFail", err)
+		return
+	}
+	data.TurnoverRate = turnover
+	if volume > 0 {
+		data.Volume = volume
+	}
+	logDebug("log.api.hkTurnoverEnhanced", symbol, turnover)
 }
 
 // ============================================================================
@@ -1286,8 +1530,8 @@ func tryEastMoneyHKTurnover(symbol string) (float64, int64, error) {
 
 	// 构建API URL (只请求必要字段以减少流量)
 	url := fmt.Sprintf(
-		"https://push2.eastmoney.com/api/qt/stock/get?secid=%s&fields=f47,f168",
-		emCode,
+		"%s/api/qt/stock/get?secid=%s&fields=f47,f168",
+		apiEndpoints.EastMoney, emCode,
 	)
 	logDebug("log.api.eastmoneyTurnoverUrl", url)
 
@@ -31,9 +31,7 @@ func TestConvertStockCodeForEastMoneyAPI(t *testing.T) {
 }
 
 func TestTryEastMoneyHKTurnover(t *testing.T) {
-	if testing.Short() {
-		t.Skip("跳过集成测试（需要网络）")
-	}
+	useMockQuoteServer(t)
 
 	testCases := []struct {
 		code string
@@ -1,80 +1,237 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // ============================================================================
 // 股价缓存管理
 // ============================================================================
 
+// quoteStaleIntervals 超过多少个刷新间隔没有更新的行情视为过期（默认 5 秒间隔时为 30 秒）
+const quoteStaleIntervals = 6
+
 // getStockPriceFromCache 从缓存获取股价数据（非阻塞）
+// 缓存过期时仍返回上次获取的数据，界面用 staleQuoteMarker 标记，同时等待异步更新
 func (m *Model) getStockPriceFromCache(symbol string) *StockData {
 	m.stockPriceMutex.RLock()
 	defer m.stockPriceMutex.RUnlock()
 	if entry, exists := m.stockPriceCache[symbol]; exists {
-		// 检查缓存是否过期（超过30秒）
-		if time.Since(entry.UpdateTime) < 30*time.Second {
-			return entry.Data
-		}
+		return entry.Data
 	}
-	// 如果缓存中没有数据或已过期，返回nil，触发异步更新
 	return nil
 }
 
+// getFreshStockPrice 从缓存获取未过期的股价数据，没有数据或已过期时返回 nil
+func (m *Model) getFreshStockPrice(symbol string) *StockData {
+	m.stockPriceMutex.RLock()
+	entry, exists := m.stockPriceCache[symbol]
+	m.stockPriceMutex.RUnlock()
+	if !exists || !m.isQuoteFresh(symbol, entry.UpdateTime, time.Now()) {
+		return nil
+	}
+	return entry.Data
+}
+
+// quoteStaleSince 缓存数据已过期时返回其获取时间
+func (m *Model) quoteStaleSince(symbol string) (time.Time, bool) {
+	m.stockPriceMutex.RLock()
+	entry, exists := m.stockPriceCache[symbol]
+	m.stockPriceMutex.RUnlock()
+	if !exists || entry.Data == nil || m.isQuoteFresh(symbol, entry.UpdateTime, time.Now()) {
+		return time.Time{}, false
+	}
+	return entry.UpdateTime, true
+}
+
+// isQuoteFresh 行情是否未过期：在过期时间（刷新间隔的 quoteStaleIntervals 倍）内获取，
+// 或者市场休市且是交易时段结束后获取的收盘价（暂停刷新期间不算过期）
+func (m *Model) isQuoteFresh(symbol string, updateTime, now time.Time) bool {
+	if now.Sub(updateTime) < quoteStaleIntervals*m.quoteRefreshInterval() {
+		return true
+	}
+	market := getMarketType(symbol)
+	marketConfig := m.marketConfigFor(market)
+	if isMarketOpenForConfig(now, market, marketConfig) {
+		return false
+	}
+	return !updateTime.Before(lastSessionEnd(now, market, marketConfig).Add(postCloseFetchDelay))
+}
+
+// staleQuoteMarker 过期行情的标记（如 "stale since 14:32"，跨天时带日期），未过期时返回空字符串
+func (m *Model) staleQuoteMarker(symbol string) string {
+	since, stale := m.quoteStaleSince(symbol)
+	if !stale {
+		return ""
+	}
+	layout := "15:04"
+	if now := time.Now(); since.YearDay() != now.YearDay() || since.Year() != now.Year() {
+		layout = "01-02 15:04"
+	}
+	return " " + lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf(m.getText("quote.staleSince"), since.Format(layout)))
+}
+
+// ============================================================================
+// 行情缓存持久化
+// ============================================================================
+
+// loadQuoteCache 加载上次保存的行情，启动时在刷新完成前显示
+func loadQuoteCache() map[string]*StockPriceCacheEntry {
+	cache := make(map[string]*StockPriceCacheEntry)
+	data, err := os.ReadFile(quoteCacheFile)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logWarn("log.cache.loadFail", err)
+		return make(map[string]*StockPriceCacheEntry)
+	}
+	for symbol, entry := range cache {
+		if entry == nil || entry.Data == nil {
+			delete(cache, symbol)
+			continue
+		}
+		entry.IsUpdating = false
+	}
+	logInfo("log.cache.loaded", len(cache))
+	return cache
+}
+
+// saveQuoteCache 保存缓存中已有数据的行情（临时文件写入后重命名，避免写入中断损坏文件）
+func (m *Model) saveQuoteCache() {
+	m.stockPriceMutex.RLock()
+	cache := make(map[string]StockPriceCacheEntry, len(m.stockPriceCache))
+	for symbol, entry := range m.stockPriceCache {
+		if entry.Data != nil {
+			cache[symbol] = StockPriceCacheEntry{Data: entry.Data, UpdateTime: entry.UpdateTime}
+		}
+	}
+	m.stockPriceMutex.RUnlock()
+
+	lock := getFileLock(quoteCacheFile)
+	lock.Lock()
+	defer lock.Unlock()
+
+	content, err := json.MarshalIndent(cache, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(quoteCacheFile), 0755)
+	}
+	if err == nil {
+		tempPath := quoteCacheFile + ".tmp"
+		if err = os.WriteFile(tempPath, content, 0644); err == nil {
+			err = os.Rename(tempPath, quoteCacheFile)
+		}
+	}
+	if err != nil {
+		logWarn("log.cache.saveFail", err)
+	}
+}
+
+// quoteUpdatesPending 本轮异步更新是否还有未返回的股票
+func (m *Model) quoteUpdatesPending() bool {
+	m.stockPriceMutex.RLock()
+	defer m.stockPriceMutex.RUnlock()
+	for _, entry := range m.stockPriceCache {
+		if entry.IsUpdating {
+			return true
+		}
+	}
+	return false
+}
+
 // ============================================================================
 // 股价异步更新
 // ============================================================================
 
-// startStockPriceUpdates 启动股价异步更新
+// startStockPriceUpdates 启动股价异步更新（自动更新关闭时不获取，等待手动刷新）
 func (m *Model) startStockPriceUpdates() tea.Cmd {
-	// 检查是否需要开始新的更新周期
-	if time.Since(m.stockPriceUpdateTime) < 5*time.Second {
-		logDebug("log.cache.skipUpdate", time.Since(m.stockPriceUpdateTime))
-		return nil // 还未到更新时间
+	if !m.config.Update.AutoUpdate {
+		logDebug("log.cache.autoUpdateOff")
+		return nil
 	}
 
-	// 收集所有需要更新的股票代码
-	stockCodes := make([]string, 0)
+	stockCodes := m.trackedStockCodes()
+	if len(stockCodes) == 0 {
+		logDebug("log.cache.noStocks")
+		return nil
+	}
 
-	// 添加自选列表中的股票 - 注意：这里应该获取所有自选股票，而不是过滤后的
-	for _, stock := range m.watchlist.Stocks {
-		stockCodes = append(stockCodes, stock.Code)
+	// 只获取开市市场到期的股票和休市市场需要的收盘价
+	stockCodes = m.dueQuoteSymbols(stockCodes, time.Now())
+	if len(stockCodes) == 0 {
+		logDebug("log.cache.skipUpdate")
+		return nil
+	}
+	return m.fetchStockPricesCmd(stockCodes)
+}
+
+// refreshStockPricesNow 立即刷新全部持股和自选股票的行情（手动刷新，不论市场状态和自动更新设置）
+// 正在更新的股票跳过，结果显示在状态消息中
+func (m *Model) refreshStockPricesNow() tea.Cmd {
+	now := time.Now()
+	if m.marketRefreshTime == nil {
+		m.marketRefreshTime = make(map[MarketType]time.Time)
 	}
 
-	// 添加持股列表中的股票
-	for _, stock := range m.portfolio.Stocks {
-		stockCodes = append(stockCodes, stock.Code)
+	var stockCodes []string
+	m.stockPriceMutex.RLock()
+	for _, code := range m.trackedStockCodes() {
+		if entry, exists := m.stockPriceCache[code]; !exists || !entry.IsUpdating {
+			stockCodes = append(stockCodes, code)
+			m.marketRefreshTime[getMarketType(code)] = now
+		}
 	}
+	m.stockPriceMutex.RUnlock()
 
 	if len(stockCodes) == 0 {
-		logDebug("log.cache.noStocks")
+		m.message = m.getText("refresh.nothing")
 		return nil
 	}
+	logInfo("log.cache.manualRefresh", len(stockCodes))
+	m.message = fmt.Sprintf(m.getText("refresh.started"), len(stockCodes))
+	return m.fetchStockPricesCmd(stockCodes)
+}
 
-	// 去重股票代码
-	uniqueCodes := make(map[string]bool)
-	var uniqueStockCodes []string
-	for _, code := range stockCodes {
-		if !uniqueCodes[code] {
-			uniqueCodes[code] = true
-			uniqueStockCodes = append(uniqueStockCodes, code)
+// trackedStockCodes 收集需要更新行情的股票代码（全部自选股票和持股，去重）
+func (m *Model) trackedStockCodes() []string {
+	seen := make(map[string]bool)
+	var stockCodes []string
+	add := func(code string) {
+		if !seen[code] {
+			seen[code] = true
+			stockCodes = append(stockCodes, code)
 		}
 	}
 
+	// 添加自选列表中的股票 - 注意：这里应该获取所有自选股票，而不是过滤后的
+	for _, stock := range m.watchlist.Stocks {
+		add(stock.Code)
+	}
+	// 添加持股列表中的股票
+	for _, stock := range m.portfolio.Stocks {
+		add(stock.Code)
+	}
+	return stockCodes
+}
+
+// fetchStockPricesCmd 标记股票正在更新，并按市场分组发起批量请求
+func (m *Model) fetchStockPricesCmd(stockCodes []string) tea.Cmd {
 	// 更新开始时间
 	m.stockPriceUpdateTime = time.Now()
 
-	logDebug("log.cache.startAsync", len(uniqueStockCodes))
+	logDebug("log.cache.startAsync", len(stockCodes))
 
-	// 逐个发起异步获取请求
-	var cmds []tea.Cmd
-	for _, code := range uniqueStockCodes {
-		// 标记正在更新
-		m.stockPriceMutex.Lock()
+	// 标记正在更新
+	m.stockPriceMutex.Lock()
+	for _, code := range stockCodes {
 		if entry, exists := m.stockPriceCache[code]; exists {
 			entry.IsUpdating = true
 		} else {
@@ -84,44 +241,96 @@ func (m *Model) startStockPriceUpdates() tea.Cmd {
 				IsUpdating: true,
 			}
 		}
-		m.stockPriceMutex.Unlock()
-
-		// 为每个股票添加一个延迟，避免同时请求太多
-		delay := time.Duration(len(cmds)) * 100 * time.Millisecond
-		// 修复闭包问题：将code变量复制到局部变量
-		stockCode := code
+	}
+	m.stockPriceMutex.Unlock()
 
-		// 创建异步命令：延迟后执行 fetchStockPriceCmd
-		cmds = append(cmds, tea.Tick(delay, func(t time.Time) tea.Msg {
-			// 返回一个消息，触发实际的获取命令
-			return fetchStockPriceTriggerMsg{symbol: stockCode}
-		}))
+	// 按市场分组，每组一个批量请求命令（各组并发执行）
+	var cmds []tea.Cmd
+	for market, codes := range groupSymbolsByMarket(stockCodes) {
+		logDebug("log.cache.batchGroup", market, len(codes))
+		cmds = append(cmds, fetchStockPricesBatchCmd(codes))
 	}
 
 	return tea.Batch(cmds...)
 }
 
-// fetchStockPriceTriggerMsg 触发股价获取的消息
-type fetchStockPriceTriggerMsg struct {
-	symbol string
+// ============================================================================
+// 按市场调度刷新
+// ============================================================================
+
+// postCloseFetchDelay 交易时段结束后等待一段时间再做最后一次获取，确保拿到收盘价
+const postCloseFetchDelay = time.Minute
+
+// quoteRefreshInterval 行情刷新间隔（配置 update.refresh_interval，单位秒，设置页面修改后立即生效）
+func (m *Model) quoteRefreshInterval() time.Duration {
+	if m.config.Update.RefreshInterval > 0 {
+		return time.Duration(m.config.Update.RefreshInterval) * time.Second
+	}
+	return refreshInterval
 }
 
-// fetchStockPriceCmd 异步获取单个股票价格（正确的 Bubble Tea 模式）
-func fetchStockPriceCmd(symbol string) tea.Cmd {
-	return func() tea.Msg {
-		// 在后台 goroutine 中执行 API 调用
-		data := getStockPrice(symbol)
+// dueQuoteSymbols 按各市场的交易状态筛选本次需要获取行情的股票，并记录各市场的获取时间
+// 开市的市场按刷新间隔获取；休市的市场在交易时段结束后获取一次收盘价，之后暂停到下一个交易时段
+// 还没有行情数据的股票不论市场状态都会获取，正在更新的股票跳过
+func (m *Model) dueQuoteSymbols(symbols []string, now time.Time) []string {
+	if m.marketRefreshTime == nil {
+		m.marketRefreshTime = make(map[MarketType]time.Time)
+	}
+	interval := m.quoteRefreshInterval()
 
-		var err error
-		if data == nil || data.Price <= 0 {
-			err = fmt.Errorf("failed to get stock price for %s", symbol)
+	var due []string
+	for market, codes := range groupSymbolsByMarket(symbols) {
+		if now.Sub(m.marketRefreshTime[market]) < interval {
+			continue
 		}
+		marketConfig := m.marketConfigFor(market)
+		open := isMarketOpenForConfig(now, market, marketConfig)
+		settled := lastSessionEnd(now, market, marketConfig).Add(postCloseFetchDelay)
 
-		// 返回消息，由 Update() 方法处理
-		return stockPriceUpdateMsg{
-			Symbol: symbol,
-			Data:   data,
-			Error:  err,
+		var marketDue []string
+		m.stockPriceMutex.RLock()
+		for _, code := range codes {
+			entry := m.stockPriceCache[code]
+			switch {
+			case entry != nil && entry.IsUpdating:
+			case open || entry == nil || entry.Data == nil:
+				marketDue = append(marketDue, code)
+			case now.After(settled) && entry.UpdateTime.Before(settled):
+				// 休市后还没有取到收盘价
+				marketDue = append(marketDue, code)
+			}
+		}
+		m.stockPriceMutex.RUnlock()
+
+		if len(marketDue) == 0 {
+			if !open {
+				logDebug("log.cache.marketPaused", market)
+			}
+			continue
+		}
+		m.marketRefreshTime[market] = now
+		logDebug("log.cache.marketDue", market, open, len(marketDue))
+		due = append(due, marketDue...)
+	}
+	return due
+}
+
+// fetchStockPricesBatchCmd 批量获取一组股票价格
+// 合并响应后拆分为逐只股票的 stockPriceUpdateMsg，沿用单只股票的更新处理逻辑
+func fetchStockPricesBatchCmd(symbols []string) tea.Cmd {
+	return func() tea.Msg {
+		// 在后台 goroutine 中执行 API 调用
+		results, errs := fetchQuotesBatch(context.Background(), symbols)
+
+		msgs := make(tea.BatchMsg, 0, len(symbols))
+		for _, symbol := range symbols {
+			msg := stockPriceUpdateMsg{
+				Symbol: symbol,
+				Data:   results[symbol],
+				Error:  errs[symbol],
+			}
+			msgs = append(msgs, func() tea.Msg { return msg })
 		}
+		return msgs
 	}
 }
@@ -0,0 +1,210 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// 交易日历：各市场的休市日和半日市
+// ============================================================================
+
+// 内置日历：calendar/holidays.json，可在配置文件 calendar 中追加或覆盖
+// 日历只覆盖其中出现过的年份，超出时只跳过周末并在日志中警告。交易所每年公布下一年的
+// 休市安排后，在 holidays.json 对应市场的 holidays / half_days 中加入日期并重新编译
+//
+//go:embed calendar/holidays.json
+var bundledCalendarJSON []byte
+
+// calendarSearchDays 查找上一个/下一个交易日时最多检查的天数（长假连同周末最长约 10 天）
+const calendarSearchDays = 30
+
+// marketCalendar 单个市场的交易日历
+type marketCalendar struct {
+	holidays map[string]bool   // 休市日 (YYYYMMDD)
+	halfDays map[string]string // 半日市 (YYYYMMDD) → 提前收盘时间 "HH:MM"
+	years    map[int]bool      // 日历覆盖的年份（内置日历或配置中出现过日期的年份）
+}
+
+// calendarWarnedYears 已警告过未覆盖的市场和年份（每个只警告一次）
+var calendarWarnedYears sync.Map
+
+// marketCalendars 当前生效的交易日历（所有交易日判断都基于这里的数据）
+var marketCalendars = newMarketCalendars(CalendarConfig{})
+
+// initMarketCalendars 在内置日历的基础上应用配置中的休市日、交易日和半日市
+func initMarketCalendars(config CalendarConfig) {
+	marketCalendars = newMarketCalendars(config)
+}
+
+// newMarketCalendars 合并内置日历和配置覆盖（配置优先）
+func newMarketCalendars(overrides CalendarConfig) map[MarketType]*marketCalendar {
+	var bundled CalendarConfig
+	if err := json.Unmarshal(bundledCalendarJSON, &bundled); err != nil {
+		logWarn("log.calendar.bundledFail", err)
+	}
+
+	calendars := make(map[MarketType]*marketCalendar)
+	for market, layers := range map[MarketType][]MarketCalendarConfig{
+		MarketChina:    {bundled.China, overrides.China},
+		MarketUS:       {bundled.US, overrides.US},
+		MarketHongKong: {bundled.HongKong, overrides.HongKong},
+	} {
+		calendar := &marketCalendar{holidays: make(map[string]bool), halfDays: make(map[string]string), years: make(map[int]bool)}
+		for _, layer := range layers {
+			calendar.apply(market, layer)
+		}
+		calendars[market] = calendar
+	}
+	return calendars
+}
+
+// apply 应用一层日历配置：追加休市日和半日市，trading_days 中的日期恢复为完整交易日
+func (c *marketCalendar) apply(market MarketType, config MarketCalendarConfig) {
+	for _, value := range config.Holidays {
+		if date, ok := parseCalendarDate(market, value); ok {
+			c.holidays[date] = true
+			delete(c.halfDays, date)
+			c.addYear(date)
+		}
+	}
+	for value, closeAt := range config.HalfDays {
+		date, ok := parseCalendarDate(market, value)
+		if !ok {
+			continue
+		}
+		c.addYear(date)
+		closeTime, err := time.Parse("15:04", closeAt)
+		if err != nil {
+			logWarn("log.calendar.invalidHalfDay", market, value, closeAt)
+			continue
+		}
+		c.halfDays[date] = closeTime.Format("15:04")
+		delete(c.holidays, date)
+	}
+	for _, value := range config.TradingDays {
+		if date, ok := parseCalendarDate(market, value); ok {
+			delete(c.holidays, date)
+			delete(c.halfDays, date)
+			c.addYear(date)
+		}
+	}
+}
+
+// addYear 记录日历覆盖的年份，date 为 YYYYMMDD
+func (c *marketCalendar) addYear(date string) {
+	if year, err := strconv.Atoi(date[:4]); err == nil {
+		c.years[year] = true
+	}
+}
+
+// covers 日历是否覆盖 year（没有任何日期时视为覆盖，不警告）
+func (c *marketCalendar) covers(year int) bool {
+	return len(c.years) == 0 || c.years[year]
+}
+
+// parseCalendarDate 将 "2006-01-02" 或 "20060102" 转换为 YYYYMMDD
+func parseCalendarDate(market MarketType, value string) (string, bool) {
+	for _, layout := range []string{"2006-01-02", "20060102"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format("20060102"), true
+		}
+	}
+	logWarn("log.calendar.invalidDate", market, value)
+	return "", false
+}
+
+// isTradingDay 判断 day（市场当地日期）是否为交易日：非周末且不在休市日历中
+// 日历未覆盖 day 所在年份时只能跳过周末，记录一次警告提示更新日历
+func isTradingDay(market MarketType, day time.Time) bool {
+	if isWeekend(day) {
+		return false
+	}
+	calendar := marketCalendars[market]
+	if calendar == nil {
+		return true
+	}
+	if !calendar.covers(day.Year()) {
+		if _, warned := calendarWarnedYears.LoadOrStore(fmt.Sprintf("%s/%d", market, day.Year()), true); !warned {
+			logWarn("log.calendar.yearNotCovered", market, day.Year())
+		}
+	}
+	return !calendar.holidays[day.Format("20060102")]
+}
+
+// earlyCloseTime 获取半日市的提前收盘时间 "HH:MM"，date 为 YYYYMMDD，非半日市返回 false
+func earlyCloseTime(market MarketType, date string) (string, bool) {
+	calendar := marketCalendars[market]
+	if calendar == nil {
+		return "", false
+	}
+	closeAt, ok := calendar.halfDays[date]
+	return closeAt, ok
+}
+
+// previousTradingDay 查找 day 之前最近的交易日
+func previousTradingDay(market MarketType, day time.Time) time.Time {
+	for i := 1; i <= calendarSearchDays; i++ {
+		prevDate := day.AddDate(0, 0, -i)
+		if isTradingDay(market, prevDate) {
+			return prevDate
+		}
+	}
+	return day.AddDate(0, 0, -1)
+}
+
+// nextTradingDay 查找 day 之后最近的交易日
+func nextTradingDay(market MarketType, day time.Time) time.Time {
+	for i := 1; i <= calendarSearchDays; i++ {
+		nextDate := day.AddDate(0, 0, i)
+		if isTradingDay(market, nextDate) {
+			return nextDate
+		}
+	}
+	return day.AddDate(0, 0, 1)
+}
+
+// tradingSessionsForDate 获取指定日期的交易时段，半日市在提前收盘时间截断
+func tradingSessionsForDate(sessions []TradingSession, market MarketType, date string) []TradingSession {
+	closeAt, ok := earlyCloseTime(market, date)
+	if !ok {
+		return sessions
+	}
+
+	closeTime, _ := time.Parse("15:04", closeAt)
+	var result []TradingSession
+	for _, session := range sessions {
+		start, err1 := time.Parse("15:04", session.StartTime)
+		end, err2 := time.Parse("15:04", session.EndTime)
+		if err1 != nil || err2 != nil {
+			// 格式错误的时段原样保留，由调用方记录日志
+			result = append(result, session)
+			continue
+		}
+		if !start.Before(closeTime) {
+			continue
+		}
+		if end.After(closeTime) {
+			session.EndTime = closeAt
+		}
+		result = append(result, session)
+	}
+	return result
+}
+
+// sessionMinutes 交易时段的总分钟数
+func sessionMinutes(sessions []TradingSession) int {
+	total := 0
+	for _, session := range sessions {
+		start, err1 := time.Parse("15:04", session.StartTime)
+		end, err2 := time.Parse("15:04", session.EndTime)
+		if err1 == nil && err2 == nil && end.After(start) {
+			total += int(end.Sub(start).Minutes())
+		}
+	}
+	return total
+}
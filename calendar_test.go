@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTradingCalendar 测试内置日历的休市日、半日市以及交易日查找
+func TestTradingCalendar(t *testing.T) {
+	day := func(date string) time.Time {
+		parsed, _ := time.Parse("20060102", date)
+		return parsed
+	}
+
+	// 2025 年春节：1月28日至2月4日休市
+	if isTradingDay(MarketChina, day("20250129")) || !isTradingDay(MarketChina, day("20250127")) {
+		t.Error("春节应休市，节前最后一天应为交易日")
+	}
+	if prev := findPreviousTradingDayFromDate("20250205", MarketChina); prev != "20250127" {
+		t.Errorf("节后第一天的上一个交易日 = %s", prev)
+	}
+	next, err := findNextTradingDay("20250127", MarketChina, day("20250301"))
+	if err != nil || next != "20250205" {
+		t.Errorf("节前最后一天的下一个交易日 = %s, %v", next, err)
+	}
+	if _, err := findNextTradingDay("20250127", MarketChina, day("20250204")); err == nil {
+		t.Error("下一个交易日超过最大日期时应返回错误")
+	}
+
+	// 同一天不同市场的日历互不影响：感恩节只影响美股
+	if isTradingDay(MarketUS, day("20251127")) || !isTradingDay(MarketChina, day("20251127")) {
+		t.Error("感恩节美股休市，A股照常交易")
+	}
+	if next, _ := findNextTradingDay("20251126", MarketUS, day("20251231")); next != "20251128" {
+		t.Errorf("感恩节前的下一个交易日 = %s", next)
+	}
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	if state := getTradingState(time.Date(2025, 11, 27, 10, 0, 0, 0, newYork), MarketUS); state != TradingStateHoliday {
+		t.Errorf("感恩节交易状态 = %v", state)
+	}
+
+	// 感恩节次日美股 13:00 提前收盘
+	if state := getTradingState(time.Date(2025, 11, 28, 12, 30, 0, 0, newYork), MarketUS); state != TradingStateLive {
+		t.Errorf("半日市 12:30 交易状态 = %v", state)
+	}
+	if state := getTradingState(time.Date(2025, 11, 28, 13, 30, 0, 0, newYork), MarketUS); state != TradingStatePostMarket {
+		t.Errorf("半日市 13:30 交易状态 = %v", state)
+	}
+	if expected := getExpectedDatapoints(MarketUS, "20251128", false); expected != 210 {
+		t.Errorf("半日市预期数据点 = %d", expected)
+	}
+
+	// 港股平安夜只有上午时段
+	m := &Model{config: getDefaultConfig()}
+	if points := m.createFixedTimeRange("20251224", MarketHongKong); len(points) != 151 || points[150].Time.Format("15:04") != "12:00" {
+		t.Errorf("港股半日市时间轴 %d 个点", len(points))
+	}
+	hongKong, _ := time.LoadLocation("Asia/Hong_Kong")
+	if isMarketOpenForConfig(time.Date(2025, 12, 24, 14, 0, 0, 0, hongKong), MarketHongKong, m.config.Markets.HongKong) {
+		t.Error("港股半日市下午不应开市")
+	}
+	if _, closeTime, ok := marketClose(time.Date(2025, 12, 24, 14, 0, 0, 0, hongKong), MarketHongKong, m.config.Markets.HongKong); !ok || closeTime.Hour() != 12 {
+		t.Errorf("港股半日市收盘时间 = %v, %v", closeTime, ok)
+	}
+}
+
+// TestCalendarConfigOverrides 测试配置追加休市日、恢复交易日和修改半日市
+func TestCalendarConfigOverrides(t *testing.T) {
+	t.Cleanup(func() { initMarketCalendars(CalendarConfig{}) })
+
+	initMarketCalendars(CalendarConfig{
+		China: MarketCalendarConfig{
+			Holidays:    []string{"2025-03-14", "not-a-date"},
+			TradingDays: []string{"20250129"},
+		},
+		US: MarketCalendarConfig{
+			HalfDays: map[string]string{"2025-07-03": "12:00", "2025-07-07": "noon"},
+		},
+	})
+
+	march14, _ := time.Parse("20060102", "20250314")
+	if isTradingDay(MarketChina, march14) || !isTradingDay(MarketUS, march14) {
+		t.Error("配置的休市日只影响对应市场")
+	}
+	if prev := findPreviousTradingDayFromDate("20250130", MarketChina); prev != "20250129" {
+		t.Errorf("恢复为交易日的日期应可导航到: %s", prev)
+	}
+	if closeAt, ok := earlyCloseTime(MarketUS, "20250703"); !ok || closeAt != "12:00" {
+		t.Errorf("半日市提前收盘时间 = %q", closeAt)
+	}
+	if _, ok := earlyCloseTime(MarketUS, "20250707"); ok {
+		t.Error("无效的提前收盘时间应忽略")
+	}
+	if _, ok := earlyCloseTime(MarketUS, "20251128"); !ok {
+		t.Error("未覆盖的内置半日市应保留")
+	}
+}
+
+// TestCalendarCoveredYears 测试日历覆盖的年份：内置日历只覆盖已有日期的年份，配置中添加的日期扩展覆盖范围
+func TestCalendarCoveredYears(t *testing.T) {
+	calendars := newMarketCalendars(CalendarConfig{})
+	for _, market := range []MarketType{MarketChina, MarketHongKong, MarketUS} {
+		if !calendars[market].covers(2025) || !calendars[market].covers(2026) || calendars[market].covers(2030) {
+			t.Errorf("%s 覆盖年份 = %v", market, calendars[market].years)
+		}
+	}
+
+	calendars = newMarketCalendars(CalendarConfig{China: MarketCalendarConfig{Holidays: []string{"2030-01-01"}}})
+	if !calendars[MarketChina].covers(2030) || calendars[MarketUS].covers(2030) {
+		t.Error("配置中的休市日应只扩展对应市场的覆盖年份")
+	}
+
+	// 未覆盖的年份仍按周末判断
+	if !isTradingDay(MarketChina, time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)) || isTradingDay(MarketChina, time.Date(2030, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Error("未覆盖的年份应只跳过周末")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	"github.com/NimbleMarkets/ntcharts/linechart"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// 分时图十字光标
+// ============================================================================
+
+// chartCursorJump H/L 键一次移动的数据点数
+const chartCursorJump = 10
+
+var (
+	chartCursorLineStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240")) // 灰色竖线
+	chartCursorMarkerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))  // 黄色价格标记
+)
+
+// moveChartCursor 移动光标；光标未显示时先出现在最新一分钟
+func (m *Model) moveChartCursor(delta int) {
+	if m.chartData == nil || len(m.chartData.Datapoints) == 0 {
+		return
+	}
+	last := len(m.chartData.Datapoints) - 1
+	if !m.chartCursorVisible {
+		m.chartCursorVisible = true
+		m.chartCursor = last
+		return
+	}
+	m.chartCursor = max(0, min(last, m.chartCursor+delta))
+}
+
+// chartCursorPoint 光标所在的数据点（切换日期后数据点数量可能变少，按最后一个截断）
+func (m *Model) chartCursorPoint() (IntradayDataPoint, bool) {
+	if !m.chartCursorVisible || m.chartData == nil || len(m.chartData.Datapoints) == 0 {
+		return IntradayDataPoint{}, false
+	}
+	index := max(0, min(len(m.chartData.Datapoints)-1, m.chartCursor))
+	return m.chartData.Datapoints[index], true
+}
+
+// drawChartCursor 在价格图上光标所在分钟画竖线，并在该分钟价格处画标记
+// timeLabels: 时间框架索引 → "15:04"；minY/maxY: 纵轴范围
+func (m *Model) drawChartCursor(lc *linechart.Model, timeLabels []string, minY, maxY float64) {
+	dp, ok := m.chartCursorPoint()
+	if !ok {
+		return
+	}
+	index := slices.Index(timeLabels, dp.Time)
+	if index < 0 {
+		return
+	}
+	x := float64(index)
+	lc.DrawRuneLineWithStyle(canvas.Float64Point{X: x, Y: minY}, canvas.Float64Point{X: x, Y: maxY}, '│', chartCursorLineStyle)
+	lc.DrawRuneWithStyle(canvas.Float64Point{X: x, Y: dp.Price}, '●', chartCursorMarkerStyle)
+}
+
+// chartCursorStatus 光标状态行：时间、价格、相对昨收的涨跌和该分钟成交量
+func (m *Model) chartCursorStatus() string {
+	dp, ok := m.chartCursorPoint()
+	if !ok {
+		return ""
+	}
+
+	// 与统计信息行一致：昨收不可用时以开盘价为基准
+	base := m.chartData.PrevClose
+	if base == 0 {
+		base = m.chartData.Datapoints[0].Price
+	}
+	change := dp.Price - base
+	changePercent := 0.0
+	if base != 0 {
+		changePercent = change / base * 100
+	}
+
+	status := fmt.Sprintf("%s %s  %s: %.2f  %s: %+.2f (%+.2f%%)",
+		m.getText("chart.cursor"), dp.Time,
+		m.getText("col.price"), dp.Price,
+		m.getText("change"), change, changePercent,
+	)
+	if hasVolumeData(m.chartData) {
+		status += fmt.Sprintf("  %s: %s", m.getText("col.volume"), formatVolume(dp.Volume))
+	}
+
+	// A股红涨绿跌，非A股绿涨红跌
+	isAShare := strings.HasPrefix(m.chartData.Code, "SH") || strings.HasPrefix(m.chartData.Code, "SZ")
+	style := lipgloss.NewStyle().Bold(true)
+	if change != 0 {
+		style = trendStyle(isAShare, change > 0).Bold(true)
+	}
+	return style.Render(status)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestChartCursor 测试分时图光标的移动、状态行和 ESC 隐藏
+func TestChartCursor(t *testing.T) {
+	m := &Model{config: getDefaultConfig(), language: English, state: IntradayChartViewing, previousState: Monitoring}
+	data := &IntradayData{Code: "SH600000", Name: "浦发银行", Date: "20250314", Market: MarketChina, PrevClose: 10}
+	for i := range 30 {
+		minute := time.Date(2025, 3, 14, 9, 30+i, 0, 0, time.UTC)
+		data.Datapoints = append(data.Datapoints, IntradayDataPoint{Time: minute.Format("15:04"), Price: 10 + float64(i)*0.01, Volume: int64(100 * (i + 1))})
+	}
+	m.chartData = data
+	m.chartViewStock, m.chartViewDate = data.Code, data.Date
+
+	key := func(s string) {
+		m.handleIntradayChartViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+	}
+
+	// 第一次按键光标出现在最新一分钟
+	key("l")
+	if dp, ok := m.chartCursorPoint(); !ok || dp.Time != "09:59" {
+		t.Fatalf("cursor = %+v, %v", dp, ok)
+	}
+	key(".")
+	if m.chartCursor != 29 {
+		t.Errorf("右移不应超过最后一个数据点: %d", m.chartCursor)
+	}
+	key("H")
+	key(",")
+	dp, _ := m.chartCursorPoint()
+	if dp.Time != "09:48" {
+		t.Errorf("cursor time = %s", dp.Time)
+	}
+
+	view := m.viewIntradayChart(120, 40)
+	for _, want := range []string{"09:48", "+0.18 (+1.80%)", ": 1900", "●"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("光标视图缺少 %q:\n%s", want, view)
+		}
+	}
+
+	for range 5 {
+		key("H")
+	}
+	if m.chartCursor != 0 {
+		t.Errorf("左移不应小于 0: %d", m.chartCursor)
+	}
+
+	// ESC 先隐藏光标，再次按下才返回
+	m.handleIntradayChartViewing(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != IntradayChartViewing || m.chartCursorVisible {
+		t.Fatalf("state = %v, cursor = %v", m.state, m.chartCursorVisible)
+	}
+	if view := m.viewIntradayChart(120, 40); strings.Contains(view, "●") {
+		t.Error("隐藏后不应绘制光标")
+	}
+	m.handleIntradayChartViewing(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != Monitoring {
+		t.Errorf("state = %v", m.state)
+	}
+}
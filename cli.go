@@ -0,0 +1,708 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// ============================================================================
+// 命令行子命令（不启动 TUI，适用于脚本和定时任务）
+// ============================================================================
+
+// 命令行退出码
+const (
+	exitOK      = 0 // 成功
+	exitFailure = 1 // 行情获取失败或数据保存失败
+	exitUsage   = 2 // 参数错误
+)
+
+// cliOutputFormat 命令行输出格式
+type cliOutputFormat string
+
+const (
+	cliFormatTable cliOutputFormat = "table"
+	cliFormatJSON  cliOutputFormat = "json"
+	cliFormatCSV   cliOutputFormat = "csv"
+)
+
+// cliQuoteTimeout 命令行获取行情的超时时间
+const cliQuoteTimeout = 20 * time.Second
+
+// cliCommand 命令行上下文（无界面的 Model 和输出流）
+type cliCommand struct {
+	m      *Model
+	stdout io.Writer
+	stderr io.Writer
+	color  bool // 标准输出是终端时表格保留颜色
+}
+
+// runCLI 执行子命令并返回退出码
+func runCLI(args []string, config Config, stdout, stderr io.Writer) int {
+	language := English
+	if config.System.Language == "zh" {
+		language = Chinese
+	}
+	c := &cliCommand{
+		m: &Model{
+			config:          config,
+			language:        language,
+			watchlist:       loadWatchlist(),
+			alerts:          loadAlerts(),
+			stockPriceCache: make(map[string]*StockPriceCacheEntry),
+			fxRates:         loadFXRates(),
+		},
+		stdout: stdout,
+		stderr: stderr,
+		color:  isTerminal(stdout),
+	}
+	// 命令行输出不需要光标列
+	c.m.config.Display.PortfolioColumns = slices.DeleteFunc(slices.Clone(config.Display.PortfolioColumns), func(id string) bool {
+		return id == string(ColCursor)
+	})
+	c.m.config.Display.WatchlistColumns = slices.DeleteFunc(slices.Clone(config.Display.WatchlistColumns), func(id string) bool {
+		return id == string(ColCursor)
+	})
+
+	command, rest := args[0], args[1:]
+	logInfo("log.cli.command", command, strings.Join(rest, " "))
+	switch command {
+	case "quote":
+		return c.runQuote(rest)
+	case "portfolio":
+		return c.runPortfolio(rest)
+	case "watchlist":
+		return c.runWatchlist(rest)
+	case "add":
+		return c.runAdd(rest)
+	case "remove":
+		return c.runRemove(rest)
+	case "serve":
+		return c.runServe(rest)
+	case "daemon":
+		return c.runDaemon(rest)
+	case "symbols":
+		return c.runSymbols(rest)
+	case "export":
+		return c.runExport(rest)
+	case "help", "-h", "--help":
+		fmt.Fprintln(stdout, c.m.getText("cli.usage"))
+		return exitOK
+	}
+	fmt.Fprintf(stderr, c.m.getText("cli.unknownCommand")+"\n\n", command)
+	fmt.Fprintln(stderr, c.m.getText("cli.usage"))
+	return exitUsage
+}
+
+// isTerminal 判断输出是否为终端（重定向到文件或管道时不输出颜色）
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// newFlagSet 创建子命令参数解析器
+func (c *cliCommand) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(c.stderr, c.m.getText("cli.usage"))
+	}
+	return fs
+}
+
+// parseFlags 解析参数（允许参数和选项交替出现，如 quote SH600000 --json AAPL）
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// addFormatFlags 添加 --json / --csv 输出格式选项
+func addFormatFlags(fs *flag.FlagSet) func() cliOutputFormat {
+	asJSON := fs.Bool("json", false, "JSON output")
+	asCSV := fs.Bool("csv", false, "CSV output")
+	return func() cliOutputFormat {
+		switch {
+		case *asJSON:
+			return cliFormatJSON
+		case *asCSV:
+			return cliFormatCSV
+		}
+		return cliFormatTable
+	}
+}
+
+// usageError 参数解析失败时的退出码（错误信息已由 flag 输出，-h 返回成功）
+func (c *cliCommand) usageError(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// failf 输出错误信息到标准错误
+func (c *cliCommand) failf(key string, args ...any) {
+	fmt.Fprintf(c.stderr, c.m.getText(key)+"\n", args...)
+}
+
+// fetchQuotes 批量获取行情并写入缓存，返回获取失败的代码及错误
+func (c *cliCommand) fetchQuotes(symbols []string) map[string]error {
+	if len(symbols) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cliQuoteTimeout)
+	defer cancel()
+
+	results, errs := fetchQuotesBatch(ctx, symbols)
+	now := time.Now()
+	for symbol, data := range results {
+		c.m.stockPriceCache[symbol] = &StockPriceCacheEntry{Data: data, UpdateTime: now}
+	}
+	for _, symbol := range symbols {
+		if err, failed := errs[symbol]; failed {
+			c.failf("cli.fetchFail", symbol, err)
+		}
+	}
+	return errs
+}
+
+// writeTable 按格式输出表格（CSV 使用列 ID 作为表头；CSV 和非终端输出去掉颜色）
+func (c *cliCommand) writeTable(format cliOutputFormat, header, csvHeader table.Row, rows []table.Row) {
+	if format == cliFormatCSV || !c.color {
+		rows = slices.Clone(rows)
+		for i, row := range rows {
+			plain := make(table.Row, len(row))
+			for j, cell := range row {
+				plain[j] = text.StripEscape(fmt.Sprint(cell))
+			}
+			rows[i] = plain
+		}
+	}
+
+	t := table.NewWriter()
+	if format == cliFormatCSV {
+		t.AppendHeader(csvHeader)
+		t.AppendRows(rows)
+		fmt.Fprintln(c.stdout, t.RenderCSV())
+		return
+	}
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(header)
+	t.AppendRows(rows)
+	fmt.Fprintln(c.stdout, t.Render())
+}
+
+// writeJSON 输出 JSON
+func (c *cliCommand) writeJSON(v any) int {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		c.failf("cli.outputFail", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// columnIDs 列元数据对应的列 ID（CSV 表头）
+func columnIDs(columns []*ColumnMetadata) table.Row {
+	row := make(table.Row, len(columns))
+	for i, col := range columns {
+		row[i] = string(col.ID)
+	}
+	return row
+}
+
+// resultCode 根据获取失败数量决定退出码
+func resultCode(errs map[string]error) int {
+	if len(errs) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// ============================================================================
+// quote: 获取行情
+// ============================================================================
+
+func (c *cliCommand) runQuote(args []string) int {
+	fs := c.newFlagSet("quote")
+	format := addFormatFlags(fs)
+	symbols, err := parseFlags(fs, args)
+	if err != nil {
+		return c.usageError(err)
+	}
+	if len(symbols) == 0 {
+		c.failf("cli.codeRequired")
+		return exitUsage
+	}
+	for i, symbol := range symbols {
+		symbols[i] = strings.ToUpper(strings.TrimSpace(symbol))
+	}
+
+	errs := c.fetchQuotes(symbols)
+	quotes := []*StockData{}
+	var watchStocks []WatchlistStock
+	for _, symbol := range symbols {
+		if data := c.m.getStockPriceFromCache(symbol); data != nil {
+			quotes = append(quotes, data)
+			watchStocks = append(watchStocks, WatchlistStock{Code: symbol, Name: data.Name, Market: getMarketType(symbol)})
+		}
+	}
+
+	if format() == cliFormatJSON {
+		if code := c.writeJSON(quotes); code != exitOK {
+			return code
+		}
+		return resultCode(errs)
+	}
+
+	// 行情表格沿用自选列表的列配置（不显示标签列）
+	c.m.config.Display.WatchlistColumns = slices.DeleteFunc(c.m.config.Display.WatchlistColumns, func(id string) bool {
+		return id == string(ColTag)
+	})
+	if len(quotes) > 0 {
+		c.writeWatchlistTable(format(), watchStocks)
+	}
+	return resultCode(errs)
+}
+
+// ============================================================================
+// watchlist: 自选列表行情
+// ============================================================================
+
+func (c *cliCommand) runWatchlist(args []string) int {
+	fs := c.newFlagSet("watchlist")
+	format := addFormatFlags(fs)
+	tag := fs.String("tag", "", "filter by tag")
+	if _, err := parseFlags(fs, args); err != nil {
+		return c.usageError(err)
+	}
+
+	c.m.selectedTag = strings.TrimSpace(*tag)
+	stocks := c.m.getFilteredWatchlist()
+	symbols := make([]string, len(stocks))
+	for i, stock := range stocks {
+		symbols[i] = stock.Code
+	}
+	errs := c.fetchQuotes(symbols)
+
+	if format() == cliFormatJSON {
+		items := c.m.buildWatchlistReport(stocks)
+		if code := c.writeJSON(items); code != exitOK {
+			return code
+		}
+		return resultCode(errs)
+	}
+
+	if len(stocks) == 0 {
+		fmt.Fprintln(c.stderr, c.m.getText("emptyWatchlist"))
+		return exitOK
+	}
+	c.writeWatchlistTable(format(), stocks)
+	return resultCode(errs)
+}
+
+// writeWatchlistTable 使用自选列表的列注册表输出行情表格
+func (c *cliCommand) writeWatchlistTable(format cliOutputFormat, stocks []WatchlistStock) {
+	rows := make([]table.Row, len(stocks))
+	for i := range stocks {
+		rows[i] = c.m.GenerateWatchlistRow(&stocks[i], c.m.getStockPriceFromCache(stocks[i].Code), i, 0, len(stocks))
+	}
+	c.writeTable(format, c.m.GenerateWatchlistHeader(), columnIDs(c.m.GetWatchlistColumns()), rows)
+}
+
+// ============================================================================
+// portfolio: 持仓与盈亏
+// ============================================================================
+
+// resolveAccount 解析 --account 参数（为空时使用配置中的当前账户）
+func (c *cliCommand) resolveAccount(account string) (string, bool) {
+	if account == "" {
+		return c.m.currentAccount(), true
+	}
+	if !accountExists(account) {
+		c.failf("cli.unknownAccount", account)
+		return "", false
+	}
+	c.m.config.Portfolio.Account = account
+	return account, true
+}
+
+func (c *cliCommand) runPortfolio(args []string) int {
+	fs := c.newFlagSet("portfolio")
+	format := addFormatFlags(fs)
+	accountFlag := fs.String("account", "", "account name")
+	if _, err := parseFlags(fs, args); err != nil {
+		return c.usageError(err)
+	}
+	account, ok := c.resolveAccount(*accountFlag)
+	if !ok {
+		return exitUsage
+	}
+	c.m.portfolio = loadAccountPortfolio(account)
+
+	symbols := make([]string, len(c.m.portfolio.Stocks))
+	for i, stock := range c.m.portfolio.Stocks {
+		symbols[i] = stock.Code
+	}
+	errs := c.fetchQuotes(symbols)
+	c.m.updatePortfolioPricesFromCache()
+
+	c.m.refreshFXRatesNow()
+	subtotals, total, missing := c.m.calculatePortfolioTotals()
+
+	if format() == cliFormatJSON {
+		result := c.m.buildPortfolioReport(account)
+		if code := c.writeJSON(result); code != exitOK {
+			return code
+		}
+		return resultCode(errs)
+	}
+
+	if len(c.m.portfolio.Stocks) == 0 {
+		fmt.Fprintln(c.stderr, c.m.getText("emptyPortfolio"))
+		return exitOK
+	}
+
+	stocks := c.m.portfolio.Stocks
+	var rows []table.Row
+	for i := range stocks {
+		rows = append(rows, c.m.GeneratePortfolioRow(&stocks[i], i, 0, len(stocks)))
+	}
+	if format() == cliFormatTable {
+		// 表格与持股页面一致：多币种时显示小计，最后一行为总计
+		if len(subtotals) > 1 {
+			for _, subtotal := range subtotals {
+				rows = append(rows, c.m.GeneratePortfolioTotalRow(subtotal))
+			}
+		}
+		rows = append(rows, c.m.GeneratePortfolioTotalRow(total))
+	}
+	c.writeTable(format(), c.m.GeneratePortfolioHeader(), columnIDs(c.m.GetPortfolioColumns()), rows)
+	if format() == cliFormatTable && len(missing) > 0 {
+		fmt.Fprintf(c.stderr, c.m.getText("fx.missingRates")+"\n", strings.Join(missing, ", "))
+	}
+	return resultCode(errs)
+}
+
+// ============================================================================
+// add / remove: 修改持仓或自选列表
+// ============================================================================
+
+func (c *cliCommand) runAdd(args []string) int {
+	fs := c.newFlagSet("add")
+	toWatchlist := fs.Bool("watchlist", false, "add to watchlist")
+	tag := fs.String("tag", "", "watchlist tag")
+	cost := fs.Float64("cost", 0, "buy price")
+	quantity := fs.Int("quantity", 0, "buy quantity")
+	accountFlag := fs.String("account", "", "account name")
+	codes, err := parseFlags(fs, args)
+	if err != nil {
+		return c.usageError(err)
+	}
+	if len(codes) != 1 {
+		c.failf("cli.codeRequired")
+		return exitUsage
+	}
+	if !*toWatchlist && (*cost <= 0 || *quantity <= 0) {
+		c.failf("cli.costQuantityRequired")
+		return exitUsage
+	}
+
+	// 与界面添加股票相同：先按代码获取，失败时按关键词搜索
+	stockData := getStockInfo(codes[0])
+	if stockData == nil || stockData.Name == "" {
+		c.failf("searchNotFound", codes[0])
+		return exitFailure
+	}
+	code := stockData.Symbol
+
+	if *toWatchlist {
+		changed := c.m.insertWatchlistStock(code, stockData.Name)
+		if !changed {
+			c.failf("cli.alreadyInWatchlist", stockData.Name, code)
+		}
+		if t := strings.TrimSpace(*tag); t != "" {
+			for i := range c.m.watchlist.Stocks {
+				if stock := &c.m.watchlist.Stocks[i]; stock.Code == code && !stock.hasTag(t) {
+					stock.Tags = append(stock.Tags, t)
+					changed = true
+				}
+			}
+		}
+		if changed {
+			if err := c.m.saveWatchlist(); err != nil {
+				c.failf("cli.saveFail", err)
+				return exitFailure
+			}
+		}
+		fmt.Fprintf(c.stdout, c.m.getText("addWatchSuccess")+"\n", stockData.Name, code)
+		return exitOK
+	}
+
+	account, ok := c.resolveAccount(*accountFlag)
+	if !ok {
+		return exitUsage
+	}
+	if c.m.isConsolidatedView() {
+		c.failf("cli.readOnlyAccount")
+		return exitUsage
+	}
+	c.m.portfolio = loadPortfolio(account)
+	appended, err := c.m.addPortfolioBuy(code, stockData.Name, *cost, *quantity)
+	if err != nil {
+		c.failf("ledger.addFail", err)
+		return exitFailure
+	}
+	if err := c.m.savePortfolio(); err != nil {
+		c.failf("cli.saveFail", err)
+		return exitFailure
+	}
+	successKey := "addSuccess"
+	if appended {
+		successKey = "addLotSuccess"
+	}
+	fmt.Fprintf(c.stdout, c.m.getText(successKey)+"\n", stockData.Name, code)
+	return exitOK
+}
+
+func (c *cliCommand) runRemove(args []string) int {
+	fs := c.newFlagSet("remove")
+	fromWatchlist := fs.Bool("watchlist", false, "remove from watchlist")
+	accountFlag := fs.String("account", "", "account name")
+	codes, err := parseFlags(fs, args)
+	if err != nil {
+		return c.usageError(err)
+	}
+	if len(codes) != 1 {
+		c.failf("cli.codeRequired")
+		return exitUsage
+	}
+	code := strings.ToUpper(strings.TrimSpace(codes[0]))
+
+	if *fromWatchlist {
+		index := slices.IndexFunc(c.m.watchlist.Stocks, func(s WatchlistStock) bool { return strings.EqualFold(s.Code, code) })
+		if index < 0 {
+			c.failf("cli.notInWatchlist", code)
+			return exitFailure
+		}
+		removed := c.m.watchlist.Stocks[index]
+		if err := c.m.removeFromWatchlist(index); err != nil {
+			c.failf("cli.saveFail", err)
+			return exitFailure
+		}
+		fmt.Fprintf(c.stdout, c.m.getText("removeWatchSuccess")+"\n", removed.Name, removed.Code)
+		return exitOK
+	}
+
+	account, ok := c.resolveAccount(*accountFlag)
+	if !ok {
+		return exitUsage
+	}
+	if c.m.isConsolidatedView() {
+		c.failf("cli.readOnlyAccount")
+		return exitUsage
+	}
+	c.m.portfolio = loadPortfolio(account)
+	index := slices.IndexFunc(c.m.portfolio.Stocks, func(s Stock) bool { return strings.EqualFold(s.Code, code) })
+	if index < 0 {
+		c.failf("cli.notInPortfolio", code)
+		return exitFailure
+	}
+	removed := c.m.portfolio.Stocks[index]
+	c.m.portfolio.Stocks = slices.Delete(c.m.portfolio.Stocks, index, index+1)
+	if err := c.m.savePortfolio(); err != nil {
+		c.failf("cli.saveFail", err)
+		return exitFailure
+	}
+	fmt.Fprintf(c.stdout, c.m.getText("removeSuccess")+"\n", removed.Name, removed.Code)
+	return exitOK
+}
+
+// ============================================================================
+// export: 导出持仓、交易记录和自选列表（不获取行情）
+// ============================================================================
+
+// exportPosition 导出的持股（数量和成本价由交易记录推导）
+type exportPosition struct {
+	Code         string        `json:"code"`
+	Name         string        `json:"name"`
+	Quantity     int           `json:"quantity"`
+	CostPrice    float64       `json:"cost_price"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// exportAccount 导出的账户持仓
+type exportAccount struct {
+	Account   string           `json:"account"`
+	Positions []exportPosition `json:"positions"`
+}
+
+// exportData 导出的全部数据
+type exportData struct {
+	ExportedAt string           `json:"exported_at"`
+	Accounts   []exportAccount  `json:"accounts"`
+	Watchlist  []WatchlistStock `json:"watchlist"`
+}
+
+// exportCSVHeader CSV 导出的表头（record 为 position / transaction / watchlist，不适用的列留空）
+var exportCSVHeader = table.Row{"record", "account", "code", "name", "market", "tags", "quantity", "cost_price", "type", "date", "price", "fee", "amount", "ratio", "lot_method"}
+
+// runExport export 导出当前账户（--account all 导出所有账户）的持仓和交易记录以及自选列表，默认 JSON
+func (c *cliCommand) runExport(args []string) int {
+	fs := c.newFlagSet("export")
+	format := addFormatFlags(fs)
+	accountFlag := fs.String("account", "", "account name")
+	if _, err := parseFlags(fs, args); err != nil {
+		return c.usageError(err)
+	}
+	account, ok := c.resolveAccount(*accountFlag)
+	if !ok {
+		return exitUsage
+	}
+	accounts := []string{account}
+	if account == allAccountsName {
+		accounts = listAccounts()
+	}
+
+	data := exportData{
+		ExportedAt: time.Now().Format(time.RFC3339),
+		Accounts:   []exportAccount{},
+		Watchlist:  c.m.watchlist.Stocks,
+	}
+	if data.Watchlist == nil {
+		data.Watchlist = []WatchlistStock{}
+	}
+	for _, name := range accounts {
+		exported := exportAccount{Account: name, Positions: []exportPosition{}}
+		for _, stock := range loadPortfolio(name).Stocks {
+			transactions := stock.Transactions
+			if transactions == nil {
+				transactions = []Transaction{}
+			}
+			exported.Positions = append(exported.Positions, exportPosition{
+				Code:         stock.Code,
+				Name:         stock.Name,
+				Quantity:     stock.Quantity,
+				CostPrice:    stock.CostPrice,
+				Transactions: transactions,
+			})
+		}
+		data.Accounts = append(data.Accounts, exported)
+	}
+	logInfo("log.cli.export", account, len(data.Accounts), len(data.Watchlist))
+
+	if format() != cliFormatCSV {
+		return c.writeJSON(data)
+	}
+
+	var rows []table.Row
+	for _, exported := range data.Accounts {
+		for _, pos := range exported.Positions {
+			market := getMarketType(pos.Code)
+			rows = append(rows, table.Row{"position", exported.Account, pos.Code, pos.Name, market, "",
+				pos.Quantity, formatExportNumber(pos.CostPrice), "", "", "", "", "", "", ""})
+			for _, tx := range pos.Transactions {
+				rows = append(rows, table.Row{"transaction", exported.Account, pos.Code, pos.Name, market, "",
+					formatExportNumber(float64(tx.Quantity)), "", tx.Type, tx.Date, formatExportNumber(tx.Price),
+					formatExportNumber(tx.Fee), formatExportNumber(tx.Amount), formatExportNumber(tx.Ratio), tx.LotMethod})
+			}
+		}
+	}
+	for _, stock := range data.Watchlist {
+		rows = append(rows, table.Row{"watchlist", "", stock.Code, stock.Name, getMarketType(stock.Code), strings.Join(stock.Tags, ";"),
+			"", "", "", "", "", "", "", "", ""})
+	}
+	c.writeTable(cliFormatCSV, exportCSVHeader, exportCSVHeader, rows)
+	return exitOK
+}
+
+// formatExportNumber 导出数值（0 表示不适用，留空）
+func formatExportNumber(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// ============================================================================
+// symbols: 本地代码表
+// ============================================================================
+
+// runSymbols symbols refresh 更新本地代码表；symbols search 关键词 搜索股票（优先使用本地代码表）
+func (c *cliCommand) runSymbols(args []string) int {
+	fs := c.newFlagSet("symbols")
+	format := addFormatFlags(fs)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return c.usageError(err)
+	}
+	if len(positional) == 0 {
+		c.failf("cli.symbolsActionRequired")
+		return exitUsage
+	}
+
+	switch positional[0] {
+	case "refresh":
+		directory, err := refreshSymbolDirectory()
+		if err != nil {
+			c.failf("symbols.refreshFail", err)
+			return exitFailure
+		}
+		fmt.Fprintf(c.stdout, c.m.getText("symbols.refreshed")+"\n", len(directory.Symbols))
+		return exitOK
+	case "search":
+		keyword := strings.Join(positional[1:], " ")
+		if strings.TrimSpace(keyword) == "" {
+			c.failf("cli.keywordRequired")
+			return exitUsage
+		}
+		candidates := searchStockCandidates(keyword)
+		if format() == cliFormatJSON {
+			if candidates == nil {
+				candidates = []SearchCandidate{}
+			}
+			if code := c.writeJSON(candidates); code != exitOK {
+				return code
+			}
+		} else if len(candidates) > 0 {
+			rows := make([]table.Row, len(candidates))
+			for i, candidate := range candidates {
+				rows[i] = table.Row{candidate.Symbol, candidate.Name, candidate.EnglishName, c.m.getMarketTagName(candidate.Market), candidate.Exchange, candidate.Pinyin}
+			}
+			header := table.Row{c.m.getText("col.code"), c.m.getText("col.name"), c.m.getText("symbols.englishName"), c.m.getText("market"), c.m.getText("search.exchange"), c.m.getText("symbols.pinyin")}
+			c.writeTable(format(), header, table.Row{"code", "name", "english_name", "market", "exchange", "pinyin"}, rows)
+		}
+		if len(candidates) == 0 {
+			c.failf("searchNotFound", keyword)
+			return exitFailure
+		}
+		return exitOK
+	}
+	c.failf("cli.symbolsActionRequired")
+	return exitUsage
+}
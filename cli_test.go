@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// runCLIForTest 在临时数据目录中使用模拟行情执行子命令
+func runCLIForTest(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	if columnRegistry == nil {
+		initColumnRegistry()
+	}
+	var stdout, stderr bytes.Buffer
+	code := runCLI(args, getDefaultConfig(), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestParseFlagsInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("quote", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := addFormatFlags(fs)
+
+	args, err := parseFlags(fs, []string{"SH600000", "--json", "AAPL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(args, []string{"SH600000", "AAPL"}) || format() != cliFormatJSON {
+		t.Errorf("parseFlags = %v, format %s", args, format())
+	}
+}
+
+// TestCLIQuote 测试行情输出格式和获取失败时的退出码
+func TestCLIQuote(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	code, stdout, stderr := runCLIForTest(t, "quote", "sh600000", "AAPL", "--json")
+	if code != exitOK {
+		t.Fatalf("exit = %d, stderr = %s", code, stderr)
+	}
+	var quotes []StockData
+	if err := json.Unmarshal([]byte(stdout), &quotes); err != nil || len(quotes) != 2 {
+		t.Fatalf("JSON 输出无法解析: %v\n%s", err, stdout)
+	}
+	if quotes[1].Name != "Apple Inc." || quotes[1].Price <= 0 {
+		t.Errorf("AAPL 行情 = %+v", quotes[1])
+	}
+
+	code, stdout, stderr = runCLIForTest(t, "quote", "AAPL", "NOPE123", "--csv")
+	if code != exitFailure {
+		t.Errorf("部分获取失败时 exit = %d, expected %d", code, exitFailure)
+	}
+	if !strings.Contains(stderr, "NOPE123") {
+		t.Errorf("stderr 应包含失败的代码: %s", stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "code,name,price") || !strings.HasPrefix(lines[1], "AAPL,Apple Inc.,") {
+		t.Errorf("CSV 输出 = %q", stdout)
+	}
+	if strings.Contains(stdout, "\x1b[") {
+		t.Error("CSV 输出不应包含颜色")
+	}
+}
+
+// TestCLIPortfolioAddRemove 测试添加买入、输出持仓和删除
+func TestCLIPortfolioAddRemove(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	if code, _, stderr := runCLIForTest(t, "add", "SH600000", "--cost", "10", "--quantity", "100"); code != exitOK {
+		t.Fatalf("add exit = %d, stderr = %s", code, stderr)
+	}
+	if code, _, _ := runCLIForTest(t, "add", "SH600000"); code != exitUsage {
+		t.Errorf("缺少成本价和数量时 exit = %d, expected %d", code, exitUsage)
+	}
+
+	code, stdout, stderr := runCLIForTest(t, "portfolio", "--json")
+	if code != exitOK {
+		t.Fatalf("portfolio exit = %d, stderr = %s", code, stderr)
+	}
+	var result portfolioReport
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("JSON 输出无法解析: %v\n%s", err, stdout)
+	}
+	if len(result.Positions) != 1 || result.Positions[0].Quantity != 100 || result.Positions[0].Price <= 0 {
+		t.Fatalf("持仓 = %+v", result.Positions)
+	}
+	if result.Total.Currency != CurrencyCNY || !almostEqual(result.Total.MarketValue, result.Positions[0].MarketValue) {
+		t.Errorf("总计 = %+v", result.Total)
+	}
+
+	if code, _, _ := runCLIForTest(t, "portfolio", "--account", "missing"); code != exitUsage {
+		t.Errorf("账户不存在时 exit = %d, expected %d", code, exitUsage)
+	}
+	if code, _, _ := runCLIForTest(t, "remove", "sh600000"); code != exitOK {
+		t.Errorf("remove exit = %d", code)
+	}
+	if portfolio := loadPortfolio(defaultAccountName); len(portfolio.Stocks) != 0 {
+		t.Errorf("删除后持仓 = %+v", portfolio.Stocks)
+	}
+	if code, _, _ := runCLIForTest(t, "remove", "SH600000"); code != exitFailure {
+		t.Errorf("删除不存在的持仓 exit = %d, expected %d", code, exitFailure)
+	}
+}
+
+func TestCLIWatchlistTagFilter(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	runCLIForTest(t, "add", "AAPL", "--watchlist", "--tag", "科技")
+	runCLIForTest(t, "add", "SH600000", "--watchlist")
+
+	code, stdout, stderr := runCLIForTest(t, "watchlist", "--tag", "科技", "--json")
+	if code != exitOK {
+		t.Fatalf("watchlist exit = %d, stderr = %s", code, stderr)
+	}
+	var items []watchlistItemReport
+	if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+		t.Fatalf("JSON 输出无法解析: %v\n%s", err, stdout)
+	}
+	if len(items) != 1 || items[0].Code != "AAPL" || items[0].Quote == nil {
+		t.Errorf("按标签过滤 = %+v", items)
+	}
+}
+
+// TestCLISaveFail 测试数据保存失败时不输出成功信息并返回失败退出码
+func TestCLISaveFail(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+	// 数据文件位置被目录占用，写入必然失败
+	for _, path := range []string{dataFile, watchlistFile} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"add", "SH600000", "--cost", "10", "--quantity", "100"},
+		{"add", "SH600000", "--watchlist"},
+	} {
+		if code, stdout, stderr := runCLIForTest(t, args...); code != exitFailure || stdout != "" || !strings.Contains(stderr, "cli.saveFail") {
+			t.Errorf("%v: exit = %d, stdout = %q, stderr = %q", args, code, stdout, stderr)
+		}
+	}
+}
+
+// TestCLIExport 测试导出持仓、交易记录和自选列表
+func TestCLIExport(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	runCLIForTest(t, "add", "SH600000", "--cost", "10", "--quantity", "100")
+	runCLIForTest(t, "add", "AAPL", "--watchlist", "--tag", "科技")
+
+	code, stdout, stderr := runCLIForTest(t, "export")
+	if code != exitOK {
+		t.Fatalf("export exit = %d, stderr = %s", code, stderr)
+	}
+	var data exportData
+	if err := json.Unmarshal([]byte(stdout), &data); err != nil {
+		t.Fatalf("JSON 输出无法解析: %v\n%s", err, stdout)
+	}
+	if len(data.Accounts) != 1 || len(data.Accounts[0].Positions) != 1 {
+		t.Fatalf("accounts = %+v", data.Accounts)
+	}
+	pos := data.Accounts[0].Positions[0]
+	if pos.Code != "SH600000" || pos.Quantity != 100 || len(pos.Transactions) != 1 || pos.Transactions[0].Price != 10 {
+		t.Errorf("position = %+v", pos)
+	}
+	if len(data.Watchlist) != 1 || data.Watchlist[0].Code != "AAPL" {
+		t.Errorf("watchlist = %+v", data.Watchlist)
+	}
+
+	code, stdout, _ = runCLIForTest(t, "export", "--csv", "--account", "all")
+	if code != exitOK {
+		t.Fatalf("export --csv exit = %d", code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "record,account,code") ||
+		!strings.HasPrefix(lines[1], "position,default,SH600000") ||
+		!strings.HasPrefix(lines[2], "transaction,default,SH600000") ||
+		!strings.HasPrefix(lines[3], "watchlist,,AAPL") || !strings.Contains(lines[3], "科技") {
+		t.Errorf("CSV:\n%s", stdout)
+	}
+}
+
+func TestCLIUnknownCommand(t *testing.T) {
+	if code, _, stderr := runCLIForTest(t, "bogus"); code != exitUsage || stderr == "" {
+		t.Errorf("未知命令 exit = %d, stderr = %q", code, stderr)
+	}
+}
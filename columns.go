@@ -14,6 +14,7 @@ const (
 	ColCursor         ColumnID = "cursor"
 	ColCode           ColumnID = "code"
 	ColName           ColumnID = "name"
+	ColCurrency       ColumnID = "currency"
 	ColPrevClose      ColumnID = "prev_close"
 	ColOpen           ColumnID = "open"
 	ColHigh           ColumnID = "high"
@@ -23,6 +24,9 @@ const (
 	ColQuantity       ColumnID = "quantity"
 	ColTodayChange    ColumnID = "today_change"
 	ColPositionProfit ColumnID = "position_profit"
+	ColRealizedProfit ColumnID = "realized_profit"
+	ColNetProfit      ColumnID = "net_profit"
+	ColBreakEven      ColumnID = "break_even"
 	ColProfitRate     ColumnID = "profit_rate"
 	ColMarketValue    ColumnID = "market_value"
 
@@ -89,6 +93,12 @@ func makePortfolioColumnRegistry() map[ColumnID]*ColumnMetadata {
 			IsRequired: true,
 			SortField:  &sortByName,
 		},
+		ColCurrency: {
+			ID:         ColCurrency,
+			I18nKey:    "col.currency",
+			IsRequired: false,
+			SortField:  nil,
+		},
 		ColPrevClose: {
 			ID:         ColPrevClose,
 			I18nKey:    "col.prev_close",
@@ -143,6 +153,24 @@ func makePortfolioColumnRegistry() map[ColumnID]*ColumnMetadata {
 			IsRequired: false,
 			SortField:  &sortByTotalProfit,
 		},
+		ColRealizedProfit: {
+			ID:         ColRealizedProfit,
+			I18nKey:    "col.realized_profit",
+			IsRequired: false,
+			SortField:  nil,
+		},
+		ColNetProfit: {
+			ID:         ColNetProfit,
+			I18nKey:    "col.net_profit",
+			IsRequired: false,
+			SortField:  nil,
+		},
+		ColBreakEven: {
+			ID:         ColBreakEven,
+			I18nKey:    "col.break_even",
+			IsRequired: false,
+			SortField:  nil,
+		},
 		ColProfitRate: {
 			ID:         ColProfitRate,
 			I18nKey:    "col.profit_rate",
@@ -318,6 +346,8 @@ func (m *Model) GeneratePortfolioRow(stock *Stock, rowIndex, startIndex, endInde
 			row[i] = stock.Code
 		case ColName:
 			row[i] = stock.Name
+		case ColCurrency:
+			row[i] = currencyForStock(stock.Code)
 		case ColPrevClose:
 			row[i] = fmt.Sprintf("%.3f", stock.PrevClose)
 		case ColOpen:
@@ -327,7 +357,7 @@ func (m *Model) GeneratePortfolioRow(stock *Stock, rowIndex, startIndex, endInde
 		case ColLow:
 			row[i] = m.formatPriceWithColorLang(stock.MinPrice, stock.PrevClose)
 		case ColPrice:
-			row[i] = m.formatPriceWithColorLang(stock.Price, stock.PrevClose)
+			row[i] = m.formatPriceWithColorLang(stock.Price, stock.PrevClose) + m.staleQuoteMarker(stock.Code)
 		case ColCost:
 			row[i] = fmt.Sprintf("%.3f", stock.CostPrice)
 		case ColQuantity:
@@ -345,6 +375,20 @@ func (m *Model) GeneratePortfolioRow(stock *Stock, rowIndex, startIndex, endInde
 			} else {
 				row[i] = "-"
 			}
+		case ColRealizedProfit:
+			row[i] = m.formatProfitWithColorZeroLang(stock.CalculateRealizedProfit())
+		case ColNetProfit:
+			if stock.Price > 0 {
+				row[i] = m.formatProfitWithColorZeroLang(m.calculateNetProfit(stock))
+			} else {
+				row[i] = "-"
+			}
+		case ColBreakEven:
+			if breakEven := m.calculateBreakEvenPrice(stock); breakEven > 0 {
+				row[i] = fmt.Sprintf("%.3f", breakEven)
+			} else {
+				row[i] = "-"
+			}
 		case ColProfitRate:
 			if stock.Price > 0 && stock.CostPrice > 0 {
 				profitRate := ((stock.Price - stock.CostPrice) / stock.CostPrice) * 100
@@ -363,21 +407,27 @@ func (m *Model) GeneratePortfolioRow(stock *Stock, rowIndex, startIndex, endInde
 	return row
 }
 
-// GeneratePortfolioTotalRow - 生成Portfolio总计行
-func (m *Model) GeneratePortfolioTotalRow(totalProfit, totalProfitRate, totalMarketValue float64) table.Row {
+// GeneratePortfolioTotalRow - 生成Portfolio总计行（或市场小计行）
+func (m *Model) GeneratePortfolioTotalRow(totals PortfolioTotals) table.Row {
 	columns := m.GetPortfolioColumns()
 	row := make(table.Row, len(columns))
 
 	for i, col := range columns {
 		switch col.ID {
 		case ColName:
-			row[i] = m.getText("total")
+			row[i] = totals.Label
+		case ColCurrency:
+			row[i] = totals.Currency
 		case ColPositionProfit:
-			row[i] = m.formatProfitWithColorLang(totalProfit)
+			row[i] = m.formatProfitWithColorLang(totals.Profit())
+		case ColRealizedProfit:
+			row[i] = m.formatProfitWithColorZeroLang(totals.RealizedProfit)
+		case ColNetProfit:
+			row[i] = m.formatProfitWithColorLang(totals.NetProfit)
 		case ColProfitRate:
-			row[i] = m.formatProfitRateWithColorLang(totalProfitRate)
+			row[i] = m.formatProfitRateWithColorLang(totals.ProfitRate())
 		case ColMarketValue:
-			row[i] = fmt.Sprintf("%.2f", totalMarketValue)
+			row[i] = fmt.Sprintf("%.2f", totals.MarketValue)
 		default:
 			row[i] = ""
 		}
@@ -437,7 +487,7 @@ func (m *Model) GenerateWatchlistRow(watchStock *WatchlistStock, stockData *Stoc
 			row[i] = m.formatStockNameWithPortfolioHighlight(watchStock.Name, watchStock.Code)
 		case ColPrice:
 			if stockData != nil && stockData.Price > 0 && stockData.PrevClose > 0 {
-				row[i] = m.formatPriceWithColorLang(stockData.Price, stockData.PrevClose)
+				row[i] = m.formatPriceWithColorLang(stockData.Price, stockData.PrevClose) + m.staleQuoteMarker(watchStock.Code)
 			} else {
 				row[i] = "-"
 			}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	"github.com/NimbleMarkets/ntcharts/linechart"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// 多股票分时对比图（涨跌幅归一化）
+// ============================================================================
+
+// compareColors 对比图中每只股票的颜色（最多同时对比的股票数与颜色数相同）
+var compareColors = []string{"14", "11", "13", "12", "208", "10", "9", "15"}
+
+// comparisonSeries 对比图中的一只股票
+type comparisonSeries struct {
+	Stock WatchlistStock
+	Data  *IntradayData
+}
+
+// comparisonDataMsg 对比数据加载完成消息（Missing 为没有分时数据的股票名称）
+type comparisonDataMsg struct {
+	Series  []comparisonSeries
+	Missing []string
+}
+
+// enterCompareSelecting 从自选列表进入对比股票选择：有标签过滤时默认选中该标签下的全部股票，否则选中光标所在股票
+func (m *Model) enterCompareSelecting() {
+	m.compareSelected = make(map[string]bool)
+	filteredStocks := m.getFilteredWatchlist()
+	if m.selectedTag != "" {
+		for _, stock := range filteredStocks {
+			m.compareSelected[stock.Code] = true
+		}
+	} else if m.watchlistCursor >= 0 && m.watchlistCursor < len(filteredStocks) {
+		m.compareSelected[filteredStocks[m.watchlistCursor].Code] = true
+	}
+	m.compareCursor = 0
+	m.compareTag = m.selectedTag
+	m.state = CompareSelecting
+	m.message = ""
+}
+
+// compareTags 可用于批量选择的标签（市场标签和用户标签，顺序与分组查看一致）
+func (m *Model) compareTags() []string {
+	var tags []string
+	for _, group := range m.getTagGroups() {
+		tags = append(tags, group.Tags...)
+	}
+	return tags
+}
+
+// stockMatchesTag 股票是否属于标签（市场标签或用户标签），与 getFilteredWatchlist 的规则一致
+func (m *Model) stockMatchesTag(stock WatchlistStock, tag string) bool {
+	return m.getMarketTagName(stock.Market) == tag || stock.hasTag(tag)
+}
+
+// selectedCompareStocks 已选中的股票（按自选列表顺序）
+func (m *Model) selectedCompareStocks() []WatchlistStock {
+	var stocks []WatchlistStock
+	for _, stock := range m.watchlist.Stocks {
+		if m.compareSelected[stock.Code] {
+			stocks = append(stocks, stock)
+		}
+	}
+	return stocks
+}
+
+func (m *Model) handleCompareSelecting(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	stocks := m.watchlist.Stocks
+	switch msg.String() {
+	case "esc", "q":
+		m.state = WatchlistViewing
+		m.message = ""
+		return m, m.tickCmd()
+	case "up", "k", "w":
+		if m.compareCursor > 0 {
+			m.compareCursor--
+		}
+	case "down", "j", "s":
+		if m.compareCursor < len(stocks)-1 {
+			m.compareCursor++
+		}
+	case " ":
+		if m.compareCursor < len(stocks) {
+			code := stocks[m.compareCursor].Code
+			m.compareSelected[code] = !m.compareSelected[code]
+		}
+		m.message = ""
+	case "t":
+		// 依次切换标签，选中该标签下的全部股票
+		tags := m.compareTags()
+		if len(tags) == 0 {
+			m.message = m.getText("watchlist.noTags")
+			return m, nil
+		}
+		next := 0
+		for i, tag := range tags {
+			if tag == m.compareTag {
+				next = (i + 1) % len(tags)
+				break
+			}
+		}
+		m.compareTag = tags[next]
+		m.compareSelected = make(map[string]bool)
+		for _, stock := range stocks {
+			if m.stockMatchesTag(stock, m.compareTag) {
+				m.compareSelected[stock.Code] = true
+			}
+		}
+		m.message = ""
+	case "c":
+		m.compareSelected = make(map[string]bool)
+		m.compareTag = ""
+		m.message = ""
+	case "enter":
+		selected := m.selectedCompareStocks()
+		switch {
+		case len(selected) < 2:
+			m.message = m.getText("compare.needTwo")
+		case len(selected) > len(compareColors):
+			m.message = fmt.Sprintf(m.getText("compare.tooMany"), len(compareColors))
+		default:
+			logInfo("log.action.enterCompare", len(selected))
+			m.state = CompareViewing
+			m.compareSeries = nil
+			m.compareMissing = nil
+			m.compareLoading = true
+			m.message = ""
+			return m, m.loadComparisonCmd(selected)
+		}
+	}
+	return m, nil
+}
+
+// loadComparisonCmd 并发加载各股票的分时数据：每只股票按自己市场的交易日取本地数据，没有时从接口临时获取（不保存）
+func (m *Model) loadComparisonCmd(stocks []WatchlistStock) tea.Cmd {
+	return func() tea.Msg {
+		results := make([]*IntradayData, len(stocks))
+		var wg sync.WaitGroup
+		for i, stock := range stocks {
+			wg.Go(func() {
+				results[i] = m.loadComparisonData(stock)
+			})
+		}
+		wg.Wait()
+
+		var msg comparisonDataMsg
+		for i, stock := range stocks {
+			if results[i] == nil {
+				msg.Missing = append(msg.Missing, stock.Name)
+				continue
+			}
+			msg.Series = append(msg.Series, comparisonSeries{Stock: stock, Data: results[i]})
+		}
+		return msg
+	}
+}
+
+// loadComparisonData 加载单只股票用于对比的分时数据，失败时返回 nil
+func (m *Model) loadComparisonData(stock WatchlistStock) *IntradayData {
+	date, _, err := GetTradingDayForCollection(stock.Code, m)
+	if err != nil {
+		date = getSmartChartDate(getMarketType(stock.Code))
+	}
+	if data, err := m.loadIntradayDataForDate(stock.Code, stock.Name, date); err == nil {
+		return data
+	}
+
+	datapoints, err := fetchIntradayDataFromAPI(stock.Code)
+	if err != nil || len(datapoints) == 0 {
+		logWarn("log.compare.loadFail", stock.Code, err)
+		return nil
+	}
+	return &IntradayData{
+		Code:       stock.Code,
+		Name:       stock.Name,
+		Date:       date,
+		Market:     getMarketType(stock.Code),
+		Datapoints: datapoints,
+		PrevClose:  m.fetchPrevCloseForStock(stock.Code),
+	}
+}
+
+// handleComparisonData 应用加载结果（已离开对比图时忽略）
+func (m *Model) handleComparisonData(msg comparisonDataMsg) {
+	if m.state != CompareViewing {
+		return
+	}
+	m.compareLoading = false
+	m.compareSeries = msg.Series
+	m.compareMissing = msg.Missing
+}
+
+func (m *Model) handleCompareViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		// 返回选择界面，可调整对比的股票
+		m.state = CompareSelecting
+		m.compareSeries = nil
+		m.compareLoading = false
+	case "r":
+		if !m.compareLoading {
+			m.compareLoading = true
+			return m, m.loadComparisonCmd(m.selectedCompareStocks())
+		}
+	}
+	return m, nil
+}
+
+// comparisonBase 涨跌幅的基准价：昨收，不可用时降级为第一笔价格（与分时图一致）
+func comparisonBase(data *IntradayData) float64 {
+	if data.PrevClose > 0 {
+		return data.PrevClose
+	}
+	return data.Datapoints[0].Price
+}
+
+// comparisonPoints 将分时数据换算为 (交易时段进度 0-1, 相对基准的涨跌幅%) 序列
+// 横坐标按该股票所在市场自己的交易时段计算，不同市场的开盘和收盘对齐；缺失分钟沿用上一价格，最后一笔数据之后不再绘制
+func (m *Model) comparisonPoints(data *IntradayData) []canvas.Float64Point {
+	framework := m.createFixedTimeRange(data.Date, data.Market)
+	if len(framework) < 2 || len(data.Datapoints) == 0 {
+		return nil
+	}
+	series := intradayIndicatorSeries(data, framework)
+	base := comparisonBase(data)
+
+	var points []canvas.Float64Point
+	for i, price := range series.Close {
+		if math.IsNaN(price) {
+			continue
+		}
+		points = append(points, canvas.Float64Point{
+			X: float64(i) / float64(len(framework)-1),
+			Y: (price - base) / base * 100,
+		})
+	}
+	return points
+}
+
+// createComparisonChart 绘制对比图：所有股票共用横轴（交易时段进度）和纵轴（涨跌幅%），0% 处画参考线
+func (m *Model) createComparisonChart(series []comparisonSeries, width, height int) *linechart.Model {
+	allPoints := make([][]canvas.Float64Point, len(series))
+	low, high := 0.0, 0.0
+	for i, s := range series {
+		allPoints[i] = m.comparisonPoints(s.Data)
+		for _, p := range allPoints[i] {
+			low, high = math.Min(low, p.Y), math.Max(high, p.Y)
+		}
+	}
+	margin := math.Max((high-low)*0.1, 0.1)
+
+	// 同一市场时横轴显示时间，跨市场时不显示刻度（由 comparisonSessionAxis 标注开盘和收盘）
+	var framework []TimePoint
+	if sameMarket(series) {
+		framework = m.createFixedTimeRange(series[0].Data.Date, series[0].Data.Market)
+	}
+	xLabelFormatter := func(index int, value float64) string {
+		if len(framework) < 2 {
+			return ""
+		}
+		i := min(max(int(math.Round(value*float64(len(framework)-1))), 0), len(framework)-1)
+		return framework[i].Time.Format("15:04")
+	}
+	yLabelFormatter := func(index int, value float64) string {
+		return fmt.Sprintf("%+.2f%%", value)
+	}
+
+	lc := linechart.New(width, height,
+		0, 1,
+		low-margin, high+margin,
+		linechart.WithXYSteps(4, 5),
+		linechart.WithXLabelFormatter(xLabelFormatter),
+		linechart.WithYLabelFormatter(yLabelFormatter),
+	)
+
+	zeroStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	lc.DrawBrailleLineWithStyle(canvas.Float64Point{X: 0, Y: 0}, canvas.Float64Point{X: 1, Y: 0}, zeroStyle)
+	for i, points := range allPoints {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(compareColors[i%len(compareColors)]))
+		for j := 0; j < len(points)-1; j++ {
+			lc.DrawBrailleLineWithStyle(points[j], points[j+1], style)
+		}
+	}
+	lc.DrawXYAxisAndLabel()
+	return &lc
+}
+
+// sameMarket 对比的股票是否都属于同一市场
+func sameMarket(series []comparisonSeries) bool {
+	for _, s := range series {
+		if s.Data.Market != series[0].Data.Market {
+			return false
+		}
+	}
+	return true
+}
+
+// comparisonSessionAxis 跨市场对比时的横轴说明：绘图区左端为各市场开盘，右端为收盘
+func (m *Model) comparisonSessionAxis(chart *linechart.Model) string {
+	open, closeLabel := m.getText("compare.open"), m.getText("compare.close")
+	gap := max(chart.GraphWidth()-lipgloss.Width(open)-lipgloss.Width(closeLabel), 1)
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).
+		Render(strings.Repeat(" ", chart.Origin().X+1) + open + strings.Repeat(" ", gap) + closeLabel)
+}
+
+func (m *Model) viewCompareSelecting() string {
+	s := m.getText("compare.selectTitle") + "\n\n"
+	if m.compareTag != "" {
+		s += fmt.Sprintf("%s: %s\n\n", m.getText("compare.tag"), m.compareTag)
+	}
+
+	if len(m.watchlist.Stocks) == 0 {
+		s += m.getText("emptyWatchlist") + "\n"
+	}
+	for i, stock := range m.watchlist.Stocks {
+		prefix := "  "
+		if i == m.compareCursor {
+			prefix = "► "
+		}
+		check := "[ ]"
+		if m.compareSelected[stock.Code] {
+			check = "[✓]"
+		}
+		s += fmt.Sprintf("%s%s %s (%s)  %s\n", prefix, check, stock.Name, stock.Code, stock.getTagsDisplay(m))
+	}
+
+	s += "\n" + fmt.Sprintf(m.getText("compare.selectedCount"), len(m.selectedCompareStocks()), len(compareColors)) + "\n"
+	s += "\n" + m.getText("compare.selectHelp") + "\n"
+	if m.message != "" {
+		s += "\n" + m.message + "\n"
+	}
+	return s
+}
+
+func (m *Model) viewCompareViewing(termWidth, termHeight int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("14")). // 青色
+		Render("📊 " + m.getText("compare.title")))
+	b.WriteString("\n\n")
+
+	help := lipgloss.NewStyle().Faint(true).Render(m.getText("compare.help"))
+	if m.compareLoading {
+		b.WriteString(m.getText("compare.loading") + "\n\n" + help)
+		return b.String()
+	}
+	if len(m.compareSeries) == 0 {
+		b.WriteString(m.getText("compare.noData") + "\n\n" + help)
+		return b.String()
+	}
+
+	// 图例：颜色、名称、市场和日期、最新涨跌幅
+	for i, s := range m.compareSeries {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(compareColors[i%len(compareColors)]))
+		last := s.Data.Datapoints[len(s.Data.Datapoints)-1].Price
+		base := comparisonBase(s.Data)
+		b.WriteString(fmt.Sprintf("%s %s (%s)  %s %s  %s\n",
+			style.Render("━━"), s.Stock.Name, s.Stock.Code,
+			m.getMarketTagName(s.Data.Market), formatDate(s.Data.Date),
+			style.Render(fmt.Sprintf("%+.2f%%", (last-base)/base*100))))
+	}
+	if len(m.compareMissing) > 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).
+			Render(fmt.Sprintf(m.getText("compare.missing"), strings.Join(m.compareMissing, ", "))) + "\n")
+	}
+	b.WriteString("\n")
+
+	chartHeight := max(termHeight-8-len(m.compareSeries), 10)
+	chart := m.createComparisonChart(m.compareSeries, max(termWidth-4, 40), chartHeight)
+	b.WriteString(chart.View())
+	if !sameMarket(m.compareSeries) {
+		b.WriteString("\n" + m.comparisonSessionAxis(chart))
+	}
+	b.WriteString("\n\n" + help)
+	return b.String()
+}
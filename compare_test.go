@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestCompareSelectAndView 测试按标签选择股票、加载数据并渲染对比图
+func TestCompareSelectAndView(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	m := &Model{config: getDefaultConfig(), language: English, state: WatchlistViewing}
+	m.watchlist.Stocks = []WatchlistStock{
+		{Code: "SH600000", Name: "浦发银行", Market: MarketChina, Tags: []string{"bank"}},
+		{Code: "SZ000001", Name: "平安银行", Market: MarketChina, Tags: []string{"bank"}},
+		{Code: "AAPL", Name: "Apple", Market: MarketUS},
+	}
+
+	m.handleWatchlistViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	if m.state != CompareSelecting || len(m.selectedCompareStocks()) != 1 {
+		t.Fatalf("state = %v, selected = %d", m.state, len(m.selectedCompareStocks()))
+	}
+
+	// 只选一只时不能对比
+	m.handleCompareSelecting(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != CompareSelecting || m.message != m.getText("compare.needTwo") {
+		t.Errorf("message = %q", m.message)
+	}
+
+	// T 键依次切换标签：市场标签在前，用户标签在后
+	for range len(m.compareTags()) {
+		m.handleCompareSelecting(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+		if m.compareTag == "bank" {
+			break
+		}
+	}
+	selected := m.selectedCompareStocks()
+	if m.compareTag != "bank" || len(selected) != 2 || selected[1].Code != "SZ000001" {
+		t.Fatalf("tag = %q, selected = %+v", m.compareTag, selected)
+	}
+
+	// 加上美股：各自按本市场交易时段对齐到 0-1
+	m.compareCursor = 2
+	m.handleCompareSelecting(tea.KeyMsg{Type: tea.KeySpace})
+	_, cmd := m.handleCompareSelecting(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != CompareViewing || cmd == nil {
+		t.Fatalf("state = %v", m.state)
+	}
+	m.Update(cmd())
+	if m.compareLoading || len(m.compareSeries) != 3 || len(m.compareMissing) != 0 {
+		t.Fatalf("series = %d, missing = %v", len(m.compareSeries), m.compareMissing)
+	}
+
+	for _, s := range m.compareSeries {
+		points := m.comparisonPoints(s.Data)
+		if len(points) < 100 || points[0].X < 0 || points[len(points)-1].X > 1 {
+			t.Errorf("%s: %d 个点, x = %.3f..%.3f", s.Stock.Code, len(points), points[0].X, points[len(points)-1].X)
+			continue
+		}
+		last := s.Data.Datapoints[len(s.Data.Datapoints)-1].Price
+		if want := (last - comparisonBase(s.Data)) / comparisonBase(s.Data) * 100; !almostEqual(points[len(points)-1].Y, want) {
+			t.Errorf("%s: 最新涨跌幅 = %.4f, expected %.4f", s.Stock.Code, points[len(points)-1].Y, want)
+		}
+	}
+
+	view := m.viewCompareViewing(120, 30)
+	for _, want := range []string{"浦发银行", "平安银行", "Apple", m.getText("compare.close")} {
+		if !strings.Contains(view, want) {
+			t.Errorf("对比图缺少 %q:\n%s", want, view)
+		}
+	}
+
+	// ESC 返回选择界面并保留选择
+	m.handleCompareViewing(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != CompareSelecting || len(m.selectedCompareStocks()) != 3 {
+		t.Errorf("state = %v, selected = %d", m.state, len(m.selectedCompareStocks()))
+	}
+}
@@ -5,9 +5,15 @@ import "time"
 // 文件路径常量
 const (
 	dataFile        = "data/portfolio.json"
+	portfoliosDir   = "data/portfolios" // 其他账户的持仓文件目录
+	snapshotsDir    = "data/snapshots"  // 每日持仓快照目录（每个账户一个文件）
 	watchlistFile   = "data/watchlist.json"
 	configFile      = "cmd/conf/config.yml"
-	refreshInterval = 5 * time.Second
+	fxRatesFile     = "data/fx_rates.json"
+	alertsFile      = "data/alerts.json" // 价格提醒规则
+	quoteCacheFile  = "data/quotes.json" // 上次获取的行情（启动时先显示）
+	refreshInterval = 5 * time.Second    // 默认行情刷新间隔（未配置 update.refresh_interval 时）
+	fxRetryInterval = time.Minute        // 汇率获取失败后的重试间隔
 )
 
 // 语言常量
@@ -41,6 +47,16 @@ const (
 	PortfolioSorting         // 持股列表排序状态
 	WatchlistSorting         // 自选列表排序状态
 	IntradayChartViewing     // 分时图表查看状态
+	TransactionViewing       // 持仓交易记录查看状态
+	SellingStock             // 卖出持仓状态
+	AccountSwitching         // 账户切换状态
+	EquityCurveViewing       // 持仓净值曲线查看状态
+	AlertManaging            // 价格提醒规则管理状态
+	KLineViewing             // 日K线图查看状态
+	CompareSelecting         // 多股票对比选择状态
+	CompareViewing           // 多股票分时对比图查看状态
+	SearchPicking            // 搜索结果候选列表选择状态
+	SettingsEditing          // 刷新设置状态
 )
 
 // 排序字段枚举
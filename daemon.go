@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ============================================================================
+// daemon: 后台分时数据采集（不依赖界面，跨交易时段持续运行）
+// ============================================================================
+
+const (
+	daemonCheckInterval     = time.Minute      // 检查交易状态和股票列表的间隔
+	intradayRestartInterval = 10 * time.Minute // worker 自动停止后重新检查的间隔（避免频繁启动）
+)
+
+// intradaySupervisor 按交易时段维护分时采集 worker（daemon 和 serve 共用）
+// 交易状态变化时重启 worker：盘前采集上一交易日，开盘后切换为当日实时采集，收盘后补全当日数据
+type intradaySupervisor struct {
+	im      *IntradayManager
+	states  map[string]TradingState // 上次检查时各股票所在市场的交易状态
+	started map[string]time.Time    // 最近一次启动采集的时间
+}
+
+func newIntradaySupervisor(im *IntradayManager) *intradaySupervisor {
+	return &intradaySupervisor{
+		im:      im,
+		states:  make(map[string]TradingState),
+		started: make(map[string]time.Time),
+	}
+}
+
+// marketTradingState 股票所在市场在 now 时刻的交易状态
+func marketTradingState(code string, now time.Time) (TradingState, bool) {
+	marketType := getMarketType(code)
+	location, err := getMarketLocation(marketType)
+	if err != nil {
+		return TradingStatePostMarket, false
+	}
+	return getTradingState(now.In(location), marketType), true
+}
+
+// sync 使 worker 与股票列表（代码 → 名称）和当前交易状态保持一致
+func (s *intradaySupervisor) sync(stocks map[string]string, now time.Time) {
+	for code, name := range stocks {
+		state, ok := marketTradingState(code, now)
+		if !ok {
+			continue
+		}
+		if previous, seen := s.states[code]; seen && previous != state {
+			logInfo("log.daemon.sessionChange", code, previous, state)
+			s.im.StopCollection(code)
+			delete(s.started, code)
+		}
+		s.states[code] = state
+
+		if s.im.IsCollecting(code) || now.Sub(s.started[code]) < intradayRestartInterval {
+			continue
+		}
+		s.started[code] = now
+		if err := s.im.StartCollection(code, name); err != nil {
+			logWarn("log.daemon.startFail", code, err)
+		}
+	}
+
+	// 已从持仓和自选列表删除的股票停止采集
+	for code := range s.states {
+		if _, tracked := stocks[code]; !tracked {
+			s.im.StopCollection(code)
+			delete(s.states, code)
+			delete(s.started, code)
+		}
+	}
+}
+
+// collectionStocks 所有账户持仓和自选列表中的股票（代码 → 名称）
+func collectionStocks() map[string]string {
+	stocks := make(map[string]string)
+	for _, stock := range loadAccountPortfolio(allAccountsName).Stocks {
+		stocks[stock.Code] = stock.Name
+	}
+	for _, stock := range loadWatchlist().Stocks {
+		stocks[stock.Code] = stock.Name
+	}
+	return stocks
+}
+
+func (c *cliCommand) runDaemon(args []string) int {
+	fs := c.newFlagSet("daemon")
+	if _, err := parseFlags(fs, args); err != nil {
+		return c.usageError(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c.m.intradayManager = newIntradayManager(c.m)
+	supervisor := newIntradaySupervisor(c.m.intradayManager)
+	fmt.Fprintln(c.stderr, c.m.getText("daemon.started"))
+	logInfo("log.daemon.started")
+
+	ticker := time.NewTicker(daemonCheckInterval)
+	defer ticker.Stop()
+	tracked := -1
+	for {
+		// 每次检查时重新加载列表，界面或命令行的修改随之生效
+		stocks := collectionStocks()
+		if len(stocks) != tracked {
+			tracked = len(stocks)
+			logInfo("log.daemon.tracking", tracked)
+		}
+		supervisor.sync(stocks, time.Now())
+
+		select {
+		case <-ctx.Done():
+			close(c.m.intradayManager.cancelChan)
+			logInfo("log.daemon.stopped")
+			return exitOK
+		case <-ticker.C:
+		}
+	}
+}
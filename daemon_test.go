@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestIntradaySupervisorSessions 测试交易状态变化时重启 worker，删除股票后停止采集
+func TestIntradaySupervisorSessions(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	im := newIntradayManager(&Model{config: getDefaultConfig()})
+	t.Cleanup(func() { close(im.cancelChan) })
+	supervisor := newIntradaySupervisor(im)
+	stocks := map[string]string{"SH600000": "浦发银行"}
+
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	preMarket := time.Date(2025, 3, 14, 9, 0, 0, 0, shanghai)
+	supervisor.sync(stocks, preMarket)
+	if !im.IsCollecting("SH600000") || supervisor.states["SH600000"] != TradingStatePreMarket {
+		t.Fatalf("盘前应启动采集, state = %v", supervisor.states["SH600000"])
+	}
+
+	// 同一时段内不重复启动
+	supervisor.sync(stocks, preMarket.Add(time.Minute))
+	if !supervisor.started["SH600000"].Equal(preMarket) {
+		t.Errorf("同一时段不应重新启动: %v", supervisor.started["SH600000"])
+	}
+
+	// 开盘后重启 worker（先停止盘前 worker 并清除其数据：实际时间在收盘后时当天数据已完整，不会再启动 worker）
+	im.StopCollection("SH600000")
+	if err := os.RemoveAll(filepath.Join("data", "intraday")); err != nil {
+		t.Fatal(err)
+	}
+	open := time.Date(2025, 3, 14, 9, 31, 0, 0, shanghai)
+	supervisor.sync(stocks, open)
+	if !im.IsCollecting("SH600000") || !supervisor.started["SH600000"].Equal(open) {
+		t.Errorf("开盘后应重启采集, started = %v", supervisor.started["SH600000"])
+	}
+
+	// 删除股票后停止
+	supervisor.sync(map[string]string{}, open.Add(time.Minute))
+	if im.IsCollecting("SH600000") || len(supervisor.states) != 0 {
+		t.Error("股票删除后应停止采集")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// ============================================================================
+// 数据源接口地址
+// ============================================================================
+
+// mockEnvVar 设置为 1/true 时启用内置模拟行情服务器（优先于配置文件）
+const mockEnvVar = "STOCK_MONITOR_MOCK"
+
+// apiEndpoints 当前生效的数据源接口地址（所有 HTTP 请求都基于这里的地址构造）
+var apiEndpoints = defaultEndpointsConfig()
+
+// defaultEndpointsConfig 获取官方数据源地址
+func defaultEndpointsConfig() EndpointsConfig {
+	return EndpointsConfig{
+		TencentQuote:     "https://qt.gtimg.cn",
+		TencentSearch:    "https://smartbox.gtimg.cn",
+		TencentMinute:    "http://ifzq.gtimg.cn",
+		SinaSearch:       "https://suggest3.sinajs.cn",
+		SinaIntraday:     "http://money.finance.sina.com.cn",
+		EastMoney:        "https://push2.eastmoney.com",
+		EastMoneyHistory: "https://push2his.eastmoney.com",
+		Yahoo:            "https://query1.finance.yahoo.com",
+		TwelveData:       "https://api.twelvedata.com",
+		FMP:              "https://financialmodelingprep.com",
+		Nasdaq:           "https://api.nasdaq.com",
+	}
+}
+
+// initAPIEndpoints 应用配置中的接口地址，未配置的项使用官方地址
+func initAPIEndpoints(config EndpointsConfig) {
+	defaults := defaultEndpointsConfig()
+	apiEndpoints = EndpointsConfig{
+		Mock:             config.Mock,
+		TencentQuote:     endpointOrDefault(config.TencentQuote, defaults.TencentQuote),
+		TencentSearch:    endpointOrDefault(config.TencentSearch, defaults.TencentSearch),
+		TencentMinute:    endpointOrDefault(config.TencentMinute, defaults.TencentMinute),
+		SinaSearch:       endpointOrDefault(config.SinaSearch, defaults.SinaSearch),
+		SinaIntraday:     endpointOrDefault(config.SinaIntraday, defaults.SinaIntraday),
+		EastMoney:        endpointOrDefault(config.EastMoney, defaults.EastMoney),
+		EastMoneyHistory: endpointOrDefault(config.EastMoneyHistory, defaults.EastMoneyHistory),
+		Yahoo:            endpointOrDefault(config.Yahoo, defaults.Yahoo),
+		TwelveData:       endpointOrDefault(config.TwelveData, defaults.TwelveData),
+		FMP:              endpointOrDefault(config.FMP, defaults.FMP),
+		Nasdaq:           endpointOrDefault(config.Nasdaq, defaults.Nasdaq),
+	}
+}
+
+// endpointOrDefault 去除末尾的 / 并在为空时返回默认地址
+func endpointOrDefault(value, defaultValue string) string {
+	value = strings.TrimRight(strings.TrimSpace(value), "/")
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// isMockModeEnabled 判断是否启用模拟行情服务器（环境变量优先）
+func isMockModeEnabled(config EndpointsConfig) bool {
+	switch strings.ToLower(os.Getenv(mockEnvVar)) {
+	case "1", "true", "yes":
+		return true
+	case "0", "false", "no":
+		return false
+	}
+	return config.Mock
+}
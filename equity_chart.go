@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/canvas"
+	"github.com/NimbleMarkets/ntcharts/linechart"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// 持仓净值曲线
+// ============================================================================
+
+// equityRange 净值曲线日期范围
+type equityRange struct {
+	Label string // 显示标签
+	Days  int    // 日历天数（0 表示全部）
+}
+
+// equityRanges 可切换的日期范围
+var equityRanges = []equityRange{
+	{"1M", 30},
+	{"3M", 90},
+	{"6M", 180},
+	{"1Y", 365},
+	{"ALL", 0},
+}
+
+// enterEquityCurveViewing 打开当前账户的净值曲线（默认最近三个月）
+func (m *Model) enterEquityCurveViewing() {
+	logInfo("log.action.enterEquityCurve")
+	m.previousState = m.state
+	m.state = EquityCurveViewing
+	m.equitySnapshots = loadSnapshots(m.currentAccount())
+	m.equityRange = 1
+	m.equityOffset = 0
+	m.message = ""
+}
+
+// visibleEquitySnapshots 获取当前日期窗口内的快照及窗口起止日期
+// 窗口结束日期 = 最新快照日期向前平移 equityOffset 个半窗口
+func (m *Model) visibleEquitySnapshots() ([]PortfolioSnapshot, string, string) {
+	if len(m.equitySnapshots) == 0 {
+		return nil, "", ""
+	}
+	first := m.equitySnapshots[0].Date
+	last := m.equitySnapshots[len(m.equitySnapshots)-1].Date
+
+	days := equityRanges[m.equityRange].Days
+	if days == 0 {
+		return m.equitySnapshots, first, last
+	}
+
+	lastDate, err := time.Parse("2006-01-02", last)
+	if err != nil {
+		return m.equitySnapshots, first, last
+	}
+	endDate := lastDate.AddDate(0, 0, -m.equityOffset*days/2)
+	end := endDate.Format("2006-01-02")
+	start := endDate.AddDate(0, 0, -days).Format("2006-01-02")
+
+	var visible []PortfolioSnapshot
+	for _, snapshot := range m.equitySnapshots {
+		if snapshot.Date > start && snapshot.Date <= end {
+			visible = append(visible, snapshot)
+		}
+	}
+	return visible, start, end
+}
+
+func (m *Model) handleEquityCurveViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.state = m.previousState
+		m.equitySnapshots = nil
+		m.message = ""
+		if m.state == Monitoring {
+			m.lastUpdate = time.Now()
+			return m, m.tickCmd()
+		}
+	case "up", "k", "w":
+		// 缩小日期范围
+		if m.equityRange > 0 {
+			m.equityRange--
+			m.equityOffset = 0
+		}
+	case "down", "j", "s":
+		// 扩大日期范围
+		if m.equityRange < len(equityRanges)-1 {
+			m.equityRange++
+			m.equityOffset = 0
+		}
+	case "left":
+		// 向更早的日期平移半个窗口（窗口起点早于第一个快照时停止）
+		if _, start, _ := m.visibleEquitySnapshots(); equityRanges[m.equityRange].Days > 0 &&
+			len(m.equitySnapshots) > 0 && start > m.equitySnapshots[0].Date {
+			m.equityOffset++
+		}
+	case "right":
+		if m.equityOffset > 0 {
+			m.equityOffset--
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) viewEquityCurveViewing(termWidth, termHeight int) string {
+	var b strings.Builder
+
+	snapshots, start, end := m.visibleEquitySnapshots()
+	rangeLabel := equityRanges[m.equityRange].Label
+
+	b.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("14")). // 青色
+		Render(fmt.Sprintf("📈 %s - %s [%s]", m.getText("equity.title"), m.accountDisplayName(m.currentAccount()), rangeLabel)))
+	b.WriteString("\n\n")
+
+	help := lipgloss.NewStyle().Faint(true).Render(m.getText("equity.help"))
+
+	if len(m.equitySnapshots) == 0 {
+		b.WriteString(m.getText("equity.empty") + "\n\n" + help)
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf(m.getText("equity.window"), start, end) + "\n")
+	if len(snapshots) == 0 {
+		b.WriteString("\n" + m.getText("equity.noDataInRange") + "\n\n" + help)
+		return b.String()
+	}
+
+	// 区间统计（基准货币）
+	firstSnapshot, lastSnapshot := snapshots[0], snapshots[len(snapshots)-1]
+	change := lastSnapshot.TotalValue - firstSnapshot.TotalValue
+	changePercent := 0.0
+	if firstSnapshot.TotalValue > 0 {
+		changePercent = change / firstSnapshot.TotalValue * 100
+	}
+	b.WriteString(fmt.Sprintf(m.getText("equity.stats"),
+		lastSnapshot.Currency, lastSnapshot.TotalValue, lastSnapshot.CostBasis,
+		m.formatProfitWithColorLang(lastSnapshot.TotalValue-lastSnapshot.CostBasis),
+		m.formatProfitWithColorLang(lastSnapshot.DailyProfit),
+		m.formatProfitWithColorLang(change), m.formatProfitRateWithColorLang(changePercent)) + "\n\n")
+
+	if chart := m.createEquityChart(snapshots, termWidth, termHeight); chart != nil {
+		b.WriteString(chart.View() + "\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(m.getText("equity.legend")) + "\n")
+	} else if len(snapshots) < 2 {
+		b.WriteString(m.getText("equity.needMoreData") + "\n")
+	} else {
+		b.WriteString(m.getText("terminalTooSmall") + "\n")
+	}
+
+	b.WriteString("\n" + help)
+	return b.String()
+}
+
+// createEquityChart 绘制净值曲线（市值）和持仓成本曲线
+func (m *Model) createEquityChart(snapshots []PortfolioSnapshot, termWidth, termHeight int) *linechart.Model {
+	if len(snapshots) < 2 {
+		return nil
+	}
+
+	minWidth, minHeight := 40, 10
+	chartWidth := max(termWidth-4, minWidth)
+	chartHeight := max(termHeight-14, minHeight)
+
+	values := make([]float64, 0, len(snapshots)*2)
+	for _, snapshot := range snapshots {
+		values = append(values, snapshot.TotalValue, snapshot.CostBasis)
+	}
+	minValue, maxValue, margin := calculateAdaptiveMargin(values)
+
+	// 颜色：中文红涨绿跌，英文绿涨红跌（与盈亏显示一致）
+	upColor, downColor := lipgloss.Color("9"), lipgloss.Color("10")
+	if m.language == English {
+		upColor, downColor = downColor, upColor
+	}
+	lineColor := upColor
+	if snapshots[len(snapshots)-1].TotalValue < snapshots[0].TotalValue {
+		lineColor = downColor
+	}
+	valueStyle := lipgloss.NewStyle().Foreground(lineColor)
+	costStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	xLabelFormatter := func(index int, value float64) string {
+		idx := int(math.Round(value))
+		if idx < 0 || idx >= len(snapshots) {
+			return ""
+		}
+		return snapshots[idx].Date[5:] // MM-DD
+	}
+	yLabelFormatter := func(index int, value float64) string {
+		if math.Abs(value) >= 1e6 {
+			return fmt.Sprintf("%.2fM", value/1e6)
+		}
+		return fmt.Sprintf("%.0f", value)
+	}
+
+	lc := linechart.New(chartWidth, chartHeight,
+		0, float64(len(snapshots)-1),
+		minValue-margin, maxValue+margin,
+		linechart.WithXYSteps(min(6, len(snapshots)-1), 5),
+		linechart.WithXLabelFormatter(xLabelFormatter),
+		linechart.WithYLabelFormatter(yLabelFormatter),
+		linechart.WithStyles(lipgloss.Style{}, lipgloss.Style{}, valueStyle),
+	)
+
+	// 先画成本线，再画净值线（重叠处显示净值）
+	for i := 0; i < len(snapshots)-1; i++ {
+		p1 := canvas.Float64Point{X: float64(i), Y: snapshots[i].CostBasis}
+		p2 := canvas.Float64Point{X: float64(i + 1), Y: snapshots[i+1].CostBasis}
+		lc.DrawBrailleLineWithStyle(p1, p2, costStyle)
+	}
+	for i := 0; i < len(snapshots)-1; i++ {
+		p1 := canvas.Float64Point{X: float64(i), Y: snapshots[i].TotalValue}
+		p2 := canvas.Float64Point{X: float64(i + 1), Y: snapshots[i+1].TotalValue}
+		lc.DrawBrailleLineWithStyle(p1, p2, valueStyle)
+	}
+
+	lc.DrawXYAxisAndLabel()
+	return &lc
+}
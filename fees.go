@@ -0,0 +1,97 @@
+package main
+
+import "math"
+
+// ============================================================================
+// 交易费用（佣金、印花税、规费）
+// ============================================================================
+
+// defaultFeesConfig 获取默认的交易费用配置
+func defaultFeesConfig() FeesConfig {
+	return FeesConfig{
+		// A股: 佣金万2.5最低5元，印花税0.05%仅卖出，过户费+经手费约0.00441%
+		China: FeeSchedule{
+			CommissionRate: 0.00025,
+			MinCommission:  5,
+			StampDutyRate:  0.0005,
+			LevyRate:       0.0000441,
+		},
+		// 美股: 主流券商零佣金
+		US: FeeSchedule{},
+		// 港股: 佣金万3最低3港元，印花税0.1%双边，交易征费+交易费+财汇局征费约0.00857%
+		HongKong: FeeSchedule{
+			CommissionRate: 0.0003,
+			MinCommission:  3,
+			StampDutyRate:  0.001,
+			StampDutyOnBuy: true,
+			LevyRate:       0.0000857,
+		},
+	}
+}
+
+// feeScheduleFor 获取股票所属市场的交易费用规则
+func (m *Model) feeScheduleFor(code string) FeeSchedule {
+	switch getMarketType(code) {
+	case MarketUS:
+		return m.config.Fees.US
+	case MarketHongKong:
+		return m.config.Fees.HongKong
+	default:
+		return m.config.Fees.China
+	}
+}
+
+// calculateTradeFee 计算单笔交易的总费用（四舍五入到分）
+func calculateTradeFee(schedule FeeSchedule, side TransactionType, price float64, quantity int) float64 {
+	if quantity <= 0 || price <= 0 {
+		return 0
+	}
+	amount := price * float64(quantity)
+
+	commission := amount*schedule.CommissionRate + schedule.CommissionPerShare*float64(quantity)
+	if commission > 0 && commission < schedule.MinCommission {
+		commission = schedule.MinCommission
+	}
+
+	fee := commission + amount*schedule.LevyRate
+	if side == TransactionSell || schedule.StampDutyOnBuy {
+		fee += amount * schedule.StampDutyRate
+	}
+	return math.Round(fee*100) / 100
+}
+
+// breakEvenPrice 计算清仓保本价：按该价格卖出全部持仓，扣除卖出费用后正好收回持仓成本
+// costBasis 已包含买入费用
+func breakEvenPrice(schedule FeeSchedule, costBasis float64, quantity int) float64 {
+	if quantity <= 0 || costBasis <= 0 {
+		return 0
+	}
+	q := float64(quantity)
+	proportional := schedule.StampDutyRate + schedule.LevyRate
+	perShare := schedule.CommissionPerShare * q
+
+	// 先按比例佣金求解，若佣金低于最低佣金则按最低佣金重新求解
+	price := (costBasis + perShare) / (q * (1 - schedule.CommissionRate - proportional))
+	if commission := price*q*schedule.CommissionRate + perShare; commission < schedule.MinCommission {
+		price = (costBasis + schedule.MinCommission) / (q * (1 - proportional))
+	}
+	return price
+}
+
+// estimateSellFee 估算按现价清仓的卖出费用
+func (m *Model) estimateSellFee(stock *Stock) float64 {
+	return calculateTradeFee(m.feeScheduleFor(stock.Code), TransactionSell, stock.Price, stock.CalculateTotalQuantity())
+}
+
+// calculateNetProfit 计算扣除清仓费用后的持仓净盈亏（买入费用已计入成本）
+func (m *Model) calculateNetProfit(stock *Stock) float64 {
+	summary := stock.PositionSummary()
+	marketValue := stock.Price * float64(summary.Quantity)
+	return marketValue - m.estimateSellFee(stock) - summary.CostBasis
+}
+
+// calculateBreakEvenPrice 计算股票的清仓保本价
+func (m *Model) calculateBreakEvenPrice(stock *Stock) float64 {
+	summary := stock.PositionSummary()
+	return breakEvenPrice(m.feeScheduleFor(stock.Code), summary.CostBasis, summary.Quantity)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestCalculateTradeFee(t *testing.T) {
+	fees := defaultFeesConfig()
+
+	tests := []struct {
+		desc     string
+		schedule FeeSchedule
+		side     TransactionType
+		price    float64
+		quantity int
+		expected float64
+	}{
+		// 10000 * 0.00025 = 2.5 < 5 按最低佣金，过户费 0.44
+		{"A股买入最低佣金", fees.China, TransactionBuy, 10, 1000, 5.44},
+		// 佣金 25 + 印花税 50 + 规费 4.41
+		{"A股卖出含印花税", fees.China, TransactionSell, 100, 1000, 79.41},
+		// 佣金 30 + 印花税 100 + 规费 8.57
+		{"港股买入双边印花税", fees.HongKong, TransactionBuy, 100, 1000, 138.57},
+		{"美股零佣金", fees.US, TransactionSell, 190, 10, 0},
+		{"按股收费", FeeSchedule{CommissionPerShare: 0.005, MinCommission: 1}, TransactionBuy, 50, 100, 1},
+		{"无效数量", fees.China, TransactionBuy, 10, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if fee := calculateTradeFee(tt.schedule, tt.side, tt.price, tt.quantity); math.Abs(fee-tt.expected) > 1e-9 {
+			t.Errorf("%s: fee = %.2f, expected %.2f", tt.desc, fee, tt.expected)
+		}
+	}
+}
+
+// TestBreakEvenPrice 测试按保本价清仓后净盈亏为零
+func TestBreakEvenPrice(t *testing.T) {
+	fees := defaultFeesConfig()
+
+	tests := []struct {
+		desc      string
+		schedule  FeeSchedule
+		costBasis float64
+		quantity  int
+	}{
+		{"A股大额", fees.China, 100005, 1000},
+		{"A股小额触发最低佣金", fees.China, 1005, 100},
+		{"港股", fees.HongKong, 300000, 800},
+		{"美股零费用", fees.US, 1900, 10},
+	}
+
+	for _, tt := range tests {
+		price := breakEvenPrice(tt.schedule, tt.costBasis, tt.quantity)
+		amount := price * float64(tt.quantity)
+
+		commission := math.Max(amount*tt.schedule.CommissionRate+tt.schedule.CommissionPerShare*float64(tt.quantity), tt.schedule.MinCommission)
+		exitFee := commission + amount*(tt.schedule.StampDutyRate+tt.schedule.LevyRate)
+		if net := amount - exitFee - tt.costBasis; math.Abs(net) > 1e-6 {
+			t.Errorf("%s: 保本价 %.4f 清仓净盈亏 = %.6f, expected 0", tt.desc, price, net)
+		}
+	}
+
+	if price := breakEvenPrice(fees.China, 0, 0); price != 0 {
+		t.Errorf("空仓保本价应为 0, got %.4f", price)
+	}
+}
+
+// TestLoadFeesConfig 测试加载交易费用：显式写成全 0 的费用规则不被默认值覆盖
+func TestLoadFeesConfig(t *testing.T) {
+	useTempDataDir(t)
+	if err := os.MkdirAll("cmd/conf", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defaults := defaultFeesConfig()
+
+	// 未配置交易费用：全部使用默认值
+	if err := os.WriteFile(configFile, []byte("display:\n  max_lines: 10\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if fees := loadConfig().Fees; fees != defaults {
+		t.Errorf("Fees = %+v, want %+v", fees, defaults)
+	}
+
+	// 美股显式免佣，未写的市场使用默认值
+	data := "fees:\n  us:\n    commission_rate: 0\n  china:\n    commission_rate: 0.0001\n    min_commission: 5\n"
+	if err := os.WriteFile(configFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fees := loadConfig().Fees
+	if fees.US != (FeeSchedule{}) {
+		t.Errorf("US = %+v, want zero fees", fees.US)
+	}
+	if fees.China != (FeeSchedule{CommissionRate: 0.0001, MinCommission: 5}) {
+		t.Errorf("China = %+v", fees.China)
+	}
+	if fees.HongKong != defaults.HongKong {
+		t.Errorf("HongKong = %+v, want %+v", fees.HongKong, defaults.HongKong)
+	}
+	if fee := calculateTradeFee(fees.US, TransactionBuy, 200, 100); fee != 0 {
+		t.Errorf("免佣时 fee = %v", fee)
+	}
+}
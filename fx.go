@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ============================================================================
+// 多币种与汇率
+// ============================================================================
+
+// 货币代码常量
+const (
+	CurrencyCNY = "CNY"
+	CurrencyHKD = "HKD"
+	CurrencyUSD = "USD"
+)
+
+// fxSymbolPrefix 汇率代码前缀，FX:USDCNY 表示 1 USD 兑换多少 CNY
+// 汇率代码与股票代码一样通过 fetchQuotesBatch 获取（市场类型为 MarketFX）
+const fxSymbolPrefix = "FX:"
+
+// FXRate 缓存的汇率
+type FXRate struct {
+	Rate       float64   `json:"rate"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+// FXRateTable 汇率缓存表，以货币对（如 "USDCNY"）为键
+type FXRateTable map[string]FXRate
+
+// fxRatesUpdateMsg 汇率更新消息
+type fxRatesUpdateMsg struct {
+	Rates  map[string]float64 // 货币对 -> 汇率
+	Errors map[string]error   // 获取失败的货币对
+}
+
+// PortfolioTotals 持仓汇总（总计行或市场小计行）
+type PortfolioTotals struct {
+	Label          string  `json:"label"`           // 行标签
+	Currency       string  `json:"currency"`        // 汇总金额的币种
+	MarketValue    float64 `json:"market_value"`    // 市值
+	Cost           float64 `json:"cost"`            // 持仓成本
+	RealizedProfit float64 `json:"realized_profit"` // 已实现盈亏
+	NetProfit      float64 `json:"net_profit"`      // 扣除清仓费用后的净盈亏
+}
+
+// Profit 持仓盈亏
+func (t PortfolioTotals) Profit() float64 {
+	return t.MarketValue - t.Cost
+}
+
+// ProfitRate 持仓盈亏率（%）
+func (t PortfolioTotals) ProfitRate() float64 {
+	if t.Cost <= 0 {
+		return 0
+	}
+	return t.Profit() / t.Cost * 100
+}
+
+// currencyForMarket 获取市场的交易币种
+func currencyForMarket(market MarketType) string {
+	switch market {
+	case MarketUS:
+		return CurrencyUSD
+	case MarketHongKong:
+		return CurrencyHKD
+	default:
+		return CurrencyCNY
+	}
+}
+
+// currencyForStock 获取股票的交易币种
+func currencyForStock(code string) string {
+	return currencyForMarket(getMarketType(code))
+}
+
+// normalizeCurrency 校验币种，无效值返回人民币
+func normalizeCurrency(currency string) (string, bool) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	switch currency {
+	case CurrencyCNY, CurrencyHKD, CurrencyUSD:
+		return currency, true
+	}
+	return CurrencyCNY, false
+}
+
+// fxSymbol 生成汇率代码（FX:USDCNY）
+func fxSymbol(from, to string) string {
+	return fxSymbolPrefix + from + to
+}
+
+// isFXSymbol 判断是否为汇率代码
+func isFXSymbol(symbol string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(symbol)), fxSymbolPrefix)
+}
+
+// fxPair 从汇率代码中提取货币对（FX:USDCNY -> USDCNY）
+func fxPair(symbol string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(symbol)), fxSymbolPrefix)
+}
+
+// yahooQuoteSymbol 转换为 Yahoo 报价代码（汇率: FX:USDCNY -> USDCNY=X）
+func yahooQuoteSymbol(symbol string) string {
+	if isFXSymbol(symbol) {
+		return fxPair(symbol) + "=X"
+	}
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// twelveDataQuoteSymbol 转换为 TwelveData 报价代码（汇率: FX:USDCNY -> USD/CNY）
+func twelveDataQuoteSymbol(symbol string) string {
+	if isFXSymbol(symbol) {
+		pair := fxPair(symbol)
+		if len(pair) == 6 {
+			return pair[:3] + "/" + pair[3:]
+		}
+		return pair
+	}
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// ============================================================================
+// 汇率缓存表
+// ============================================================================
+
+// loadFXRates 从文件加载汇率缓存（启动时离线也能换算）
+func loadFXRates() FXRateTable {
+	table := make(FXRateTable)
+	data, err := os.ReadFile(fxRatesFile)
+	if err != nil {
+		return table
+	}
+	if err := json.Unmarshal(data, &table); err != nil {
+		logWarn("log.fx.loadFail", err)
+		return make(FXRateTable)
+	}
+	return table
+}
+
+// saveFXRates 保存汇率缓存到文件
+func saveFXRates(table FXRateTable) {
+	data, err := json.MarshalIndent(table, "", "  ")
+	if err != nil {
+		return
+	}
+	os.WriteFile(fxRatesFile, data, 0644)
+}
+
+// Rate 查询汇率（直接货币对或反向货币对），不检查是否过期
+func (t FXRateTable) Rate(from, to string) (FXRate, bool) {
+	if from == to {
+		return FXRate{Rate: 1, UpdateTime: time.Now()}, true
+	}
+	if rate, ok := t[from+to]; ok && rate.Rate > 0 {
+		return rate, true
+	}
+	if rate, ok := t[to+from]; ok && rate.Rate > 0 {
+		return FXRate{Rate: 1 / rate.Rate, UpdateTime: rate.UpdateTime}, true
+	}
+	return FXRate{}, false
+}
+
+// baseCurrency 获取配置的基准货币
+func (m *Model) baseCurrency() string {
+	currency, _ := normalizeCurrency(m.config.Currency.BaseCurrency)
+	return currency
+}
+
+// portfolioCurrencies 获取持仓涉及的币种（按 CNY、HKD、USD 顺序）
+func (m *Model) portfolioCurrencies() []string {
+	var currencies []string
+	for _, currency := range []string{CurrencyCNY, CurrencyHKD, CurrencyUSD} {
+		for _, stock := range m.portfolio.Stocks {
+			if currencyForStock(stock.Code) == currency {
+				currencies = append(currencies, currency)
+				break
+			}
+		}
+	}
+	return currencies
+}
+
+// requiredFXSymbols 获取换算到基准货币所需的汇率代码
+func (m *Model) requiredFXSymbols() []string {
+	base := m.baseCurrency()
+	var symbols []string
+	for _, currency := range m.portfolioCurrencies() {
+		if currency != base {
+			symbols = append(symbols, fxSymbol(currency, base))
+		}
+	}
+	return symbols
+}
+
+// fxRatesStale 判断是否有汇率缺失或超过刷新间隔
+func (m *Model) fxRatesStale() bool {
+	refresh := time.Duration(m.config.Currency.FXRefreshMinutes) * time.Minute
+	for _, symbol := range m.requiredFXSymbols() {
+		rate, exists := m.fxRates[fxPair(symbol)]
+		if !exists || time.Since(rate.UpdateTime) > refresh {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshFXRatesCmd 汇率过期时通过行情数据源异步刷新
+// 失败后等待 fxRetryInterval 再重试，避免每次定时刷新都请求
+func (m *Model) refreshFXRatesCmd() tea.Cmd {
+	if m.fxIsUpdating || time.Since(m.fxLastAttempt) < fxRetryInterval || !m.fxRatesStale() {
+		return nil
+	}
+	symbols := m.requiredFXSymbols()
+	m.fxIsUpdating = true
+	m.fxLastAttempt = time.Now()
+	logDebug("log.fx.refreshStart", strings.Join(symbols, ","))
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		results, errs := fetchQuotesBatch(ctx, symbols)
+		msg := fxRatesUpdateMsg{
+			Rates:  make(map[string]float64),
+			Errors: make(map[string]error),
+		}
+		for symbol, data := range results {
+			if data != nil && data.Price > 0 {
+				msg.Rates[fxPair(symbol)] = data.Price
+			}
+		}
+		for symbol, err := range errs {
+			msg.Errors[fxPair(symbol)] = err
+		}
+		return msg
+	}
+}
+
+// handleFXRatesUpdate 更新汇率缓存表并保存
+func (m *Model) handleFXRatesUpdate(msg fxRatesUpdateMsg) {
+	m.fxIsUpdating = false
+	now := time.Now()
+	for pair, rate := range msg.Rates {
+		m.fxRates[pair] = FXRate{Rate: rate, UpdateTime: now}
+		logDebug("log.fx.updated", pair, rate)
+	}
+	for pair, err := range msg.Errors {
+		logWarn("log.fx.fetchFail", pair, err)
+	}
+	if len(msg.Rates) > 0 {
+		saveFXRates(m.fxRates)
+	}
+}
+
+// ============================================================================
+// 多币种汇总
+// ============================================================================
+
+// calculatePortfolioTotals 计算各币种小计（原币）和基准货币总计
+// 缺少汇率的币种不计入总计，并在 missing 中返回
+func (m *Model) calculatePortfolioTotals() (subtotals []PortfolioTotals, total PortfolioTotals, missing []string) {
+	base := m.baseCurrency()
+	total = PortfolioTotals{Label: m.getText("total"), Currency: base}
+
+	for _, currency := range m.portfolioCurrencies() {
+		subtotal := PortfolioTotals{Currency: currency}
+		var market MarketType
+		for i := range m.portfolio.Stocks {
+			stock := &m.portfolio.Stocks[i]
+			if currencyForStock(stock.Code) != currency {
+				continue
+			}
+			market = getMarketType(stock.Code)
+
+			subtotal.RealizedProfit += stock.CalculateRealizedProfit()
+			if stock.Price > 0 {
+				subtotal.MarketValue += stock.Price * float64(stock.Quantity)
+				subtotal.Cost += stock.CostPrice * float64(stock.Quantity)
+				subtotal.NetProfit += m.calculateNetProfit(stock)
+			}
+		}
+		subtotal.Label = fmt.Sprintf("%s %s", m.getText("subtotal"), m.getMarketTagName(market))
+		subtotals = append(subtotals, subtotal)
+
+		rate, ok := m.fxRates.Rate(currency, base)
+		if !ok {
+			missing = append(missing, currency+"/"+base)
+			continue
+		}
+		total.MarketValue += subtotal.MarketValue * rate.Rate
+		total.Cost += subtotal.Cost * rate.Rate
+		total.RealizedProfit += subtotal.RealizedProfit * rate.Rate
+		total.NetProfit += subtotal.NetProfit * rate.Rate
+	}
+
+	return subtotals, total, missing
+}
+
+// formatFXRatesLine 生成当前使用的汇率说明（如 "USD/CNY 7.1200 (10:30)"）
+func (m *Model) formatFXRatesLine() string {
+	base := m.baseCurrency()
+	var parts []string
+	for _, currency := range m.portfolioCurrencies() {
+		if currency == base {
+			continue
+		}
+		if rate, ok := m.fxRates.Rate(currency, base); ok {
+			parts = append(parts, fmt.Sprintf("%s/%s %.4f (%s)", currency, base, rate.Rate, rate.UpdateTime.Format("01-02 15:04")))
+		}
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestFXRateTableLookup 测试直接、反向和同币种汇率查询
+func TestFXRateTableLookup(t *testing.T) {
+	table := FXRateTable{
+		"USDCNY": {Rate: 7.1, UpdateTime: time.Now()},
+	}
+
+	if rate, ok := table.Rate(CurrencyUSD, CurrencyCNY); !ok || !almostEqual(rate.Rate, 7.1) {
+		t.Errorf("USD->CNY = %v, %v, expected 7.1", rate.Rate, ok)
+	}
+	if rate, ok := table.Rate(CurrencyCNY, CurrencyUSD); !ok || !almostEqual(rate.Rate, 1/7.1) {
+		t.Errorf("CNY->USD = %v, %v, expected %v", rate.Rate, ok, 1/7.1)
+	}
+	if rate, ok := table.Rate(CurrencyHKD, CurrencyHKD); !ok || rate.Rate != 1 {
+		t.Errorf("同币种汇率应为 1, got %v", rate.Rate)
+	}
+	if _, ok := table.Rate(CurrencyHKD, CurrencyCNY); ok {
+		t.Error("缺少 HKDCNY 时应返回 false")
+	}
+}
+
+func TestFXQuoteSymbols(t *testing.T) {
+	symbol := fxSymbol(CurrencyUSD, CurrencyCNY)
+	if getMarketType(symbol) != MarketFX {
+		t.Errorf("getMarketType(%s) = %s, expected fx", symbol, getMarketType(symbol))
+	}
+	if got := yahooQuoteSymbol(symbol); got != "USDCNY=X" {
+		t.Errorf("yahooQuoteSymbol = %s", got)
+	}
+	if got := twelveDataQuoteSymbol(symbol); got != "USD/CNY" {
+		t.Errorf("twelveDataQuoteSymbol = %s", got)
+	}
+	if got := yahooQuoteSymbol("aapl"); got != "AAPL" {
+		t.Errorf("股票代码应保持不变: %s", got)
+	}
+}
+
+// TestCalculatePortfolioTotals 测试按币种小计并换算基准货币总计
+func TestCalculatePortfolioTotals(t *testing.T) {
+	config := getDefaultConfig()
+	config.Fees = FeesConfig{} // 不计费用，便于验证
+	m := &Model{
+		config:   config,
+		language: English,
+		fxRates: FXRateTable{
+			"USDCNY": {Rate: 7, UpdateTime: time.Now()},
+		},
+	}
+	m.portfolio.Stocks = []Stock{
+		{Code: "SH600000", Price: 11, Transactions: []Transaction{{Type: TransactionBuy, Price: 10, Quantity: 100}}},
+		{Code: "AAPL", Price: 200, Transactions: []Transaction{{Type: TransactionBuy, Price: 150, Quantity: 10}}},
+		{Code: "HK00700", Price: 600, Transactions: []Transaction{{Type: TransactionBuy, Price: 500, Quantity: 100}}},
+	}
+	for i := range m.portfolio.Stocks {
+		m.portfolio.Stocks[i].syncFromTransactions()
+	}
+
+	subtotals, total, missing := m.calculatePortfolioTotals()
+	if len(subtotals) != 3 {
+		t.Fatalf("小计数量 = %d, expected 3", len(subtotals))
+	}
+	if subtotals[2].Currency != CurrencyUSD || !almostEqual(subtotals[2].MarketValue, 2000) {
+		t.Errorf("美股小计应为原币 2000 USD: %+v", subtotals[2])
+	}
+	if len(missing) != 1 || missing[0] != "HKD/CNY" {
+		t.Errorf("缺少汇率 = %v, expected [HKD/CNY]", missing)
+	}
+
+	// 总计 = 1100 CNY + 2000 USD * 7，港股因缺少汇率不计入
+	if total.Currency != CurrencyCNY || !almostEqual(total.MarketValue, 1100+14000) {
+		t.Errorf("总市值 = %.2f %s, expected 15100 CNY", total.MarketValue, total.Currency)
+	}
+	if !almostEqual(total.Profit(), 100+3500) {
+		t.Errorf("总盈亏 = %.2f, expected 3600", total.Profit())
+	}
+}
+
+// TestRefreshFXRatesFromMock 测试通过行情数据源获取汇率（含反向汇率）
+func TestRefreshFXRatesFromMock(t *testing.T) {
+	useMockQuoteServer(t)
+
+	symbols := []string{fxSymbol(CurrencyUSD, CurrencyCNY), fxSymbol(CurrencyCNY, CurrencyHKD)}
+	results, errs := fetchQuotesBatch(context.Background(), symbols)
+
+	if data := results[symbols[0]]; data == nil || !almostEqual(data.Price, 7.1) {
+		t.Errorf("USDCNY = %+v, errs = %v", data, errs)
+	}
+	if _, ok := results[symbols[1]]; ok {
+		t.Error("模拟数据中没有 CNYHKD，应获取失败")
+	}
+	if errs[symbols[1]] == nil {
+		t.Error("CNYHKD 应返回错误")
+	}
+}
@@ -0,0 +1,609 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// 技术指标（分时图和日K线共用）
+// ============================================================================
+
+// Indicator 技术指标类型
+type Indicator string
+
+const (
+	IndicatorMA        Indicator = "ma"   // 移动平均线
+	IndicatorEMA       Indicator = "ema"  // 指数移动平均线
+	IndicatorVWAP      Indicator = "vwap" // 成交量加权平均价
+	IndicatorBollinger Indicator = "boll" // 布林带
+	IndicatorMACD      Indicator = "macd" // MACD 副图
+	IndicatorRSI       Indicator = "rsi"  // RSI 副图
+)
+
+// allIndicators 全部指标，按数字键 1-6 的顺序
+var allIndicators = []Indicator{IndicatorMA, IndicatorEMA, IndicatorVWAP, IndicatorBollinger, IndicatorMACD, IndicatorRSI}
+
+const indicatorPanelRows = 4 // MACD/RSI 副图行数
+
+// 指标线颜色（多条均线依次使用）
+var (
+	maColors        = []string{"11", "13", "14", "12"}
+	emaColors       = []string{"214", "177", "45", "111"}
+	vwapColor       = "15"
+	bollingerColor  = "245"
+	macdColor       = "15"
+	macdSignalColor = "11"
+	rsiColor        = "13"
+)
+
+// defaultIndicatorsConfig 默认指标参数
+func defaultIndicatorsConfig() IndicatorsConfig {
+	return IndicatorsConfig{
+		MAPeriods:       []int{5, 20},
+		EMAPeriods:      []int{12, 26},
+		BollingerPeriod: 20,
+		BollingerWidth:  2,
+		MACDFast:        12,
+		MACDSlow:        26,
+		MACDSignal:      9,
+		RSIPeriod:       14,
+	}
+}
+
+// normalizeIndicatorsConfig 校验指标参数，未配置或无效的参数使用默认值
+func normalizeIndicatorsConfig(cfg IndicatorsConfig) IndicatorsConfig {
+	defaults := defaultIndicatorsConfig()
+
+	enabled := make([]Indicator, 0, len(cfg.Enabled))
+	for _, indicator := range cfg.Enabled {
+		normalized := Indicator(strings.ToLower(strings.TrimSpace(string(indicator))))
+		if indicatorIndex(normalized) < 0 {
+			logWarn("log.config.invalidIndicator", indicator)
+			continue
+		}
+		enabled = append(enabled, normalized)
+	}
+	cfg.Enabled = enabled
+
+	validPeriods := func(periods []int) bool {
+		for _, period := range periods {
+			if period <= 0 {
+				return false
+			}
+		}
+		return len(periods) > 0
+	}
+	if !validPeriods(cfg.MAPeriods) {
+		cfg.MAPeriods = defaults.MAPeriods
+	}
+	if !validPeriods(cfg.EMAPeriods) {
+		cfg.EMAPeriods = defaults.EMAPeriods
+	}
+	if cfg.BollingerPeriod <= 1 {
+		cfg.BollingerPeriod = defaults.BollingerPeriod
+	}
+	if cfg.BollingerWidth <= 0 {
+		cfg.BollingerWidth = defaults.BollingerWidth
+	}
+	if cfg.MACDFast <= 0 || cfg.MACDSlow <= cfg.MACDFast || cfg.MACDSignal <= 0 {
+		cfg.MACDFast, cfg.MACDSlow, cfg.MACDSignal = defaults.MACDFast, defaults.MACDSlow, defaults.MACDSignal
+	}
+	if cfg.RSIPeriod <= 0 {
+		cfg.RSIPeriod = defaults.RSIPeriod
+	}
+	return cfg
+}
+
+// indicatorIndex 指标在 allIndicators 中的位置（未知指标返回 -1）
+func indicatorIndex(indicator Indicator) int {
+	for i, known := range allIndicators {
+		if known == indicator {
+			return i
+		}
+	}
+	return -1
+}
+
+// ----------------------------------------------------------------------------
+// 指标计算：输入按时间顺序排列，无法计算的位置（预热期、缺失数据）为 NaN
+// ----------------------------------------------------------------------------
+
+// nanSeries 创建全部为 NaN 的序列
+func nanSeries(n int) []float64 {
+	series := make([]float64, n)
+	for i := range series {
+		series[i] = math.NaN()
+	}
+	return series
+}
+
+// simpleMovingAverage 简单移动平均，窗口内有缺失数据时为 NaN
+func simpleMovingAverage(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 {
+		return result
+	}
+	var sum float64
+	valid := 0 // 窗口内连续有效值个数
+	for i, v := range values {
+		if math.IsNaN(v) {
+			sum, valid = 0, 0
+			continue
+		}
+		sum += v
+		valid++
+		if valid > period {
+			sum -= values[i-period]
+			valid = period
+		}
+		if valid == period {
+			result[i] = sum / float64(period)
+		}
+	}
+	return result
+}
+
+// exponentialMovingAverage 指数移动平均，以前 period 个有效值的简单平均作为初值
+// 缺失数据位置为 NaN，不影响之后的计算
+func exponentialMovingAverage(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 {
+		return result
+	}
+	alpha := 2 / float64(period+1)
+	var ema, seed float64
+	count := 0
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		count++
+		switch {
+		case count < period:
+			seed += v
+			continue
+		case count == period:
+			ema = (seed + v) / float64(period)
+		default:
+			ema += alpha * (v - ema)
+		}
+		result[i] = ema
+	}
+	return result
+}
+
+// volumeWeightedAveragePrice 累计成交量加权平均价（从序列起点开始累计）
+func volumeWeightedAveragePrice(prices, volumes []float64) []float64 {
+	result := nanSeries(len(prices))
+	var amount, volume float64
+	for i, price := range prices {
+		if math.IsNaN(price) || i >= len(volumes) {
+			continue
+		}
+		amount += price * volumes[i]
+		volume += volumes[i]
+		if volume > 0 {
+			result[i] = amount / volume
+		}
+	}
+	return result
+}
+
+// bollingerBands 布林带：中轨为 period 日均线，上下轨为中轨 ± width 倍标准差
+func bollingerBands(values []float64, period int, width float64) (middle, upper, lower []float64) {
+	middle = simpleMovingAverage(values, period)
+	upper, lower = nanSeries(len(values)), nanSeries(len(values))
+	for i, mean := range middle {
+		if math.IsNaN(mean) {
+			continue
+		}
+		var variance float64
+		for _, v := range values[i-period+1 : i+1] {
+			variance += (v - mean) * (v - mean)
+		}
+		deviation := math.Sqrt(variance / float64(period))
+		upper[i] = mean + width*deviation
+		lower[i] = mean - width*deviation
+	}
+	return middle, upper, lower
+}
+
+// movingAverageConvergenceDivergence MACD：快慢 EMA 之差、其信号线 EMA 以及两者之差（柱）
+func movingAverageConvergenceDivergence(values []float64, fast, slow, signal int) (line, signalLine, histogram []float64) {
+	fastEMA := exponentialMovingAverage(values, fast)
+	slowEMA := exponentialMovingAverage(values, slow)
+	line = nanSeries(len(values))
+	for i := range values {
+		line[i] = fastEMA[i] - slowEMA[i] // 任一为 NaN 时结果为 NaN
+	}
+	signalLine = exponentialMovingAverage(line, signal)
+	histogram = nanSeries(len(values))
+	for i := range values {
+		histogram[i] = line[i] - signalLine[i]
+	}
+	return line, signalLine, histogram
+}
+
+// relativeStrengthIndex RSI（Wilder 平滑），范围 0-100
+func relativeStrengthIndex(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 {
+		return result
+	}
+	var avgGain, avgLoss float64
+	previous := math.NaN()
+	count := 0 // 已累计的涨跌幅个数
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		if math.IsNaN(previous) {
+			previous = v
+			continue
+		}
+		change := v - previous
+		previous = v
+		gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+		count++
+		if count <= period {
+			avgGain += gain / float64(period)
+			avgLoss += loss / float64(period)
+			if count < period {
+				continue
+			}
+		} else {
+			avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+			avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+		}
+
+		switch {
+		case avgLoss == 0 && avgGain == 0:
+			result[i] = 50
+		case avgLoss == 0:
+			result[i] = 100
+		default:
+			result[i] = 100 - 100/(1+avgGain/avgLoss)
+		}
+	}
+	return result
+}
+
+// lastValid 序列中最后一个有效值
+func lastValid(values []float64) (float64, bool) {
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			return values[i], true
+		}
+	}
+	return 0, false
+}
+
+// ----------------------------------------------------------------------------
+// 图表叠加线和副图
+// ----------------------------------------------------------------------------
+
+// indicatorSeries 指标计算所需的价格序列（收盘价、典型价和成交量按时间对齐）
+type indicatorSeries struct {
+	Close   []float64
+	Typical []float64 // (最高+最低+收盘)/3，没有高低价时为收盘价
+	Volume  []float64
+}
+
+// indicatorLine 一条指标线
+type indicatorLine struct {
+	Indicator Indicator
+	Label     string
+	Values    []float64
+	Style     lipgloss.Style
+}
+
+// indicatorPanel 价格图下方的指标副图
+type indicatorPanel struct {
+	Title     string
+	Histogram []float64 // 以 0 为基线的柱（MACD）
+	Lines     []indicatorLine
+	Low, High float64   // 固定纵轴范围（High <= Low 时按数据自动计算）
+	Levels    []float64 // 参考线（RSI 30/70）
+}
+
+// defaultEnabledIndicators 按配置生成初始的指标开关
+func defaultEnabledIndicators(cfg IndicatorsConfig) map[Indicator]bool {
+	enabled := make(map[Indicator]bool, len(allIndicators))
+	for _, indicator := range cfg.Enabled {
+		enabled[indicator] = true
+	}
+	return enabled
+}
+
+// toggleIndicatorKey 数字键 1-6 切换对应指标
+func (m *Model) toggleIndicatorKey(key string) {
+	if len(key) != 1 || key[0] < '1' || int(key[0]-'1') >= len(allIndicators) {
+		return
+	}
+	indicator := allIndicators[key[0]-'1']
+	m.chartIndicators[indicator] = !m.indicatorEnabled(indicator)
+	logDebug("log.chart.toggleIndicator", indicator, m.chartIndicators[indicator])
+}
+
+// indicatorEnabled 指标是否打开（尚未切换过时使用配置的默认值）
+func (m *Model) indicatorEnabled(indicator Indicator) bool {
+	if m.chartIndicators == nil {
+		m.chartIndicators = defaultEnabledIndicators(m.config.Indicators)
+	}
+	return m.chartIndicators[indicator]
+}
+
+// priceOverlays 计算叠加在价格图上的指标线（均线、指数均线、VWAP、布林带）
+func (m *Model) priceOverlays(series indicatorSeries) []indicatorLine {
+	cfg := m.config.Indicators
+	var lines []indicatorLine
+	if m.indicatorEnabled(IndicatorMA) {
+		for i, period := range cfg.MAPeriods {
+			lines = append(lines, indicatorLine{
+				Indicator: IndicatorMA,
+				Label:     fmt.Sprintf("MA%d", period),
+				Values:    simpleMovingAverage(series.Close, period),
+				Style:     lipgloss.NewStyle().Foreground(lipgloss.Color(maColors[i%len(maColors)])),
+			})
+		}
+	}
+	if m.indicatorEnabled(IndicatorEMA) {
+		for i, period := range cfg.EMAPeriods {
+			lines = append(lines, indicatorLine{
+				Indicator: IndicatorEMA,
+				Label:     fmt.Sprintf("EMA%d", period),
+				Values:    exponentialMovingAverage(series.Close, period),
+				Style:     lipgloss.NewStyle().Foreground(lipgloss.Color(emaColors[i%len(emaColors)])),
+			})
+		}
+	}
+	if m.indicatorEnabled(IndicatorVWAP) {
+		lines = append(lines, indicatorLine{
+			Indicator: IndicatorVWAP,
+			Label:     "VWAP",
+			Values:    volumeWeightedAveragePrice(series.Typical, series.Volume),
+			Style:     lipgloss.NewStyle().Foreground(lipgloss.Color(vwapColor)),
+		})
+	}
+	if m.indicatorEnabled(IndicatorBollinger) {
+		middle, upper, lower := bollingerBands(series.Close, cfg.BollingerPeriod, cfg.BollingerWidth)
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(bollingerColor))
+		lines = append(lines,
+			indicatorLine{Indicator: IndicatorBollinger, Label: "BOLL↑", Values: upper, Style: style},
+			indicatorLine{Indicator: IndicatorBollinger, Label: fmt.Sprintf("BOLL%d", cfg.BollingerPeriod), Values: middle, Style: style},
+			indicatorLine{Indicator: IndicatorBollinger, Label: "BOLL↓", Values: lower, Style: style},
+		)
+	}
+	return lines
+}
+
+// indicatorPanels 计算价格图下方的指标副图（MACD、RSI）
+func (m *Model) indicatorPanels(series indicatorSeries) []indicatorPanel {
+	cfg := m.config.Indicators
+	var panels []indicatorPanel
+	if m.indicatorEnabled(IndicatorMACD) {
+		line, signal, histogram := movingAverageConvergenceDivergence(series.Close, cfg.MACDFast, cfg.MACDSlow, cfg.MACDSignal)
+		panels = append(panels, indicatorPanel{
+			Title:     "MACD",
+			Histogram: histogram,
+			Lines: []indicatorLine{
+				{Indicator: IndicatorMACD, Label: fmt.Sprintf("MACD(%d,%d,%d)", cfg.MACDFast, cfg.MACDSlow, cfg.MACDSignal), Values: line, Style: lipgloss.NewStyle().Foreground(lipgloss.Color(macdColor))},
+				{Indicator: IndicatorMACD, Label: "Signal", Values: signal, Style: lipgloss.NewStyle().Foreground(lipgloss.Color(macdSignalColor))},
+			},
+		})
+	}
+	if m.indicatorEnabled(IndicatorRSI) {
+		panels = append(panels, indicatorPanel{
+			Title: "RSI",
+			Lines: []indicatorLine{
+				{Indicator: IndicatorRSI, Label: fmt.Sprintf("RSI%d", cfg.RSIPeriod), Values: relativeStrengthIndex(series.Close, cfg.RSIPeriod), Style: lipgloss.NewStyle().Foreground(lipgloss.Color(rsiColor))},
+			},
+			Low: 0, High: 100,
+			Levels: []float64{30, 70},
+		})
+	}
+	return panels
+}
+
+// indicatorLegend 指标图例：每条线的名称和最新值（按线的颜色显示），以及切换按键提示
+func (m *Model) indicatorLegend(overlays []indicatorLine, panels []indicatorPanel) string {
+	var parts []string
+	add := func(line indicatorLine) {
+		if value, ok := lastValid(line.Values); ok {
+			parts = append(parts, line.Style.Render(fmt.Sprintf("%s %s", line.Label, formatIndicatorValue(value))))
+		}
+	}
+	for _, line := range overlays {
+		add(line)
+	}
+	for _, panel := range panels {
+		for _, line := range panel.Lines {
+			add(line)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatIndicatorValue 指标值的显示精度随量级变化
+func formatIndicatorValue(value float64) string {
+	switch abs := math.Abs(value); {
+	case abs >= 100:
+		return fmt.Sprintf("%.2f", value)
+	case abs >= 1:
+		return fmt.Sprintf("%.3f", value)
+	}
+	return fmt.Sprintf("%.4f", value)
+}
+
+// renderIndicatorPanel 绘制指标副图（纵轴和绘图区与价格图对齐）
+// columns 将与原始数据对齐的序列换算为绘图区每列的值；柱以 0 为基线按涨跌着色，线用 • 标出
+func renderIndicatorPanel(panel indicatorPanel, columns func([]float64) []float64, origin, rows int, isAShare bool) string {
+	histogram := columns(panel.Histogram)
+	lines := make([][]float64, len(panel.Lines))
+	for i, line := range panel.Lines {
+		lines[i] = columns(line.Values)
+	}
+
+	low, high := panel.Low, panel.High
+	if high <= low {
+		low, high = 0, 0 // 自动范围始终包含 0 基线
+		for _, values := range append([][]float64{histogram}, lines...) {
+			for _, v := range values {
+				if !math.IsNaN(v) {
+					low, high = math.Min(low, v), math.Max(high, v)
+				}
+			}
+		}
+		if high == low {
+			return ""
+		}
+	}
+	rowHeight := (high - low) / float64(rows)
+	rowOf := func(v float64) int {
+		return min(max(int((high-v)/rowHeight), 0), rows-1)
+	}
+
+	width := len(histogram)
+	grid := make([][]string, rows)
+	for r := range grid {
+		grid[r] = make([]string, width)
+		for c := range grid[r] {
+			grid[r][c] = " "
+		}
+	}
+
+	// 参考线（只画到有数据的最后一列）
+	dataWidth := 0
+	for _, values := range append([][]float64{histogram}, lines...) {
+		for c, v := range values {
+			if !math.IsNaN(v) {
+				dataWidth = max(dataWidth, c+1)
+			}
+		}
+	}
+	levelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+	for _, level := range panel.Levels {
+		r := rowOf(level)
+		for c := range dataWidth {
+			grid[r][c] = levelStyle.Render("┈")
+		}
+	}
+
+	// 柱：从 0 所在行延伸到数值所在行
+	zero := rowOf(0)
+	for c, v := range histogram {
+		if math.IsNaN(v) || v == 0 {
+			continue
+		}
+		style := trendStyle(isAShare, v > 0)
+		for r := min(zero, rowOf(v)); r <= max(zero, rowOf(v)); r++ {
+			grid[r][c] = style.Render("│")
+		}
+	}
+
+	// 线
+	for i, values := range lines {
+		for c, v := range values {
+			if !math.IsNaN(v) {
+				grid[rowOf(v)][c] = panel.Lines[i].Style.Render("•")
+			}
+		}
+	}
+
+	axisStyle := lipgloss.NewStyle()
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	title := panel.Title
+	if len(title) > origin {
+		title = ""
+	}
+
+	output := make([]string, rows)
+	for r := range grid {
+		var b strings.Builder
+		if r == 0 {
+			b.WriteString(labelStyle.Render(fmt.Sprintf("%*s", origin, title)))
+		} else {
+			b.WriteString(strings.Repeat(" ", origin))
+		}
+		b.WriteString(axisStyle.Render("│"))
+		b.WriteString(strings.Join(grid[r], ""))
+		output[r] = b.String()
+	}
+	return strings.Join(output, "\n")
+}
+
+// intradayIndicatorSeries 将分时数据按时间框架对齐为指标序列
+// 第一笔数据之前和最后一笔数据之后为 NaN（不把未来时段当作横盘），中间缺失的分钟沿用上一价格、成交量为 0
+func intradayIndicatorSeries(data *IntradayData, timeFramework []TimePoint) indicatorSeries {
+	n := len(timeFramework)
+	series := indicatorSeries{Close: nanSeries(n), Typical: nanSeries(n), Volume: make([]float64, n)}
+	if data == nil || len(data.Datapoints) == 0 {
+		return series
+	}
+
+	points := make(map[string]IntradayDataPoint, len(data.Datapoints))
+	for _, dp := range data.Datapoints {
+		points[dp.Time] = dp
+	}
+
+	last := -1
+	for i, tp := range timeFramework {
+		if _, exists := points[tp.Time.Format("15:04")]; exists {
+			last = i
+		}
+	}
+
+	price := math.NaN()
+	for i := 0; i <= last; i++ {
+		dp, exists := points[timeFramework[i].Time.Format("15:04")]
+		if !exists {
+			if !math.IsNaN(price) {
+				series.Close[i], series.Typical[i] = price, price
+			}
+			continue
+		}
+		price = dp.Price
+		series.Close[i], series.Typical[i] = dp.Price, dp.Price
+		if dp.HasOHLC() {
+			series.Typical[i] = (dp.High + dp.Low + dp.Price) / 3
+		}
+		series.Volume[i] = float64(dp.Volume)
+	}
+	return series
+}
+
+// dailyIndicatorSeries 日K线的指标序列
+func dailyIndicatorSeries(bars []DailyBar) indicatorSeries {
+	series := indicatorSeries{
+		Close:   make([]float64, len(bars)),
+		Typical: make([]float64, len(bars)),
+		Volume:  make([]float64, len(bars)),
+	}
+	for i, bar := range bars {
+		series.Close[i] = bar.Close
+		series.Typical[i] = (bar.High + bar.Low + bar.Close) / 3
+		series.Volume[i] = float64(bar.Volume)
+	}
+	return series
+}
+
+// frameworkColumns 返回把时间框架上的序列换算为绘图区每列的函数（与 buildVolumeColumns 的分列一致，取每列最后一分钟的值）
+func frameworkColumns(n, width int) func([]float64) []float64 {
+	return func(values []float64) []float64 {
+		columns := nanSeries(width)
+		if n == 0 || len(values) < n {
+			return columns
+		}
+		for c := range columns {
+			start := c * n / width
+			end := min(max((c+1)*n/width, start+1), n)
+			columns[c] = values[end-1]
+		}
+		return columns
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMovingAverages(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, math.NaN(), 6, 7}
+
+	sma := simpleMovingAverage(values, 3)
+	if !math.IsNaN(sma[1]) || !almostEqual(sma[2], 2) || !almostEqual(sma[4], 4) {
+		t.Errorf("sma = %v", sma)
+	}
+	// 缺失数据之后重新累计窗口
+	if !math.IsNaN(sma[5]) || !math.IsNaN(sma[7]) {
+		t.Errorf("缺失数据后 sma = %v", sma)
+	}
+
+	// 以前 3 个值的均值为初值，之后按 alpha = 0.5 平滑；缺失数据跳过
+	ema := exponentialMovingAverage(values, 3)
+	if !math.IsNaN(ema[1]) || !almostEqual(ema[2], 2) || !almostEqual(ema[3], 3) || !almostEqual(ema[4], 4) {
+		t.Errorf("ema = %v", ema)
+	}
+	if !math.IsNaN(ema[5]) || !almostEqual(ema[6], 5) {
+		t.Errorf("缺失数据后 ema = %v", ema)
+	}
+
+	vwap := volumeWeightedAveragePrice([]float64{10, 11, 12}, []float64{0, 100, 300})
+	if !math.IsNaN(vwap[0]) || !almostEqual(vwap[1], 11) || !almostEqual(vwap[2], 11.75) {
+		t.Errorf("vwap = %v", vwap)
+	}
+}
+
+func TestBollingerBands(t *testing.T) {
+	middle, upper, lower := bollingerBands([]float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2)
+	// 均值 5，总体标准差 2
+	if !almostEqual(middle[7], 5) || !almostEqual(upper[7], 9) || !almostEqual(lower[7], 1) {
+		t.Errorf("boll = %.3f / %.3f / %.3f", upper[7], middle[7], lower[7])
+	}
+	if !math.IsNaN(upper[6]) {
+		t.Error("预热期上轨应为 NaN")
+	}
+}
+
+func TestMACDAndRSI(t *testing.T) {
+	rising := make([]float64, 60)
+	for i := range rising {
+		rising[i] = 10 + float64(i)*0.1
+	}
+
+	line, signal, histogram := movingAverageConvergenceDivergence(rising, 12, 26, 9)
+	if !math.IsNaN(line[24]) || math.IsNaN(line[25]) || !math.IsNaN(signal[32]) || math.IsNaN(signal[33]) {
+		t.Errorf("预热期: line[24..25] = %v, signal[32..33] = %v", line[24:26], signal[32:34])
+	}
+	// 匀速上涨：快线在慢线上方，且差值收敛到常数
+	if line[59] <= 0 || math.Abs(histogram[59]) > 1e-3 {
+		t.Errorf("macd = %.4f, histogram = %.4f", line[59], histogram[59])
+	}
+
+	if rsi := relativeStrengthIndex(rising, 14); !math.IsNaN(rsi[13]) || rsi[14] != 100 {
+		t.Errorf("单边上涨 rsi = %v", rsi[13:15])
+	}
+	// 涨跌交替且幅度相同时 RSI 接近 50
+	alternating := make([]float64, 40)
+	for i := range alternating {
+		alternating[i] = 10 + float64(i%2)
+	}
+	if rsi := relativeStrengthIndex(alternating, 14); math.Abs(rsi[39]-50) > 5 {
+		t.Errorf("震荡 rsi = %.2f", rsi[39])
+	}
+}
+
+func TestNormalizeIndicatorsConfig(t *testing.T) {
+	cfg := normalizeIndicatorsConfig(IndicatorsConfig{
+		Enabled:   []Indicator{"MA", "kdj", " rsi"},
+		MAPeriods: []int{10, 0},
+		MACDFast:  26,
+		MACDSlow:  12,
+	})
+	if len(cfg.Enabled) != 2 || cfg.Enabled[0] != IndicatorMA || cfg.Enabled[1] != IndicatorRSI {
+		t.Errorf("enabled = %v", cfg.Enabled)
+	}
+	defaults := defaultIndicatorsConfig()
+	if fmt.Sprint(cfg.MAPeriods) != fmt.Sprint(defaults.MAPeriods) || cfg.MACDFast != 12 || cfg.MACDSlow != 26 {
+		t.Errorf("无效参数应使用默认值: %+v", cfg)
+	}
+	if cfg.BollingerPeriod != 20 || cfg.BollingerWidth != 2 || cfg.RSIPeriod != 14 {
+		t.Errorf("未配置参数应使用默认值: %+v", cfg)
+	}
+}
+
+// TestIntradayIndicators 测试分时图数字键切换指标以及叠加线、副图的渲染
+func TestIntradayIndicators(t *testing.T) {
+	m := &Model{config: getDefaultConfig(), language: English, state: IntradayChartViewing}
+	data := &IntradayData{Code: "SH600000", Name: "浦发银行", Date: "20250314", Market: MarketChina, PrevClose: 10}
+	for i := range 90 {
+		minute := time.Date(2025, 3, 14, 9, 30+i, 0, 0, time.UTC)
+		price := 10 + 0.2*math.Sin(float64(i)/8)
+		data.Datapoints = append(data.Datapoints, IntradayDataPoint{Time: minute.Format("15:04"), Price: price, Volume: int64(1000 + i)})
+	}
+	m.chartData = data
+	m.chartViewStock, m.chartViewDate = data.Code, data.Date
+
+	// 序列在最后一笔数据之后为 NaN
+	series := intradayIndicatorSeries(data, m.createFixedTimeRange(data.Date, data.Market))
+	if math.IsNaN(series.Close[89]) || !math.IsNaN(series.Close[90]) || series.Volume[0] != 1000 {
+		t.Errorf("close[89..90] = %v, volume[0] = %v", series.Close[89:91], series.Volume[0])
+	}
+
+	before := m.viewIntradayChart(120, 40)
+	if strings.Contains(before, "MA5") {
+		t.Error("默认不应显示指标")
+	}
+
+	m.handleIntradayChartViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	m.handleIntradayChartViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+	if !m.chartIndicators[IndicatorMA] || !m.chartIndicators[IndicatorMACD] || m.chartIndicators[IndicatorRSI] {
+		t.Fatalf("indicators = %v", m.chartIndicators)
+	}
+	view := m.viewIntradayChart(120, 40)
+	if !strings.Contains(view, "MA5") || !strings.Contains(view, "MA20") || !strings.Contains(view, "MACD(12,26,9)") {
+		t.Errorf("图例缺失:\n%s", view)
+	}
+	if len(strings.Split(view, "\n")) <= len(strings.Split(before, "\n")) {
+		t.Error("打开 MACD 后应增加副图")
+	}
+
+	// 再按一次关闭
+	m.handleIntradayChartViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	if m.chartIndicators[IndicatorMA] {
+		t.Error("再次按键应关闭指标")
+	}
+}
+
+// TestKLineIndicators 测试日K线的指标叠加线和副图与蜡烛对齐
+func TestKLineIndicators(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+	if _, err := updateDailyData("SH600000", "浦发银行", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Model{config: getDefaultConfig(), language: English, state: Monitoring}
+	m.config.Indicators.Enabled = []Indicator{IndicatorMA, IndicatorVWAP, IndicatorRSI}
+	m.enterKLineViewing("SH600000", "浦发银行")
+
+	// 在全部数据上计算，窗口第一天的 MA20 已有值；VWAP 从窗口第一天开始累计
+	overlays, panels := m.klineIndicators()
+	bars := m.visibleDailyBars()
+	if len(overlays) != 3 || len(panels) != 1 || len(overlays[0].Values) != len(bars) {
+		t.Fatalf("overlays = %d, panels = %d", len(overlays), len(panels))
+	}
+	if math.IsNaN(overlays[1].Values[0]) {
+		t.Error("窗口第一天的 MA20 不应为 NaN")
+	}
+	first := bars[0]
+	if vwap := overlays[2].Values[0]; math.Abs(vwap-(first.High+first.Low+first.Close)/3) > 1e-9 {
+		t.Errorf("窗口第一天 VWAP = %.4f", vwap)
+	}
+
+	chart, _, layout := renderCandlestickChart(bars, overlays, 100, 16, klineVolumeRows, true)
+	if !strings.Contains(chart, "·") {
+		t.Errorf("蜡烛图应包含指标线:\n%s", chart)
+	}
+	columns := layout.columnValues(overlays[0].Values)
+	if got := columns[layout.column(len(bars)-1)]; !almostEqual(got, overlays[0].Values[len(bars)-1]) {
+		t.Errorf("最后一根蜡烛所在列 = %.4f", got)
+	}
+
+	panel := renderIndicatorPanel(panels[0], layout.columnValues, layout.origin, indicatorPanelRows, true)
+	if lines := strings.Split(panel, "\n"); len(lines) != indicatorPanelRows || !strings.Contains(lines[0], "RSI") {
+		t.Errorf("RSI 副图:\n%s", panel)
+	}
+
+	// 日K线和分时图共用开关
+	m.handleKLineViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("6")})
+	if m.chartIndicators[IndicatorRSI] {
+		t.Error("6 键应关闭 RSI")
+	}
+	if view := m.viewKLineViewing(120, 40); !strings.Contains(view, "MA5") || strings.Contains(view, "RSI14") {
+		t.Errorf("K线图例:\n%s", view)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -15,9 +16,20 @@ import (
 )
 
 // IntradayDataPoint represents a single minute's price data
+// 开高低价、成交量和成交额为可选字段：旧文件和不提供这些字段的数据源为 0
 type IntradayDataPoint struct {
-	Time  string  `json:"time"`  // Format: "09:31" (HH:MM)
-	Price float64 `json:"price"` // Closing price for that minute
+	Time     string  `json:"time"`               // Format: "09:31" (HH:MM)
+	Price    float64 `json:"price"`              // Closing price for that minute
+	Open     float64 `json:"open,omitempty"`     // 该分钟开盘价
+	High     float64 `json:"high,omitempty"`     // 该分钟最高价
+	Low      float64 `json:"low,omitempty"`      // 该分钟最低价
+	Volume   int64   `json:"volume,omitempty"`   // 该分钟成交量（数据源单位，A股通常为手）
+	Turnover float64 `json:"turnover,omitempty"` // 该分钟成交额
+}
+
+// HasOHLC 是否包含该分钟的开高低价
+func (dp IntradayDataPoint) HasOHLC() bool {
+	return dp.Open > 0 && dp.High > 0 && dp.Low > 0
 }
 
 // IntradayData represents the complete intraday data for a stock on a given day
@@ -33,7 +45,7 @@ type IntradayData struct {
 
 // DatapointDiffResult 表示数据点比较结果
 type DatapointDiffResult struct {
-	HasPriceChanges  bool // 是否有价格变化
+	HasPriceChanges  bool // 是否有价格变化（含开高低价、成交量和成交额）
 	HasNewEntries    bool // 是否有新时间点
 	PriceChangeCount int  // 价格变化数量
 	NewEntryCount    int  // 新时间点数量
@@ -58,6 +70,8 @@ type IntradayManager struct {
 	fetchInterval  time.Duration              // 1 minute
 	workerMetadata map[string]*WorkerMetadata // Track each worker's state
 	metadataMutex  sync.RWMutex               // Protects workerMetadata
+	workerStops    map[string]chan struct{}   // 单个 worker 的停止信号（StopCollection），由 mu 保护
+	workerDone     map[string]chan struct{}   // worker 退出时关闭，由 mu 保护
 	model          *Model                     // Reference to main model
 }
 
@@ -73,6 +87,8 @@ func newIntradayManager(model *Model) *IntradayManager {
 		lastFetchTime:  make(map[string]time.Time),
 		fetchInterval:  1 * time.Minute,
 		workerMetadata: make(map[string]*WorkerMetadata),
+		workerStops:    make(map[string]chan struct{}),
+		workerDone:     make(map[string]chan struct{}),
 		model:          model,
 	}
 }
@@ -89,8 +105,10 @@ func getTradingState(now time.Time, marketType MarketType) TradingState {
 		return TradingStateWeekend
 	}
 
-	// TODO: 假日检测 (v2 enhancement)
-	// 目前假设工作日都是交易日
+	// 检查休市日历（节假日）
+	if !isTradingDay(marketType, now) {
+		return TradingStateHoliday
+	}
 
 	// 获取市场特定的交易时段
 	var morningStart, morningEnd, afternoonStart, afternoonEnd time.Time
@@ -118,6 +136,19 @@ func getTradingState(now time.Time, marketType MarketType) TradingState {
 		return TradingStatePostMarket
 	}
 
+	// 半日市：提前收盘后不再交易
+	if closeAt, ok := earlyCloseTime(marketType, now.Format("20060102")); ok {
+		if closeTime, err := time.Parse("15:04", closeAt); err == nil {
+			earlyClose := time.Date(now.Year(), now.Month(), now.Day(), closeTime.Hour(), closeTime.Minute(), 0, 0, now.Location())
+			if morningEnd.After(earlyClose) {
+				morningEnd = earlyClose
+			}
+			if afternoonEnd.After(earlyClose) {
+				afternoonEnd = earlyClose
+			}
+		}
+	}
+
 	// 判断当前状态
 	if now.Before(morningStart) {
 		return TradingStatePreMarket
@@ -131,13 +162,21 @@ func getTradingState(now time.Time, marketType MarketType) TradingState {
 
 // getExpectedDatapoints 计算完整交易日的预期数据点数量
 // marketType: 市场类型
+// date: 交易日期 (YYYYMMDD)，半日市按提前收盘后的交易时段计算
 // isLiveMode: 是否为实时模式（如果是，返回较宽松的预期）
 // 返回: 预期的数据点数量
 //
 // A股: 09:30-11:30 (120分钟) + 13:00-15:00 (120分钟) = 240数据点
 // 美股: 09:30-16:00 (390分钟) = 390数据点
 // 港股: 09:30-12:00 (150分钟) + 13:00-16:00 (180分钟) = 330数据点
-func getExpectedDatapoints(marketType MarketType, isLiveMode bool) int {
+func getExpectedDatapoints(marketType MarketType, date string, isLiveMode bool) int {
+	if _, halfDay := earlyCloseTime(marketType, date); halfDay {
+		sessions := defaultMarketsConfig().forMarket(marketType).TradingSessions
+		if minutes := sessionMinutes(tradingSessionsForDate(sessions, marketType, date)); minutes > 0 {
+			return minutes
+		}
+	}
+
 	switch marketType {
 	case MarketChina:
 		return 240 // 4小时 × 60分钟
@@ -182,7 +221,7 @@ func isDataComplete(stockCode string, date string, marketType MarketType, isLive
 
 	// 统计数据点数量
 	actualDatapoints := len(intradayData.Datapoints)
-	expectedDatapoints := getExpectedDatapoints(marketType, isLiveMode)
+	expectedDatapoints := getExpectedDatapoints(marketType, date, isLiveMode)
 
 	// 定义完整性标准
 	minDatapoints := 20           // 绝对最小数据点（防止误判）
@@ -228,24 +267,8 @@ func findPreviousTradingDay(stockCode string, currentDate string, m *Model) stri
 		return currentDate // 解析失败，返回原日期
 	}
 
-	// 最多回溯 7 天，找到上一个交易日
-	for i := 1; i <= 7; i++ {
-		prevDate := date.AddDate(0, 0, -i)
-		weekday := prevDate.Weekday()
-
-		// 跳过周末（周六、周日）
-		if weekday == time.Saturday || weekday == time.Sunday {
-			continue
-		}
-
-		// TODO: 检查假日日历 (v2 enhancement)
-		// 目前假设非周末的工作日都是交易日
-
-		return prevDate.Format("20060102")
-	}
-
-	// 降级：如果 7 天内找不到，直接返回前一天
-	return date.AddDate(0, 0, -1).Format("20060102")
+	// 跳过周末和休市日
+	return previousTradingDay(marketType, date).Format("20060102")
 }
 
 // GetTradingDayForCollection 决定应该采集哪天的数据以及采集模式
@@ -322,13 +345,17 @@ func (im *IntradayManager) StartCollection(stockCode, stockName string) error {
 		return nil
 	}
 
-	// 步骤 3: 检查是否已有 worker 在运行
+	// 步骤 3: 检查是否已有 worker 在运行（没有则标记为活动状态，避免重复启动）
 	im.mu.Lock()
 	if im.activeStocks[stockCode] {
 		im.mu.Unlock()
 		logInfoDirect("[Intraday] Worker already running for %s", stockCode)
 		return nil
 	}
+	im.activeStocks[stockCode] = true
+	stop, done := make(chan struct{}), make(chan struct{})
+	im.workerStops[stockCode] = stop
+	im.workerDone[stockCode] = done
 	im.mu.Unlock()
 
 	// 步骤 4: 初始化 worker 元数据
@@ -350,27 +377,51 @@ func (im *IntradayManager) StartCollection(stockCode, stockName string) error {
 	logInfoDirect("[Intraday] Starting %s collection for %s (target: %s)",
 		mode.String(), stockCode, targetDate)
 
-	go im.startSmartWorker(stockCode, stockName, targetDate, mode)
+	go im.startSmartWorker(stockCode, stockName, targetDate, mode, stop, done)
 
 	return nil
 }
 
+// IsCollecting 判断股票是否有正在运行的 worker
+func (im *IntradayManager) IsCollecting(stockCode string) bool {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	return im.activeStocks[stockCode]
+}
+
+// StopCollection 停止单只股票的 worker 并等待其退出（交易时段切换时重新启动）
+func (im *IntradayManager) StopCollection(stockCode string) {
+	im.mu.Lock()
+	stop, running := im.workerStops[stockCode]
+	done := im.workerDone[stockCode]
+	if running {
+		delete(im.workerStops, stockCode)
+		close(stop)
+	}
+	im.mu.Unlock()
+
+	if running {
+		<-done
+	}
+}
+
 // startSmartWorker 启动带有自动停止逻辑的智能 worker
 // stockCode: 股票代码
 // stockName: 股票名称
 // targetDate: 目标日期 (YYYYMMDD)
 // mode: 采集模式 (Historical/Live)
-func (im *IntradayManager) startSmartWorker(stockCode, stockName, targetDate string, mode CollectionMode) {
-	// 标记 worker 为活动状态
-	im.mu.Lock()
-	im.activeStocks[stockCode] = true
-	im.mu.Unlock()
-
-	// 清理函数
+// stop: 单个 worker 的停止信号；done: 退出时关闭
+func (im *IntradayManager) startSmartWorker(stockCode, stockName, targetDate string, mode CollectionMode, stop <-chan struct{}, done chan<- struct{}) {
+	// 清理函数（StartCollection 已标记为活动状态）
 	defer func() {
 		im.mu.Lock()
 		delete(im.activeStocks, stockCode)
+		if im.workerDone[stockCode] == done {
+			delete(im.workerStops, stockCode)
+			delete(im.workerDone, stockCode)
+		}
 		im.mu.Unlock()
+		close(done)
 
 		im.metadataMutex.Lock()
 		if meta, exists := im.workerMetadata[stockCode]; exists {
@@ -488,6 +539,10 @@ func (im *IntradayManager) startSmartWorker(stockCode, stockName, targetDate str
 		case <-im.cancelChan:
 			// 全局取消信号
 			return
+
+		case <-stop:
+			// 单个 worker 停止信号
+			return
 		}
 	}
 }
@@ -792,8 +847,8 @@ func tryGetIntradayFromSina(stockCode string) ([]IntradayDataPoint, error) {
 
 	// Build URL
 	url := fmt.Sprintf(
-		"http://money.finance.sina.com.cn/quotes_service/api/json_v2.php/CN_MarketData.getKLineData?symbol=%s&scale=1&datalen=250",
-		sinaCode,
+		"%s/quotes_service/api/json_v2.php/CN_MarketData.getKLineData?symbol=%s&scale=1&datalen=250",
+		apiEndpoints.SinaIntraday, sinaCode,
 	)
 
 	// Create HTTP client with timeout
@@ -841,10 +896,15 @@ func tryGetIntradayFromSina(stockCode string) ([]IntradayDataPoint, error) {
 			continue
 		}
 
-		result = append(result, IntradayDataPoint{
+		dp := IntradayDataPoint{
 			Time:  timeStr,
 			Price: price,
-		})
+		}
+		dp.Open, _ = strconv.ParseFloat(item.Open, 64)
+		dp.High, _ = strconv.ParseFloat(item.High, 64)
+		dp.Low, _ = strconv.ParseFloat(item.Low, 64)
+		dp.Volume, _ = strconv.ParseInt(item.Volume, 10, 64)
+		result = append(result, dp)
 	}
 
 	return result, nil
@@ -857,8 +917,8 @@ func tryGetIntradayFromEastMoney(stockCode string) ([]IntradayDataPoint, error)
 
 	// Build URL
 	url := fmt.Sprintf(
-		"https://push2.eastmoney.com/api/qt/stock/trends2/get?secid=%s&fields1=f1,f2,f3&fields2=f51,f52,f53,f54,f55&iscr=0",
-		emCode,
+		"%s/api/qt/stock/trends2/get?secid=%s&fields1=f1,f2,f3&fields2=f51,f52,f53,f54,f55,f56,f57&iscr=0",
+		apiEndpoints.EastMoney, emCode,
 	)
 
 	// Create HTTP client with timeout
@@ -885,7 +945,7 @@ func tryGetIntradayFromEastMoney(stockCode string) ([]IntradayDataPoint, error)
 	// Parse response
 	var emData struct {
 		Data struct {
-			Trends []string `json:"trends"` // ["2025-11-26 09:31,8.52,12000,...]
+			Trends []string `json:"trends"` // ["2025-11-26 09:31,开,收,高,低,量,额", ...]
 		} `json:"data"`
 	}
 
@@ -910,15 +970,32 @@ func tryGetIntradayFromEastMoney(stockCode string) ([]IntradayDataPoint, error)
 			continue
 		}
 
-		price, err := strconv.ParseFloat(parts[1], 64)
+		// 字段顺序: 时间,开,收,高,低,量,额（只有两列时第二列为价格）
+		priceField := parts[1]
+		if len(parts) >= 5 {
+			priceField = parts[2]
+		}
+		price, err := strconv.ParseFloat(priceField, 64)
 		if err != nil {
 			continue
 		}
 
-		result = append(result, IntradayDataPoint{
+		dp := IntradayDataPoint{
 			Time:  timeStr,
 			Price: price,
-		})
+		}
+		if len(parts) >= 5 {
+			dp.Open, _ = strconv.ParseFloat(parts[1], 64)
+			dp.High, _ = strconv.ParseFloat(parts[3], 64)
+			dp.Low, _ = strconv.ParseFloat(parts[4], 64)
+		}
+		if len(parts) >= 6 {
+			dp.Volume, _ = strconv.ParseInt(parts[5], 10, 64)
+		}
+		if len(parts) >= 7 {
+			dp.Turnover, _ = strconv.ParseFloat(parts[6], 64)
+		}
+		result = append(result, dp)
 	}
 
 	return result, nil
@@ -936,6 +1013,9 @@ func mergeDatapoints(existing, new []IntradayDataPoint) []IntradayDataPoint {
 
 	// Overlay new datapoints (overwrites duplicates)
 	for _, dp := range new {
+		if old, exists := dataMap[dp.Time]; exists {
+			dp = mergeDatapoint(old, dp)
+		}
 		dataMap[dp.Time] = dp
 	}
 
@@ -953,25 +1033,54 @@ func mergeDatapoints(existing, new []IntradayDataPoint) []IntradayDataPoint {
 	return result
 }
 
+// mergeDatapoint 合并同一分钟的数据点：以新数据为准，新数据源未提供的字段保留已有值
+// （如主数据源失败后由只有收盘价的备用数据源补充）
+func mergeDatapoint(existing, new IntradayDataPoint) IntradayDataPoint {
+	if !new.HasOHLC() && existing.HasOHLC() {
+		new.Open = existing.Open
+		new.High = math.Max(existing.High, new.Price)
+		new.Low = math.Min(existing.Low, new.Price)
+	}
+	if new.Volume == 0 {
+		new.Volume = existing.Volume
+	}
+	if new.Turnover == 0 {
+		new.Turnover = existing.Turnover
+	}
+	return new
+}
+
+// datapointChanged 判断新数据点是否带来变化（新数据源未提供的字段不参与比较）
+func datapointChanged(existing, new IntradayDataPoint) bool {
+	if existing.Price != new.Price {
+		return true
+	}
+	if new.HasOHLC() && (existing.Open != new.Open || existing.High != new.High || existing.Low != new.Low) {
+		return true
+	}
+	return (new.Volume != 0 && existing.Volume != new.Volume) ||
+		(new.Turnover != 0 && existing.Turnover != new.Turnover)
+}
+
 // compareDatapoints 比较已有和新的数据点，检测价格变化
 // 时间复杂度: O(n+m)
 func compareDatapoints(existing, new []IntradayDataPoint) DatapointDiffResult {
 	result := DatapointDiffResult{}
 
-	// 构建已有数据的 map (时间 -> 价格)
-	existingMap := make(map[string]float64, len(existing))
+	// 构建已有数据的 map (时间 -> 数据点)
+	existingMap := make(map[string]IntradayDataPoint, len(existing))
 	for _, dp := range existing {
-		existingMap[dp.Time] = dp.Price
+		existingMap[dp.Time] = dp
 	}
 
 	// 比较每个新数据点
 	for _, dp := range new {
-		existingPrice, exists := existingMap[dp.Time]
+		existingPoint, exists := existingMap[dp.Time]
 		if !exists {
 			// 新时间点
 			result.HasNewEntries = true
 			result.NewEntryCount++
-		} else if existingPrice != dp.Price {
+		} else if datapointChanged(existingPoint, dp) {
 			// 价格变化
 			result.HasPriceChanges = true
 			result.PriceChangeCount++
@@ -1077,16 +1186,21 @@ func getFileLock(filePath string) *sync.Mutex {
 
 // isMarketOpen 检查当前是否在交易时间内（支持多市场）
 func isMarketOpen(stockCode string, m *Model) bool {
+	return isStockMarketOpen(stockCode, m.config.Markets)
+}
+
+// isStockMarketOpen 按市场配置检查股票所在市场当前是否在交易时间内（不读取 Model，可在后台 goroutine 中使用）
+func isStockMarketOpen(stockCode string, markets MarketsConfig) bool {
 	market := getMarketType(stockCode)
 
 	var marketConfig MarketConfig
 	switch market {
 	case MarketChina:
-		marketConfig = m.config.Markets.China
+		marketConfig = markets.China
 	case MarketUS:
-		marketConfig = m.config.Markets.US
+		marketConfig = markets.US
 	case MarketHongKong:
-		marketConfig = m.config.Markets.HongKong
+		marketConfig = markets.HongKong
 	default:
 		logDebug("log.market.unknownType", stockCode, market)
 		return false
@@ -1101,16 +1215,15 @@ func isMarketOpen(stockCode string, m *Model) bool {
 	}
 
 	now := time.Now()
-	return isMarketOpenForConfig(now, marketConfig)
+	return isMarketOpenForConfig(now, market, marketConfig)
 }
 
 // isMarketOpenHardcoded 硬编码的A股交易时间判断（降级方案）
 func isMarketOpenHardcoded() bool {
 	now := time.Now()
 
-	// Check if weekday
-	weekday := now.Weekday()
-	if weekday == time.Saturday || weekday == time.Sunday {
+	// Check if trading day (weekends and holidays)
+	if !isTradingDay(MarketChina, now) {
 		return false
 	}
 
@@ -1295,8 +1408,8 @@ func tryGetIntradayFromTencent(stockCode string) ([]IntradayDataPoint, error) {
 	// Build URL - Tencent minute data API (JSONP format)
 	// Response format: min_data_sh601138={"code":0,"data":{"sh601138":{"data":{"data":["0930 60.88 10989 66901032.00",...]}}}}
 	url := fmt.Sprintf(
-		"http://ifzq.gtimg.cn/appstock/app/minute/query?_var=min_data_%s&code=%s",
-		tencentCode, tencentCode,
+		"%s/appstock/app/minute/query?_var=min_data_%s&code=%s",
+		apiEndpoints.TencentMinute, tencentCode, tencentCode,
 	)
 
 	// Create HTTP client with timeout
@@ -1353,6 +1466,9 @@ func tryGetIntradayFromTencent(stockCode string) ([]IntradayDataPoint, error) {
 	result := make([]IntradayDataPoint, 0)
 
 	for _, stockData := range tencentResp.Data {
+		// 成交量和成交额为当日累计值，逐分钟相减得到每分钟的量
+		var prevVolume int64
+		var prevAmount float64
 		for _, dataStr := range stockData.Data.Data {
 			// Format: "0930 60.88 10989 66901032.00" (time price volume amount)
 			parts := strings.Split(dataStr, " ")
@@ -1372,10 +1488,20 @@ func tryGetIntradayFromTencent(stockCode string) ([]IntradayDataPoint, error) {
 				continue
 			}
 
-			result = append(result, IntradayDataPoint{
+			dp := IntradayDataPoint{
 				Time:  timeStr,
 				Price: price,
-			})
+			}
+			if len(parts) >= 4 {
+				volume, volumeErr := strconv.ParseInt(parts[2], 10, 64)
+				amount, amountErr := strconv.ParseFloat(parts[3], 64)
+				if volumeErr == nil && amountErr == nil && volume >= prevVolume {
+					dp.Volume = volume - prevVolume
+					dp.Turnover = math.Max(amount-prevAmount, 0)
+					prevVolume, prevAmount = volume, amount
+				}
+			}
+			result = append(result, dp)
 		}
 	}
 
@@ -1391,8 +1517,8 @@ func tryGetIntradayFromYahoo(stockCode string) ([]IntradayDataPoint, error) {
 	// Build URL - Yahoo Finance chart API
 	// interval=1m (1 minute), range=1d (1 day)
 	url := fmt.Sprintf(
-		"https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1m&range=1d",
-		yahooSymbol,
+		"%s/v8/finance/chart/%s?interval=1m&range=1d",
+		apiEndpoints.Yahoo, yahooSymbol,
 	)
 
 	// Create HTTP client with timeout
@@ -1422,7 +1548,11 @@ func tryGetIntradayFromYahoo(stockCode string) ([]IntradayDataPoint, error) {
 				Timestamp  []int64 `json:"timestamp"` // Unix timestamps
 				Indicators struct {
 					Quote []struct {
-						Close []float64 `json:"close"` // Closing prices
+						Open   []float64 `json:"open"`
+						High   []float64 `json:"high"`
+						Low    []float64 `json:"low"`
+						Close  []float64 `json:"close"` // Closing prices
+						Volume []int64   `json:"volume"`
 					} `json:"quote"`
 				} `json:"indicators"`
 			} `json:"result"`
@@ -1458,6 +1588,13 @@ func tryGetIntradayFromYahoo(stockCode string) ([]IntradayDataPoint, error) {
 	}
 
 	closePrices := quotes[0].Close
+	// valueAt 取数组中的值（Yahoo 对缺失的分钟返回 null，解析为 0）
+	valueAt := func(values []float64, i int) float64 {
+		if i < len(values) {
+			return values[i]
+		}
+		return 0
+	}
 
 	// Convert timestamps and prices to IntradayDataPoint
 	datapoints := make([]IntradayDataPoint, 0, len(timestamps))
@@ -1497,10 +1634,17 @@ func tryGetIntradayFromYahoo(stockCode string) ([]IntradayDataPoint, error) {
 
 		timeStr := t.Format("15:04") // HH:MM format
 
-		datapoints = append(datapoints, IntradayDataPoint{
+		dp := IntradayDataPoint{
 			Time:  timeStr,
 			Price: price,
-		})
+			Open:  valueAt(quotes[0].Open, i),
+			High:  valueAt(quotes[0].High, i),
+			Low:   valueAt(quotes[0].Low, i),
+		}
+		if i < len(quotes[0].Volume) {
+			dp.Volume = quotes[0].Volume[i]
+		}
+		datapoints = append(datapoints, dp)
 	}
 
 	return datapoints, nil
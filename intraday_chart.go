@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -216,44 +217,32 @@ func calculateAdaptiveMargin(prices []float64) (float64, float64, float64) {
 // ============================================================================
 
 // getSmartChartDate 根据当前时间智能选择图表日期
-// 开盘前（< 9:30）：返回上一个交易日
+// 开盘前（< 9:30）或休市日：返回上一个交易日
 // 盘中（9:30-15:00）或收盘后（≥ 15:00）：返回今天
-func getSmartChartDate() string {
+func getSmartChartDate(market MarketType) string {
 	now := time.Now()
 	hour := now.Hour()
 	minute := now.Minute()
 
-	// 判断是否在开盘前（9:30之前）
-	if hour < 9 || (hour == 9 && minute < 30) {
-		// 开盘前，查找上一个交易日
-		return findPreviousTradingDayFromDate(now.Format("20060102"))
+	// 判断是否在开盘前（9:30之前）或今天休市
+	if hour < 9 || (hour == 9 && minute < 30) || !isTradingDay(market, now) {
+		// 查找上一个交易日
+		return findPreviousTradingDayFromDate(now.Format("20060102"), market)
 	}
 
 	// 盘中或收盘后，使用今天
 	return now.Format("20060102")
 }
 
-// findPreviousTradingDayFromDate 从指定日期查找上一个交易日（跳过周末）
-func findPreviousTradingDayFromDate(dateStr string) string {
+// findPreviousTradingDayFromDate 从指定日期查找上一个交易日（跳过周末和休市日）
+func findPreviousTradingDayFromDate(dateStr string, market MarketType) string {
 	// 解析日期
 	currentDate, err := time.Parse("20060102", dateStr)
 	if err != nil {
 		return dateStr
 	}
 
-	// 最多尝试10天，找到上一个交易日
-	for i := 1; i <= 10; i++ {
-		prevDate := currentDate.AddDate(0, 0, -i)
-		weekday := prevDate.Weekday()
-
-		// 跳过周末（周六=6，周日=0）
-		if weekday != time.Saturday && weekday != time.Sunday {
-			return prevDate.Format("20060102")
-		}
-	}
-
-	// 如果10天内都找不到，返回原日期
-	return dateStr
+	return previousTradingDay(market, currentDate).Format("20060102")
 }
 
 // isWeekend 判断是否为周末
@@ -264,30 +253,19 @@ func isWeekend(t time.Time) bool {
 
 // Note: findPreviousTradingDay 已移至 intraday.go 并增强为支持多市场
 
-// findNextTradingDay 查找下一个交易日（跳过周末）
-// 最多往后查找7天，避免无限循环
-func findNextTradingDay(currentDateStr string, maxDate time.Time) (string, error) {
+// findNextTradingDay 查找下一个交易日（跳过周末和休市日）
+// 不能超过最大日期（通常是今天）
+func findNextTradingDay(currentDateStr string, market MarketType, maxDate time.Time) (string, error) {
 	currentDate, err := time.Parse("20060102", currentDateStr)
 	if err != nil {
 		return "", err
 	}
 
-	// 最多往后查找7天
-	for i := 1; i <= 7; i++ {
-		nextDate := currentDate.AddDate(0, 0, i)
-
-		// 不能超过最大日期（通常是今天）
-		if nextDate.After(maxDate) {
-			return "", fmt.Errorf("已到达最新日期")
-		}
-
-		if !isWeekend(nextDate) {
-			return nextDate.Format("20060102"), nil
-		}
+	nextDate := nextTradingDay(market, currentDate)
+	if nextDate.After(maxDate) {
+		return "", fmt.Errorf("已到达最新日期")
 	}
-
-	// 如果7天内都是周末（理论上不可能），返回错误
-	return "", fmt.Errorf("无法找到下一个交易日")
+	return nextDate.Format("20060102"), nil
 }
 
 // formatDate 辅助函数: 格式化 YYYYMMDD → 可读日期
@@ -305,6 +283,7 @@ func formatDate(dateStr string) string {
 
 // createFixedTimeRange 创建固定的时间范围框架（9:30-15:00，共331个分钟点，包含午休）
 // 创建完整连续的时间轴，午休时段（11:30-13:00）也包含在内，用于正确的时间映射
+// 半日市只包含提前收盘之前的时段
 func (m *Model) createFixedTimeRange(date string, market MarketType) []TimePoint {
 	var marketConfig MarketConfig
 	switch market {
@@ -321,8 +300,8 @@ func (m *Model) createFixedTimeRange(date string, market MarketType) []TimePoint
 
 	points := make([]TimePoint, 0)
 
-	// 遍历所有交易时段
-	for _, session := range marketConfig.TradingSessions {
+	// 遍历当日的交易时段
+	for _, session := range tradingSessionsForDate(marketConfig.TradingSessions, market, date) {
 		startTime, err := parseTimeInMarket(date, session.StartTime, marketConfig)
 		if err != nil {
 			logDebug("log.chart.parseStartFail", session.StartTime, err)
@@ -425,6 +404,16 @@ func (m *Model) createIntradayChart(termWidth, termHeight int) *linechart.Model
 		actualPrices[i] = dp.Price
 	}
 
+	// 叠加的技术指标线也要落在纵轴范围内（布林带可能超出价格范围）
+	overlays := m.priceOverlays(intradayIndicatorSeries(m.chartData, timeFramework))
+	for _, overlay := range overlays {
+		for _, v := range overlay.Values {
+			if !math.IsNaN(v) {
+				actualPrices = append(actualPrices, v)
+			}
+		}
+	}
+
 	minPrice, maxPrice, margin := calculateAdaptiveMargin(actualPrices)
 
 	logDebug("log.chart.priceRange", minPrice, maxPrice, (maxPrice-minPrice)/minPrice*100, margin)
@@ -542,6 +531,21 @@ func (m *Model) createIntradayChart(termWidth, termHeight int) *linechart.Model
 		lc.DrawBrailleLineWithStyle(p1, p2, chartStyle)
 	}
 
+	// === 技术指标叠加线（跳过无法计算的位置） ===
+	for _, overlay := range overlays {
+		for i := 0; i < len(overlay.Values)-1; i++ {
+			if math.IsNaN(overlay.Values[i]) || math.IsNaN(overlay.Values[i+1]) {
+				continue
+			}
+			p1 := canvas.Float64Point{X: float64(i), Y: overlay.Values[i]}
+			p2 := canvas.Float64Point{X: float64(i + 1), Y: overlay.Values[i+1]}
+			lc.DrawBrailleLineWithStyle(p1, p2, overlay.Style)
+		}
+	}
+
+	// === 十字光标 ===
+	m.drawChartCursor(&lc, timeLabels, minPrice-margin, maxPrice+margin)
+
 	lc.DrawXYAxisAndLabel()
 
 	logDebug("log.chart.success")
@@ -581,9 +585,16 @@ func (m *Model) triggerIntradayDataCollection(code, name, date string) tea.Cmd {
 func (m *Model) handleIntradayChartViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "q":
+		// 显示光标时 ESC 先隐藏光标
+		if msg.String() == "esc" && m.chartCursorVisible {
+			m.chartCursorVisible = false
+			return m, nil
+		}
+
 		// 返回上一个状态
 		m.state = m.previousState
 		m.chartData = nil
+		m.chartCursorVisible = false
 
 		// 如果返回到 Monitoring 或 WatchlistViewing，需要重启定时器和数据更新
 		if m.previousState == Monitoring || m.previousState == WatchlistViewing {
@@ -600,8 +611,36 @@ func (m *Model) handleIntradayChartViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 
 		return m, nil
 
+	case "v":
+		// 切换成交量副图
+		m.chartHideVolume = !m.chartHideVolume
+		return m, nil
+
+	case "1", "2", "3", "4", "5", "6":
+		// 切换技术指标：1 MA、2 EMA、3 VWAP、4 布林带、5 MACD、6 RSI
+		m.toggleIndicatorKey(msg.String())
+		return m, nil
+
+	case "h", ",":
+		// 光标左移一分钟
+		m.moveChartCursor(-1)
+		return m, nil
+
+	case "l", ".":
+		// 光标右移一分钟
+		m.moveChartCursor(1)
+		return m, nil
+
+	case "H", "<":
+		m.moveChartCursor(-chartCursorJump)
+		return m, nil
+
+	case "L", ">":
+		m.moveChartCursor(chartCursorJump)
+		return m, nil
+
 	case "left":
-		// 导航到前一个交易日（跳过周末）
+		// 导航到前一个交易日（跳过周末和休市日）
 		if m.chartData != nil {
 			newDateStr := findPreviousTradingDay(m.chartViewStock, m.chartViewDate, m)
 
@@ -634,10 +673,10 @@ func (m *Model) handleIntradayChartViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		return m, nil
 
 	case "right":
-		// 导航到下一个交易日（跳过周末，最多到今天）
+		// 导航到下一个交易日（跳过周末和休市日，最多到今天）
 		if m.chartData != nil {
 			today := time.Now()
-			newDateStr, err := findNextTradingDay(m.chartViewDate, today)
+			newDateStr, err := findNextTradingDay(m.chartViewDate, getMarketType(m.chartViewStock), today)
 			if err != nil {
 				// 已经是最新日期或无法找到下一个交易日
 				m.chartLoadError = err
@@ -651,7 +690,7 @@ func (m *Model) handleIntradayChartViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 				// 最多再往后尝试10个交易日（但不超过今天）
 				found := false
 				for attempt := 0; attempt < 10; attempt++ {
-					newDateStr, err = findNextTradingDay(newDateStr, today)
+					newDateStr, err = findNextTradingDay(newDateStr, getMarketType(m.chartViewStock), today)
 					if err != nil {
 						break
 					}
@@ -804,8 +843,25 @@ func (m *Model) viewIntradayChart(termWidth, termHeight int) string {
 		return b.String()
 	}
 
-	// 创建图表
-	chartModel := m.createIntradayChart(termWidth, termHeight)
+	// 创建图表（有成交量数据或打开了指标副图时价格图让出副图的高度，终端太矮时不显示副图）
+	volumeRows := 0
+	if !m.chartHideVolume && hasVolumeData(m.chartData) {
+		volumeRows = intradayVolumeRows
+	}
+	timeFramework := m.createFixedTimeRange(m.chartData.Date, m.chartData.Market)
+	series := intradayIndicatorSeries(m.chartData, timeFramework)
+	panels := m.indicatorPanels(series)
+	cursorStatus := m.chartCursorStatus()
+	statusRows := 0
+	if cursorStatus != "" {
+		statusRows = 1
+	}
+	chartModel := m.createIntradayChart(termWidth, termHeight-statusRows-volumeRows-len(panels)*indicatorPanelRows)
+	if chartModel == nil && (volumeRows > 0 || len(panels) > 0) {
+		volumeRows = 0
+		panels = nil
+		chartModel = m.createIntradayChart(termWidth, termHeight-statusRows)
+	}
 	if chartModel == nil {
 		b.WriteString(m.getText("terminalTooSmall"))
 		b.WriteString("\n\n")
@@ -865,7 +921,7 @@ func (m *Model) viewIntradayChart(termWidth, termHeight int) string {
 		}
 	}
 
-	b.WriteString(statsStyle.Render(fmt.Sprintf(
+	stats := fmt.Sprintf(
 		"%s: %.2f  %s: %.2f  %s: %.2f  %s: %.2f  %s: %.2f  %s: %+.2f (%.2f%%)",
 		m.getText("prevClose"), prevClose,
 		m.getText("open"), prices[0],
@@ -873,17 +929,44 @@ func (m *Model) viewIntradayChart(termWidth, termHeight int) string {
 		m.getText("high"), maxPrice,
 		m.getText("low"), minPrice,
 		m.getText("change"), change, changePercent,
-	)))
-	b.WriteString("\n\n")
+	)
+	if hasVolumeData(m.chartData) {
+		stats += fmt.Sprintf("  %s: %s", m.getText("col.volume"), formatVolume(totalVolume(m.chartData)))
+	}
+	b.WriteString(statsStyle.Render(stats))
+	b.WriteString("\n")
+	if cursorStatus != "" {
+		b.WriteString(cursorStatus)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// 渲染图表
 	b.WriteString(chartModel.View())
+	if panel := m.renderVolumePanel(m.chartData, chartModel, volumeRows); panel != "" {
+		b.WriteString("\n")
+		b.WriteString(panel)
+	}
+	columns := frameworkColumns(len(timeFramework), chartModel.GraphWidth())
+	for _, indicatorPanel := range panels {
+		if panel := renderIndicatorPanel(indicatorPanel, columns, chartModel.Origin().X, indicatorPanelRows, isAShare); panel != "" {
+			b.WriteString("\n")
+			b.WriteString(panel)
+		}
+	}
+	if legend := m.indicatorLegend(m.priceOverlays(series), panels); legend != "" {
+		b.WriteString("\n")
+		b.WriteString(legend)
+	}
 	b.WriteString("\n\n")
 
 	// 底部操作提示
 	controls := fmt.Sprintf(
-		"[%s/%s] %s | [%s/%s] %s",
+		"[%s/%s] %s | [%s/%s] %s | [%s] %s | [%s] %s | [%s/%s] %s",
 		"←", "→", m.getText("changeDate"),
+		"H", "L", m.getText("moveCursor"),
+		"V", m.getText("toggleVolume"),
+		"1-6", m.getText("toggleIndicators"),
 		"ESC", "Q", m.getText("back"),
 	)
 	b.WriteString(lipgloss.NewStyle().
@@ -901,55 +984,67 @@ func (m *Model) viewIntradayChart(termWidth, termHeight int) string {
 // 特点：
 // 1. 5秒刷新间隔（高频）
 // 2. 只采集单只股票
-// 3. 数据存储在内存 (m.searchIntradayData)
+// 3. 数据通过 searchIntradayUpdateMsg 交给 Update 存入内存 (m.searchIntradayData)，worker 不读写 Model
 // 4. 不写入磁盘
 // 5. 首次立即执行
 func (m *Model) startSearchIntradayWorker(code, name, date string) tea.Cmd {
-	// 创建停止信号和更新通知 channel
-	m.searchIntradayWorker = make(chan struct{})
-	m.searchIntradayUpdateCh = make(chan struct{}, 10) // 带缓冲，避免阻塞
+	// 停止之前的 worker（重新打开搜索结果时）
+	if m.searchIntradayCancel != nil {
+		m.searchIntradayCancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan searchIntradayUpdateMsg, 1)
+	m.searchIntradayCancel = cancel
+	m.searchIntradayUpdateCh = updates
+
+	// 获取昨收价（用于图表颜色判断）
+	prevClose := 0.0
+	if m.searchResult != nil {
+		prevClose = m.searchResult.PrevClose
+	}
 
 	logDebug("log.search.workerStart", code, date)
 
-	// 启动临时 goroutine
-	go m.runSearchIntradayWorker(code, name, date)
+	// 启动临时 goroutine（所需数据在启动时复制，避免与主线程同时读写 Model）
+	go runSearchIntradayWorker(ctx, code, name, date, prevClose, m.config.Markets, updates)
 
 	// 启动监听更新的 cmd
-	return m.waitForSearchIntradayUpdate()
+	return waitForSearchIntradayUpdate(updates)
 }
 
-// runSearchIntradayWorker 运行搜索模式的高频临时 worker
-func (m *Model) runSearchIntradayWorker(code, name, date string) {
+// runSearchIntradayWorker 运行搜索模式的高频临时 worker，退出时关闭 updates
+func runSearchIntradayWorker(ctx context.Context, code, name, date string, prevClose float64, markets MarketsConfig, updates chan searchIntradayUpdateMsg) {
+	defer close(updates)
+
 	// 使用 5 秒间隔的 ticker（高频刷新）
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
 	// 首次立即执行数据获取（不等待第一个 tick）
-	m.fetchAndStoreSearchIntradayData(code, name, date)
+	if !fetchSearchIntradayData(ctx, code, name, date, prevClose, updates) {
+		return
+	}
 
 	// 定时采集循环
 	for {
 		select {
 		case <-ticker.C:
-			// 检查是否仍在搜索模式
-			if !m.isSearchMode || m.state != SearchResultWithActions {
-				logDebug("log.search.workerAutoStop", code)
-				return
-			}
-
 			// 检查市场是否开市（闭市时降低频率）
-			if !isMarketOpen(code, m) {
+			if !isStockMarketOpen(code, markets) {
 				logDebug("log.search.marketClosed", code)
 				// 市场关闭时仍然执行一次获取（获取当日完整数据）
 				// 然后停止 worker
-				m.fetchAndStoreSearchIntradayData(code, name, date)
+				fetchSearchIntradayData(ctx, code, name, date, prevClose, updates)
 				return
 			}
 
-			// 采集数据并更新内存
-			m.fetchAndStoreSearchIntradayData(code, name, date)
+			// 采集数据并发送给 UI
+			if !fetchSearchIntradayData(ctx, code, name, date, prevClose, updates) {
+				return
+			}
 
-		case <-m.searchIntradayWorker:
+		case <-ctx.Done():
 			// 收到停止信号
 			logDebug("log.search.workerStop", code)
 			return
@@ -957,66 +1052,59 @@ func (m *Model) runSearchIntradayWorker(code, name, date string) {
 	}
 }
 
-// fetchAndStoreSearchIntradayData 获取并存储搜索模式的分时数据（仅内存）
-func (m *Model) fetchAndStoreSearchIntradayData(code, name, date string) {
+// fetchSearchIntradayData 获取搜索模式的分时数据并发送给 UI（仅内存），worker 已停止时返回 false
+func fetchSearchIntradayData(ctx context.Context, code, name, date string, prevClose float64, updates chan searchIntradayUpdateMsg) bool {
 	// 从 API 获取最新数据
 	datapoints, err := fetchIntradayDataFromAPI(code)
 	if err != nil {
 		logDebug("log.search.fetchFail", code, err)
 		// 不返回错误，继续下次尝试
-		return
+		return ctx.Err() == nil
 	}
 
 	if len(datapoints) == 0 {
 		logDebug("log.search.noData", code)
-		return
-	}
-
-	// 获取市场类型
-	market := getMarketType(code)
-
-	// 获取昨收价（用于图表颜色判断）
-	prevClose := 0.0
-	if m.searchResult != nil {
-		prevClose = m.searchResult.PrevClose
+		return ctx.Err() == nil
 	}
 
 	// 直接使用新数据替换（不需要合并，每次都是完整数据）
-	m.searchIntradayData = &IntradayData{
+	data := &IntradayData{
 		Code:       code,
 		Name:       name,
 		Date:       date,
-		Market:     market,
+		Market:     getMarketType(code),
 		Datapoints: datapoints, // 直接使用新数据
 		UpdatedAt:  time.Now().Format("2006-01-02 15:04:05"),
 		PrevClose:  prevClose,
 	}
 
-	logDebug("log.search.dataUpdated", code, len(datapoints), time.Now().Format("15:04:05"))
+	// 发送更新消息，触发 UI 重新渲染（worker 停止后不再发送）
+	select {
+	case updates <- searchIntradayUpdateMsg{data: data, updates: updates}:
+		logDebug("log.search.dataUpdated", code, len(datapoints), time.Now().Format("15:04:05"))
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
 
-	// 发送更新通知，触发 UI 重新渲染
-	if m.searchIntradayUpdateCh != nil {
-		select {
-		case m.searchIntradayUpdateCh <- struct{}{}:
-			// 通知已发送
-		default:
-			// channel 满了，跳过（不阻塞）
-		}
+// handleSearchIntradayUpdate 保存 worker 获取的分时数据并继续监听（忽略已停止的 worker 发来的数据）
+func (m *Model) handleSearchIntradayUpdate(msg searchIntradayUpdateMsg) tea.Cmd {
+	if msg.updates != m.searchIntradayUpdateCh {
+		return nil
 	}
+	m.searchIntradayData = msg.data
+	return waitForSearchIntradayUpdate(msg.updates)
 }
 
 // stopSearchIntradayWorker 停止搜索模式的临时 worker
+// 不等待 worker 退出：进行中的请求结束后 worker 自行退出，之后的数据不再发送给 UI
 func (m *Model) stopSearchIntradayWorker() {
-	if m.searchIntradayWorker != nil {
-		close(m.searchIntradayWorker)
-		m.searchIntradayWorker = nil
-		logDebug("log.search.workerClosed")
-	}
-
-	// 关闭更新通知 channel
-	if m.searchIntradayUpdateCh != nil {
-		close(m.searchIntradayUpdateCh)
+	if m.searchIntradayCancel != nil {
+		m.searchIntradayCancel()
+		m.searchIntradayCancel = nil
 		m.searchIntradayUpdateCh = nil
+		logDebug("log.search.workerClosed")
 	}
 
 	// 清理内存数据
@@ -1243,18 +1331,14 @@ func (m *Model) createSearchIntradayChart(termWidth, termHeight int) *linechart.
 	return &lc
 }
 
-// waitForSearchIntradayUpdate 监听搜索模式数据更新通知
-func (m *Model) waitForSearchIntradayUpdate() tea.Cmd {
+// waitForSearchIntradayUpdate 监听搜索模式 worker 的数据更新
+func waitForSearchIntradayUpdate(updates <-chan searchIntradayUpdateMsg) tea.Cmd {
 	return func() tea.Msg {
-		// 阻塞等待 channel 消息
-		if m.searchIntradayUpdateCh != nil {
-			_, ok := <-m.searchIntradayUpdateCh
-			if ok {
-				// 收到更新通知，返回消息触发 UI 重新渲染
-				return searchIntradayUpdateMsg{}
-			}
+		// 阻塞等待 worker 消息，worker 退出后 channel 关闭，返回 nil
+		msg, ok := <-updates
+		if !ok {
+			return nil
 		}
-		// channel 已关闭，返回 nil
-		return nil
+		return msg
 	}
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -206,6 +207,30 @@ func TestCompareDatapoints(t *testing.T) {
 			expectedPriceCount: 1,
 			desc:               "即使微小的价格差异也应该被识别",
 		},
+		{
+			name: "旧文件补充成交量",
+			oldDatapoints: []IntradayDataPoint{
+				{Time: "09:30", Price: 100.0},
+			},
+			newDatapoints: []IntradayDataPoint{
+				{Time: "09:30", Price: 100.0, Volume: 1200, Turnover: 120000},
+			},
+			expectedNewCount:   0,
+			expectedPriceCount: 1,
+			desc:               "新增成交量字段应视为数据变化",
+		},
+		{
+			name: "备用数据源缺少成交量",
+			oldDatapoints: []IntradayDataPoint{
+				{Time: "09:30", Price: 100.0, Open: 99.8, High: 100.1, Low: 99.7, Volume: 1200},
+			},
+			newDatapoints: []IntradayDataPoint{
+				{Time: "09:30", Price: 100.0},
+			},
+			expectedNewCount:   0,
+			expectedPriceCount: 0,
+			desc:               "新数据源未提供的字段不应视为变化",
+		},
 	}
 
 	for _, tt := range tests {
@@ -326,3 +351,68 @@ func TestShouldSaveIntradayData(t *testing.T) {
 		})
 	}
 }
+
+// TestMergeDatapointsKeepsBars 测试合并时保留新数据源未提供的开高低价和成交量
+func TestMergeDatapointsKeepsBars(t *testing.T) {
+	existing := []IntradayDataPoint{
+		{Time: "09:30", Price: 100.0, Open: 99.8, High: 100.1, Low: 99.7, Volume: 1200, Turnover: 119900},
+	}
+	merged := mergeDatapoints(existing, []IntradayDataPoint{{Time: "09:30", Price: 100.3}, {Time: "09:31", Price: 100.4}})
+
+	if len(merged) != 2 {
+		t.Fatalf("merged = %+v", merged)
+	}
+	expected := IntradayDataPoint{Time: "09:30", Price: 100.3, Open: 99.8, High: 100.3, Low: 99.7, Volume: 1200, Turnover: 119900}
+	if merged[0] != expected {
+		t.Errorf("merged[0] = %+v, expected %+v", merged[0], expected)
+	}
+	if merged[1].HasOHLC() || merged[1].Volume != 0 {
+		t.Errorf("merged[1] = %+v, expected price only", merged[1])
+	}
+}
+
+// TestIntradayDataBackwardCompatible 测试旧格式文件可以读取，没有成交量时不写出新字段
+func TestIntradayDataBackwardCompatible(t *testing.T) {
+	var data IntradayData
+	old := `{"code":"SH600000","date":"20250314","datapoints":[{"time":"09:31","price":10.1}]}`
+	if err := json.Unmarshal([]byte(old), &data); err != nil {
+		t.Fatal(err)
+	}
+	if dp := data.Datapoints[0]; dp.Price != 10.1 || dp.HasOHLC() || dp.Volume != 0 {
+		t.Errorf("旧格式数据点 = %+v", dp)
+	}
+
+	encoded, _ := json.Marshal(IntradayDataPoint{Time: "09:31", Price: 10.1})
+	if string(encoded) != `{"time":"09:31","price":10.1}` {
+		t.Errorf("只有价格时编码为 %s", encoded)
+	}
+}
+
+// TestIntradayVolumeFromSources 测试各数据源的分钟成交量（腾讯为累计值，需要逐分钟相减）
+func TestIntradayVolumeFromSources(t *testing.T) {
+	useMockQuoteServer(t)
+
+	sources := map[string]func(string) ([]IntradayDataPoint, error){
+		"tencent":   tryGetIntradayFromTencent,
+		"eastmoney": tryGetIntradayFromEastMoney,
+		"sina":      tryGetIntradayFromSina,
+	}
+	for name, fetch := range sources {
+		datapoints, err := fetch("SH600000")
+		if err != nil || len(datapoints) < 2 {
+			t.Fatalf("%s: %d datapoints, err = %v", name, len(datapoints), err)
+		}
+		first, last := datapoints[0], datapoints[len(datapoints)-1]
+		if first.Volume <= 0 || first.Volume != last.Volume {
+			t.Errorf("%s: 分钟成交量 = %d / %d, expected equal and > 0", name, first.Volume, last.Volume)
+		}
+		if name != "tencent" && (!last.HasOHLC() || last.High < last.Price || last.Low > last.Price) {
+			t.Errorf("%s: 开高低价 = %+v", name, last)
+		}
+	}
+
+	datapoints, err := tryGetIntradayFromYahoo("AAPL")
+	if err != nil || len(datapoints) == 0 || !datapoints[0].HasOHLC() || datapoints[0].Volume <= 0 {
+		t.Errorf("yahoo: %+v, err = %v", datapoints[:min(len(datapoints), 1)], err)
+	}
+}
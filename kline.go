@@ -0,0 +1,553 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 日K线数据：获取、增量更新、复权
+// ============================================================================
+
+const (
+	dailyHistoryDays     = 730              // 首次获取的历史长度（日历天数）
+	dailyOverlapDays     = 10               // 增量更新时与本地数据重叠的天数（用于校准复权因子）
+	dailyRefreshInterval = 10 * time.Minute // 本地数据在此时间内更新过时不重新获取
+)
+
+// DailyBar 一个交易日的K线（价格为不复权的实际成交价）
+type DailyBar struct {
+	Date     string  `json:"date"` // YYYY-MM-DD（市场当地日期）
+	Open     float64 `json:"open"`
+	High     float64 `json:"high"`
+	Low      float64 `json:"low"`
+	Close    float64 `json:"close"`
+	Volume   int64   `json:"volume"`             // 成交量（A股为手，港美股为股）
+	Turnover float64 `json:"turnover,omitempty"` // 成交额（部分数据源没有）
+	Factor   float64 `json:"factor,omitempty"`   // 复权因子 = 复权价 / 实际价（比例任意，0 表示无复权信息）
+}
+
+// DailyData 单只股票的日K线文件 data/daily/<MARKET>/<CODE>.json
+type DailyData struct {
+	Code      string     `json:"code"`
+	Name      string     `json:"name"`
+	Market    MarketType `json:"market"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Bars      []DailyBar `json:"bars"` // 按日期升序
+}
+
+// PriceAdjustment 复权方式
+type PriceAdjustment string
+
+const (
+	PriceAdjustNone     PriceAdjustment = "none"     // 不复权
+	PriceAdjustForward  PriceAdjustment = "forward"  // 前复权（最新价格不变，调整历史价格）
+	PriceAdjustBackward PriceAdjustment = "backward" // 后复权（本地最早一根K线价格不变，调整之后的价格）
+)
+
+// priceAdjustments 复权方式切换顺序
+var priceAdjustments = []PriceAdjustment{PriceAdjustNone, PriceAdjustForward, PriceAdjustBackward}
+
+// normalizePriceAdjustment 验证复权方式（无效时使用前复权）
+func normalizePriceAdjustment(mode PriceAdjustment) (PriceAdjustment, bool) {
+	switch PriceAdjustment(strings.ToLower(string(mode))) {
+	case PriceAdjustNone:
+		return PriceAdjustNone, true
+	case PriceAdjustForward:
+		return PriceAdjustForward, true
+	case PriceAdjustBackward:
+		return PriceAdjustBackward, true
+	}
+	return PriceAdjustForward, false
+}
+
+// effectiveFactor 复权因子（没有复权信息时为 1）
+func (b DailyBar) effectiveFactor() float64 {
+	if b.Factor > 0 {
+		return b.Factor
+	}
+	return 1
+}
+
+// adjustDailyBars 按复权方式换算价格（返回新切片，不修改原数据）
+// 前复权以最新一根K线为基准，后复权以最早一根K线为基准
+func adjustDailyBars(bars []DailyBar, mode PriceAdjustment) []DailyBar {
+	if mode == PriceAdjustNone || len(bars) == 0 {
+		return bars
+	}
+
+	base := bars[len(bars)-1].effectiveFactor()
+	if mode == PriceAdjustBackward {
+		base = bars[0].effectiveFactor()
+	}
+
+	adjusted := make([]DailyBar, len(bars))
+	for i, bar := range bars {
+		ratio := bar.effectiveFactor() / base
+		bar.Open *= ratio
+		bar.High *= ratio
+		bar.Low *= ratio
+		bar.Close *= ratio
+		adjusted[i] = bar
+	}
+	return adjusted
+}
+
+// mergeDailyBars 合并本地和新获取的K线（同一日期以新数据为准）
+// 不同数据源或不同时间获取的复权因子比例可能不同，按最早的重叠日期把新数据的因子换算到本地比例；
+// 复权K线获取失败时新数据没有复权因子，已有日期保留本地因子，新日期沿用上一个已知因子
+func mergeDailyBars(existing, fetched []DailyBar) []DailyBar {
+	if len(existing) == 0 {
+		return fetched
+	}
+	if len(fetched) == 0 {
+		return existing
+	}
+
+	byDate := make(map[string]DailyBar, len(existing)+len(fetched))
+	for _, bar := range existing {
+		byDate[bar.Date] = bar
+	}
+
+	scale := 1.0
+	for _, bar := range fetched {
+		if old, exists := byDate[bar.Date]; exists && old.Factor > 0 && bar.Factor > 0 {
+			scale = old.Factor / bar.Factor
+			break
+		}
+	}
+	for _, bar := range fetched {
+		if bar.Factor > 0 {
+			bar.Factor *= scale
+		} else if old, exists := byDate[bar.Date]; exists {
+			bar.Factor = old.Factor
+		}
+		byDate[bar.Date] = bar
+	}
+
+	merged := make([]DailyBar, 0, len(byDate))
+	for _, bar := range byDate {
+		merged = append(merged, bar)
+	}
+	slices.SortFunc(merged, func(a, b DailyBar) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	// 同一序列中不能混用有无复权因子的K线（0 会按 1 计算）
+	last := 0.0
+	for i := range merged {
+		if merged[i].Factor > 0 {
+			last = merged[i].Factor
+		} else {
+			merged[i].Factor = last
+		}
+	}
+	return merged
+}
+
+// withAdjustmentFactors 用同一数据源的复权K线计算实际K线的复权因子（按日期对应）
+func withAdjustmentFactors(raw, adjusted []DailyBar) []DailyBar {
+	closes := make(map[string]float64, len(adjusted))
+	for _, bar := range adjusted {
+		closes[bar.Date] = bar.Close
+	}
+	for i, bar := range raw {
+		if adjustedClose, exists := closes[bar.Date]; exists && bar.Close > 0 && adjustedClose > 0 {
+			raw[i].Factor = adjustedClose / bar.Close
+		}
+	}
+	return raw
+}
+
+// ============================================================================
+// 本地存储
+// ============================================================================
+
+// getDailyFilePath 日K线文件路径 data/daily/<MARKET>/<CODE>.json
+func getDailyFilePath(code string) string {
+	return filepath.Join("data", "daily", getMarketDirectory(code), code+".json")
+}
+
+// loadDailyData 加载本地日K线（文件不存在时返回 os.ErrNotExist）
+func loadDailyData(code string) (*DailyData, error) {
+	content, err := os.ReadFile(getDailyFilePath(code))
+	if err != nil {
+		return nil, err
+	}
+	var data DailyData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", getDailyFilePath(code), err)
+	}
+	if data.Market == "" {
+		data.Market = getMarketType(code)
+	}
+	return &data, nil
+}
+
+// saveDailyData 保存日K线（先写临时文件再重命名）
+func saveDailyData(data *DailyData) error {
+	filePath := getDailyFilePath(data.Code)
+	lock := getFileLock(filePath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempPath := filePath + ".tmp"
+	if err := os.WriteFile(tempPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, filePath)
+}
+
+// updateDailyData 增量更新本地日K线：只获取最后一根K线之后（含少量重叠）的数据
+// 获取失败时返回本地数据（可能为 nil）和错误
+func updateDailyData(code, name string, now time.Time) (*DailyData, error) {
+	data, err := loadDailyData(code)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logWarn("log.kline.loadFail", code, err)
+	}
+
+	days := dailyHistoryDays
+	if data != nil && len(data.Bars) > 0 {
+		if last, err := time.Parse("2006-01-02", data.Bars[len(data.Bars)-1].Date); err == nil {
+			days = min(int(now.Sub(last).Hours()/24)+dailyOverlapDays, dailyHistoryDays)
+		}
+	}
+
+	bars, err := fetchDailyBars(code, days)
+	if err != nil {
+		return data, err
+	}
+
+	if data == nil {
+		data = &DailyData{Code: code, Market: getMarketType(code)}
+	}
+	if name != "" {
+		data.Name = name
+	}
+	data.Bars = mergeDailyBars(data.Bars, bars)
+	data.UpdatedAt = now
+	if err := saveDailyData(data); err != nil {
+		logWarn("log.kline.saveFail", code, err)
+	} else {
+		logDebug("log.kline.saved", code, len(bars), len(data.Bars))
+	}
+	return data, nil
+}
+
+// ============================================================================
+// 数据源
+// ============================================================================
+
+// dailySource 日K线数据源
+type dailySource struct {
+	name  string
+	fetch func(code string, days int) ([]DailyBar, error)
+}
+
+// dailySourcesForMarket 各市场的日K线数据源顺序（与分时数据一致）
+func dailySourcesForMarket(market MarketType) []dailySource {
+	tencent := dailySource{"Tencent", tryGetDailyFromTencent}
+	eastMoney := dailySource{"EastMoney", tryGetDailyFromEastMoney}
+	yahoo := dailySource{"Yahoo", tryGetDailyFromYahoo}
+
+	switch market {
+	case MarketUS:
+		return []dailySource{yahoo}
+	case MarketHongKong:
+		return []dailySource{tencent, yahoo, eastMoney}
+	default:
+		return []dailySource{tencent, eastMoney}
+	}
+}
+
+// fetchDailyBars 按数据源顺序获取最近 days 个日历日的日K线
+func fetchDailyBars(code string, days int) ([]DailyBar, error) {
+	var lastErr error
+	for _, source := range dailySourcesForMarket(getMarketType(code)) {
+		bars, err := source.fetch(code, days)
+		if err == nil && len(bars) > 0 {
+			logDebug("log.kline.sourceSuccess", source.name, code, len(bars))
+			return bars, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("no data")
+		}
+		lastErr = err
+		logDebug("log.kline.sourceFail", source.name, code, err)
+	}
+	return nil, fmt.Errorf("all daily K-line sources failed for %s: %w", code, lastErr)
+}
+
+// newKLineRequest 创建带浏览器请求头的 GET 请求
+func newKLineRequest(url, referer string) (*http.Request, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	if referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+	return req, nil
+}
+
+// tryGetDailyFromTencent 腾讯日K线（分别获取不复权和后复权数据计算复权因子）
+func tryGetDailyFromTencent(code string, days int) ([]DailyBar, error) {
+	raw, err := fetchTencentKLine(code, days, "")
+	if err != nil {
+		return nil, err
+	}
+	adjusted, err := fetchTencentKLine(code, days, "hfq")
+	if err != nil {
+		logDebug("log.kline.factorFail", "Tencent", code, err)
+		return raw, nil
+	}
+	return withAdjustmentFactors(raw, adjusted), nil
+}
+
+// fetchTencentKLine 腾讯K线接口，fq 为 ""(不复权)、"qfq" 或 "hfq"
+// 响应: {"code":0,"data":{"sh600000":{"day":[["2025-03-14","开","收","高","低","量"],...]}}}
+func fetchTencentKLine(code string, days int, fq string) ([]DailyBar, error) {
+	tencentCode := convertStockCodeForTencent(code)
+	url := fmt.Sprintf("%s/appstock/app/fqkline/get?param=%s,day,,,%d,%s",
+		apiEndpoints.TencentMinute, tencentCode, days, fq)
+
+	req, err := newKLineRequest(url, "https://gu.qq.com")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fetchWithRetry(&http.Client{Timeout: 10 * time.Second}, req, 2)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tencentResp struct {
+		Code int                                   `json:"code"`
+		Msg  string                                `json:"msg"`
+		Data map[string]map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tencentResp); err != nil {
+		return nil, err
+	}
+	if tencentResp.Code != 0 {
+		return nil, fmt.Errorf("API error code %d: %s", tencentResp.Code, tencentResp.Msg)
+	}
+
+	stockData, exists := tencentResp.Data[tencentCode]
+	if !exists {
+		return nil, fmt.Errorf("no data for %s", tencentCode)
+	}
+	rowsJSON, exists := stockData[fq+"day"]
+	if !exists {
+		return nil, fmt.Errorf("no %sday data for %s", fq, tencentCode)
+	}
+	// 除权日的行末尾带有分红送转信息对象，只取前 6 个字符串字段
+	var rows [][]any
+	if err := json.Unmarshal(rowsJSON, &rows); err != nil {
+		return nil, err
+	}
+
+	bars := make([]DailyBar, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			continue
+		}
+		fields := make([]string, 6)
+		for i := range fields {
+			fields[i], _ = row[i].(string)
+		}
+		if bar, ok := parseDailyFields(fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]); ok {
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
+
+// tryGetDailyFromEastMoney 东方财富日K线（fqt=0 不复权，fqt=2 后复权）
+func tryGetDailyFromEastMoney(code string, days int) ([]DailyBar, error) {
+	raw, err := fetchEastMoneyKLine(code, days, 0)
+	if err != nil {
+		return nil, err
+	}
+	adjusted, err := fetchEastMoneyKLine(code, days, 2)
+	if err != nil {
+		logDebug("log.kline.factorFail", "EastMoney", code, err)
+		return raw, nil
+	}
+	return withAdjustmentFactors(raw, adjusted), nil
+}
+
+// fetchEastMoneyKLine 东方财富K线接口
+// 响应: {"data":{"klines":["2025-03-14,开,收,高,低,量,额",...]}}
+func fetchEastMoneyKLine(code string, days int, fqt int) ([]DailyBar, error) {
+	url := fmt.Sprintf(
+		"%s/api/qt/stock/kline/get?secid=%s&fields1=f1,f2,f3&fields2=f51,f52,f53,f54,f55,f56,f57&klt=101&fqt=%d&end=20500101&lmt=%d",
+		apiEndpoints.EastMoneyHistory, convertStockCodeForEastMoney(code), fqt, days,
+	)
+
+	req, err := newKLineRequest(url, "https://www.eastmoney.com")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fetchWithRetry(&http.Client{Timeout: 10 * time.Second}, req, 2)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var emData struct {
+		Data *struct {
+			Klines []string `json:"klines"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&emData); err != nil {
+		return nil, err
+	}
+	if emData.Data == nil {
+		return nil, fmt.Errorf("no kline data")
+	}
+
+	bars := make([]DailyBar, 0, len(emData.Data.Klines))
+	for _, line := range emData.Data.Klines {
+		parts := strings.Split(line, ",")
+		if len(parts) < 6 {
+			continue
+		}
+		bar, ok := parseDailyFields(parts[0], parts[1], parts[2], parts[3], parts[4], parts[5])
+		if !ok {
+			continue
+		}
+		if len(parts) >= 7 {
+			bar.Turnover, _ = strconv.ParseFloat(parts[6], 64)
+		}
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
+
+// tryGetDailyFromYahoo Yahoo Finance 日K线（close 已按拆股调整，adjclose 额外按分红调整）
+func tryGetDailyFromYahoo(code string, days int) ([]DailyBar, error) {
+	now := time.Now()
+	url := fmt.Sprintf("%s/v8/finance/chart/%s?interval=1d&period1=%d&period2=%d&events=div,split",
+		apiEndpoints.Yahoo, convertStockCodeForYahoo(code), now.AddDate(0, 0, -days).Unix(), now.Unix())
+
+	req, err := newKLineRequest(url, "")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := fetchWithRetry(&http.Client{Timeout: 10 * time.Second}, req, 2)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var yahooResp struct {
+		Chart struct {
+			Result []struct {
+				Timestamp  []int64 `json:"timestamp"`
+				Indicators struct {
+					Quote []struct {
+						Open   []float64 `json:"open"`
+						High   []float64 `json:"high"`
+						Low    []float64 `json:"low"`
+						Close  []float64 `json:"close"`
+						Volume []int64   `json:"volume"`
+					} `json:"quote"`
+					AdjClose []struct {
+						AdjClose []float64 `json:"adjclose"`
+					} `json:"adjclose"`
+				} `json:"indicators"`
+			} `json:"result"`
+			Error *struct {
+				Code        string `json:"code"`
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"chart"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&yahooResp); err != nil {
+		return nil, err
+	}
+	if yahooResp.Chart.Error != nil {
+		return nil, fmt.Errorf("Yahoo API error: %s - %s", yahooResp.Chart.Error.Code, yahooResp.Chart.Error.Description)
+	}
+	if len(yahooResp.Chart.Result) == 0 || len(yahooResp.Chart.Result[0].Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("no data in Yahoo response")
+	}
+
+	result := yahooResp.Chart.Result[0]
+	quote := result.Indicators.Quote[0]
+	var adjCloses []float64
+	if len(result.Indicators.AdjClose) > 0 {
+		adjCloses = result.Indicators.AdjClose[0].AdjClose
+	}
+	location, err := getMarketLocation(getMarketType(code))
+	if err != nil {
+		location = time.Local
+	}
+
+	// valueAt 取数组中的值（Yahoo 对缺失的日期返回 null，解析为 0）
+	valueAt := func(values []float64, i int) float64 {
+		if i < len(values) {
+			return values[i]
+		}
+		return 0
+	}
+
+	bars := make([]DailyBar, 0, len(result.Timestamp))
+	for i, timestamp := range result.Timestamp {
+		bar := DailyBar{
+			Date:  time.Unix(timestamp, 0).In(location).Format("2006-01-02"),
+			Open:  valueAt(quote.Open, i),
+			High:  valueAt(quote.High, i),
+			Low:   valueAt(quote.Low, i),
+			Close: valueAt(quote.Close, i),
+		}
+		if bar.Close == 0 {
+			continue
+		}
+		if i < len(quote.Volume) {
+			bar.Volume = quote.Volume[i]
+		}
+		if adjClose := valueAt(adjCloses, i); adjClose > 0 {
+			bar.Factor = adjClose / bar.Close
+		}
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
+
+// parseDailyFields 解析 日期,开,收,高,低,量 字段（成交量可能带小数）
+func parseDailyFields(date, open, closePrice, high, low, volume string) (DailyBar, bool) {
+	bar := DailyBar{Date: date}
+	var err error
+	if bar.Close, err = strconv.ParseFloat(closePrice, 64); err != nil || bar.Close == 0 {
+		return bar, false
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return bar, false
+	}
+	bar.Open, _ = strconv.ParseFloat(open, 64)
+	bar.High, _ = strconv.ParseFloat(high, 64)
+	bar.Low, _ = strconv.ParseFloat(low, 64)
+	if v, err := strconv.ParseFloat(volume, 64); err == nil {
+		bar.Volume = int64(v)
+	}
+	return bar, true
+}
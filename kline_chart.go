@@ -0,0 +1,488 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// 日K线图（蜡烛图）
+// ============================================================================
+
+// klineZoomLevels 可见K线数量（0 表示全部），↑/↓ 切换
+var klineZoomLevels = []int{20, 60, 120, 250, 500, 0}
+
+const (
+	klineDefaultZoom  = 1 // 默认显示最近 60 根
+	klineVolumeRows   = 3 // 成交量副图行数
+	klineLabelSpacing = 12
+)
+
+// dailyDataUpdateMsg 日K线后台更新完成消息
+type dailyDataUpdateMsg struct {
+	Code string
+	Data *DailyData
+	Err  error
+}
+
+// updateDailyDataCmd 后台增量更新日K线
+func updateDailyDataCmd(code, name string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := updateDailyData(code, name, time.Now())
+		return dailyDataUpdateMsg{Code: code, Data: data, Err: err}
+	}
+}
+
+// enterKLineViewing 打开股票的日K线图：先显示本地数据，数据过期时后台增量更新
+func (m *Model) enterKLineViewing(code, name string) tea.Cmd {
+	logInfo("log.action.enterKLine", code)
+	m.previousState = m.state
+	m.state = KLineViewing
+	m.klineStock = code
+	m.klineStockName = name
+	m.klineError = nil
+	m.klineAdjustment = m.config.KLine.Adjustment
+	m.klineZoom = klineDefaultZoom
+	m.klineOffset = 0
+	m.message = ""
+
+	data, err := loadDailyData(code)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logWarn("log.kline.loadFail", code, err)
+	}
+	m.klineData = data
+	if data != nil && time.Since(data.UpdatedAt) < dailyRefreshInterval {
+		return nil
+	}
+	m.klineUpdating = true
+	return updateDailyDataCmd(code, name)
+}
+
+// handleDailyDataUpdate 应用后台更新结果（已离开该股票的K线图时忽略）
+func (m *Model) handleDailyDataUpdate(msg dailyDataUpdateMsg) {
+	if m.state != KLineViewing || msg.Code != m.klineStock {
+		return
+	}
+	m.klineUpdating = false
+	m.klineError = msg.Err
+	if msg.Err != nil {
+		logWarn("log.kline.updateFail", msg.Code, msg.Err)
+	}
+	if msg.Data != nil {
+		m.klineData = msg.Data
+	}
+	m.clampKLineOffset()
+}
+
+// klineVisibleCount 当前缩放级别下可见的日K线数量
+func (m *Model) klineVisibleCount() int {
+	if m.klineData == nil {
+		return 0
+	}
+	total := len(m.klineData.Bars)
+	if level := klineZoomLevels[m.klineZoom]; level > 0 {
+		return min(level, total)
+	}
+	return total
+}
+
+// clampKLineOffset 保证可见窗口不超出数据范围
+func (m *Model) clampKLineOffset() {
+	if m.klineData == nil {
+		m.klineOffset = 0
+		return
+	}
+	m.klineOffset = max(min(m.klineOffset, len(m.klineData.Bars)-m.klineVisibleCount()), 0)
+}
+
+// visibleDailyBars 当前窗口内按复权方式换算后的日K线
+// 复权基准（最新/最早一根）取全部本地数据，平移窗口时价格保持一致
+func (m *Model) visibleDailyBars() []DailyBar {
+	if m.klineData == nil || len(m.klineData.Bars) == 0 {
+		return nil
+	}
+	bars := adjustDailyBars(m.klineData.Bars, m.klineAdjustment)
+	end := len(bars) - m.klineOffset
+	start := max(end-m.klineVisibleCount(), 0)
+	return bars[start:end]
+}
+
+func (m *Model) handleKLineViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.state = m.previousState
+		m.klineData = nil
+		m.klineUpdating = false
+		m.message = ""
+		// 返回持股或自选列表时重启定时器并立即刷新股价
+		if m.state == Monitoring || m.state == WatchlistViewing {
+			m.lastUpdate = time.Now()
+			cmds := []tea.Cmd{m.tickCmd()}
+			if stockPriceCmd := m.startStockPriceUpdates(); stockPriceCmd != nil {
+				cmds = append(cmds, stockPriceCmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+	case "up", "k", "w":
+		// 放大：显示更少的K线
+		if m.klineZoom > 0 {
+			m.klineZoom--
+			m.clampKLineOffset()
+		}
+	case "down", "j", "s":
+		// 缩小：显示更多的K线
+		if m.klineZoom < len(klineZoomLevels)-1 {
+			m.klineZoom++
+			m.clampKLineOffset()
+		}
+	case "left", "h":
+		// 向更早的日期平移四分之一个窗口
+		m.klineOffset += max(m.klineVisibleCount()/4, 1)
+		m.clampKLineOffset()
+	case "right", "l":
+		m.klineOffset = max(m.klineOffset-max(m.klineVisibleCount()/4, 1), 0)
+	case "f":
+		// 切换复权方式（仅本次查看有效，默认值见配置 kline.adjustment）
+		for i, mode := range priceAdjustments {
+			if mode == m.klineAdjustment {
+				m.klineAdjustment = priceAdjustments[(i+1)%len(priceAdjustments)]
+				break
+			}
+		}
+	case "1", "2", "3", "4", "5", "6":
+		// 切换技术指标（与分时图共用开关）
+		m.toggleIndicatorKey(msg.String())
+	case "r":
+		if !m.klineUpdating {
+			m.klineUpdating = true
+			m.klineError = nil
+			return m, updateDailyDataCmd(m.klineStock, m.klineStockName)
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) viewKLineViewing(termWidth, termHeight int) string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("14")). // 青色
+		Render(fmt.Sprintf("🕯️ %s - %s (%s) [%s]", m.getText("kline.title"),
+			m.klineStock, m.klineStockName, m.getText("kline.adjust."+string(m.klineAdjustment)))))
+	b.WriteString("\n\n")
+
+	help := lipgloss.NewStyle().Faint(true).Render(m.getText("kline.help"))
+	status := m.klineStatusLine()
+
+	bars := m.visibleDailyBars()
+	if len(bars) == 0 {
+		if m.klineUpdating {
+			b.WriteString(m.getText("kline.loading") + "\n\n")
+		} else {
+			b.WriteString(m.getText("kline.empty") + "\n\n")
+			if status != "" {
+				b.WriteString(status + "\n\n")
+			}
+		}
+		b.WriteString(help)
+		return b.String()
+	}
+
+	// 窗口和统计信息（按日计算，不受合并K线影响）
+	first, last := bars[0], bars[len(bars)-1]
+	high, low := last.High, last.Low
+	for _, bar := range bars {
+		high = math.Max(high, bar.High)
+		low = math.Min(low, bar.Low)
+	}
+	isAShare := strings.HasPrefix(m.klineStock, "SH") || strings.HasPrefix(m.klineStock, "SZ")
+	formatChange := func(from, to float64) string {
+		if from <= 0 {
+			return "-"
+		}
+		percent := (to - from) / from * 100
+		return trendStyle(isAShare, percent >= 0).Render(fmt.Sprintf("%+.2f%%", percent))
+	}
+	dayChange := "-"
+	if len(bars) > 1 {
+		dayChange = formatChange(bars[len(bars)-2].Close, last.Close)
+	}
+
+	window := fmt.Sprintf(m.getText("kline.window"), first.Date, last.Date, len(bars))
+	b.WriteString(window + "\n")
+	b.WriteString(fmt.Sprintf(m.getText("kline.stats"),
+		last.Close, dayChange, formatChange(first.Open, last.Close), high, low, formatVolume(last.Volume)) + "\n\n")
+
+	overlays, panels := m.klineIndicators()
+	chartHeight := max(termHeight-14-klineVolumeRows-len(panels)*indicatorPanelRows, 8)
+	chart, volume, layout := renderCandlestickChart(bars, overlays, max(termWidth-4, 40), chartHeight, klineVolumeRows, isAShare)
+	b.WriteString(chart + "\n")
+	if volume != "" {
+		b.WriteString(volume + "\n")
+	}
+	for _, indicatorPanel := range panels {
+		if panel := renderIndicatorPanel(indicatorPanel, layout.columnValues, layout.origin, indicatorPanelRows, isAShare); panel != "" {
+			b.WriteString(panel + "\n")
+		}
+	}
+	if legend := m.indicatorLegend(overlays, panels); legend != "" {
+		b.WriteString(legend + "\n")
+	}
+	if layout.perCandle > 1 {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).
+			Render(fmt.Sprintf(m.getText("kline.aggregated"), layout.perCandle)) + "\n")
+	}
+
+	if status != "" {
+		b.WriteString("\n" + status)
+	}
+	b.WriteString("\n" + help)
+	return b.String()
+}
+
+// klineIndicators 在全部本地数据上计算技术指标（窗口开头不受均线预热期影响），再截取当前可见窗口
+// VWAP 从窗口第一天开始累计
+func (m *Model) klineIndicators() ([]indicatorLine, []indicatorPanel) {
+	if m.klineData == nil || len(m.klineData.Bars) == 0 {
+		return nil, nil
+	}
+	bars := adjustDailyBars(m.klineData.Bars, m.klineAdjustment)
+	series := dailyIndicatorSeries(bars)
+	overlays, panels := m.priceOverlays(series), m.indicatorPanels(series)
+
+	end := len(bars) - m.klineOffset
+	start := max(end-m.klineVisibleCount(), 0)
+	window := func(values []float64) []float64 {
+		if values == nil {
+			return nil
+		}
+		return values[start:end]
+	}
+	for i := range overlays {
+		if overlays[i].Indicator == IndicatorVWAP {
+			overlays[i].Values = volumeWeightedAveragePrice(window(series.Typical), window(series.Volume))
+		} else {
+			overlays[i].Values = window(overlays[i].Values)
+		}
+	}
+	for i := range panels {
+		panels[i].Histogram = window(panels[i].Histogram)
+		for j := range panels[i].Lines {
+			panels[i].Lines[j].Values = window(panels[i].Lines[j].Values)
+		}
+	}
+	return overlays, panels
+}
+
+// klineStatusLine 更新状态（更新中 / 更新失败 / 最后更新时间）
+func (m *Model) klineStatusLine() string {
+	switch {
+	case m.klineUpdating:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(m.getText("kline.updating"))
+	case m.klineError != nil:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(fmt.Sprintf(m.getText("kline.updateFail"), m.klineError))
+	case m.klineData != nil && !m.klineData.UpdatedAt.IsZero():
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).
+			Render(fmt.Sprintf(m.getText("kline.updatedAt"), m.klineData.UpdatedAt.Format("2006-01-02 15:04")))
+	}
+	return ""
+}
+
+// aggregateDailyBars K线数量超过可用列数时，从最新一根向前每 n 根合并为一根（开盘取最早，收盘取最新）
+func aggregateDailyBars(bars []DailyBar, columns int) ([]DailyBar, int) {
+	if columns <= 0 || len(bars) <= columns {
+		return bars, 1
+	}
+	n := (len(bars) + columns - 1) / columns
+
+	groups := make([]DailyBar, 0, columns)
+	for end := len(bars); end > 0; end -= n {
+		start := max(end-n, 0)
+		group := bars[start]
+		for _, bar := range bars[start+1 : end] {
+			group.High = math.Max(group.High, bar.High)
+			group.Low = math.Min(group.Low, bar.Low)
+			group.Close = bar.Close
+			group.Volume += bar.Volume
+			group.Turnover += bar.Turnover
+		}
+		groups = append(groups, group)
+	}
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	return groups, n
+}
+
+// candleLayout 蜡烛图的横向布局，副图和指标线按它与蜡烛对齐
+type candleLayout struct {
+	origin     int   // 纵轴标签宽度
+	graphWidth int   // 绘图区列数
+	step       int   // 每根蜡烛占的列数
+	perCandle  int   // 每根蜡烛合并的交易日数
+	lastIndex  []int // 每根蜡烛最后一个交易日在输入数据中的索引
+}
+
+// column 第 i 根蜡烛所在的列
+func (l candleLayout) column(i int) int {
+	return i*l.step + (l.step-1)/2
+}
+
+// columnValues 把与输入日K线对齐的序列换算为绘图区每列的值：蜡烛所在列取该蜡烛最后一个交易日的值，蜡烛之间线性插值
+func (l candleLayout) columnValues(values []float64) []float64 {
+	columns := nanSeries(l.graphWidth)
+	for i, index := range l.lastIndex {
+		column := l.column(i)
+		if column >= l.graphWidth || index >= len(values) {
+			break
+		}
+		columns[column] = values[index]
+		if i == 0 || math.IsNaN(values[index]) || math.IsNaN(values[l.lastIndex[i-1]]) {
+			continue
+		}
+		previousColumn, previous := l.column(i-1), values[l.lastIndex[i-1]]
+		for c := previousColumn + 1; c < column; c++ {
+			columns[c] = previous + (values[index]-previous)*float64(c-previousColumn)/float64(column-previousColumn)
+		}
+	}
+	return columns
+}
+
+// renderCandlestickChart 绘制蜡烛图（实体 ┃、影线 │、十字星 ━）、指标叠加线（·）、日期轴和成交量副图
+// overlays 与 bars 按日对齐；返回价格图、成交量副图（没有成交量时为空）和横向布局
+func renderCandlestickChart(bars []DailyBar, overlays []indicatorLine, width, height, volumeRows int, isAShare bool) (string, string, candleLayout) {
+	// 纵轴范围包含指标线（布林带可能超出价格范围）
+	prices := make([]float64, 0, len(bars)*2)
+	for _, bar := range bars {
+		prices = append(prices, bar.High, bar.Low)
+	}
+	for _, overlay := range overlays {
+		for _, v := range overlay.Values {
+			if !math.IsNaN(v) {
+				prices = append(prices, v)
+			}
+		}
+	}
+	minPrice, maxPrice, margin := calculateAdaptiveMargin(prices)
+	top, bottom := maxPrice+margin, minPrice-margin
+	labelFormat := "%.2f"
+	if maxPrice < 10 {
+		labelFormat = "%.3f"
+	}
+	origin := max(len(fmt.Sprintf(labelFormat, top)), len(fmt.Sprintf(labelFormat, bottom))) + 1
+
+	graphWidth := max(width-origin-1, 1)
+	candles, perCandle := aggregateDailyBars(bars, graphWidth)
+	layout := candleLayout{
+		origin:     origin,
+		graphWidth: graphWidth,
+		step:       max(graphWidth/len(candles), 1),
+		perCandle:  perCandle,
+		lastIndex:  make([]int, len(candles)),
+	}
+	for i := range candles {
+		layout.lastIndex[i] = len(bars) - 1 - (len(candles)-1-i)*perCandle
+	}
+	candleColumn := layout.column
+	overlayColumns := make([][]float64, len(overlays))
+	for i, overlay := range overlays {
+		overlayColumns[i] = layout.columnValues(overlay.Values)
+	}
+
+	rowHeight := (top - bottom) / float64(height)
+	axisStyle := lipgloss.NewStyle()
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	var b strings.Builder
+	for r := range height {
+		rowTop := top - float64(r)*rowHeight
+		rowBottom := rowTop - rowHeight
+
+		if r%4 == 0 || r == height-1 {
+			b.WriteString(labelStyle.Render(fmt.Sprintf("%*s", origin, fmt.Sprintf(labelFormat, rowTop-rowHeight/2))))
+			b.WriteString(axisStyle.Render("┤"))
+		} else {
+			b.WriteString(strings.Repeat(" ", origin) + axisStyle.Render("│"))
+		}
+
+		cells := make([]string, graphWidth)
+		for i := range cells {
+			cells[i] = " "
+		}
+		for i, candle := range candles {
+			open := candle.Open
+			if open <= 0 {
+				open = candle.Close
+			}
+			bodyHigh, bodyLow := math.Max(open, candle.Close), math.Min(open, candle.Close)
+
+			var cell string
+			switch {
+			case bodyLow <= rowTop && bodyHigh >= rowBottom && bodyHigh-bodyLow < rowHeight/4:
+				cell = "━"
+			case bodyLow <= rowTop && bodyHigh >= rowBottom:
+				cell = "┃"
+			case candle.Low <= rowTop && candle.High >= rowBottom:
+				cell = "│"
+			default:
+				continue
+			}
+			if column := candleColumn(i); column < graphWidth {
+				cells[column] = trendStyle(isAShare, candle.Close >= open).Render(cell)
+			}
+		}
+		// 指标线画在蜡烛的空隙中
+		for i, values := range overlayColumns {
+			for column, v := range values {
+				if !math.IsNaN(v) && v <= rowTop && v > rowBottom && cells[column] == " " {
+					cells[column] = overlays[i].Style.Render("·")
+				}
+			}
+		}
+		b.WriteString(strings.Join(cells, "") + "\n")
+	}
+
+	// 日期轴：标签间隔至少 klineLabelSpacing 列，跨度超过一年时显示年月
+	b.WriteString(strings.Repeat(" ", origin) + axisStyle.Render("└"+strings.Repeat("─", graphWidth)) + "\n")
+	dateFormat := func(date string) string { return date[5:] } // MM-DD
+	if first, err := time.Parse("2006-01-02", candles[0].Date); err == nil {
+		if last, err := time.Parse("2006-01-02", candles[len(candles)-1].Date); err == nil && last.Sub(first) > 365*24*time.Hour {
+			dateFormat = func(date string) string { return date[:7] } // YYYY-MM
+		}
+	}
+	labels := []rune(strings.Repeat(" ", origin+1+graphWidth))
+	nextFree := 0
+	for i, candle := range candles {
+		position := origin + 1 + candleColumn(i)
+		label := []rune(dateFormat(candle.Date))
+		if position < nextFree || position+len(label) > len(labels) {
+			continue
+		}
+		copy(labels[position:], label)
+		nextFree = position + max(len(label)+1, klineLabelSpacing)
+	}
+	b.WriteString(labelStyle.Render(strings.TrimRight(string(labels), " ")))
+
+	// 成交量副图与蜡烛对齐
+	columns := make([]volumeColumn, graphWidth)
+	for i, candle := range candles {
+		if column := candleColumn(i); column < graphWidth {
+			open := candle.Open
+			if open <= 0 {
+				open = candle.Close
+			}
+			columns[column] = volumeColumn{volume: candle.Volume, up: candle.Close >= open}
+		}
+	}
+	volume := renderVolumeBars(columns, origin, volumeRows, isAShare)
+
+	return b.String(), volume, layout
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAdjustDailyBars(t *testing.T) {
+	// 第三天除权（后复权因子从 1 变为 1.02），实际价格下跌但复权价格连续
+	bars := []DailyBar{
+		{Date: "2025-03-12", Open: 10.2, High: 10.4, Low: 10.1, Close: 10.2, Factor: 1},
+		{Date: "2025-03-13", Open: 10.2, High: 10.3, Low: 10.1, Close: 10.2, Factor: 1},
+		{Date: "2025-03-14", Open: 10.0, High: 10.1, Low: 9.9, Close: 10.0, Factor: 1.02},
+	}
+
+	if none := adjustDailyBars(bars, PriceAdjustNone); none[1].Close != 10.2 {
+		t.Errorf("不复权 close = %.3f", none[1].Close)
+	}
+
+	forward := adjustDailyBars(bars, PriceAdjustForward)
+	if !almostEqual(forward[2].Close, 10.0) || !almostEqual(forward[1].Close, 10.2/1.02) {
+		t.Errorf("前复权 = %.4f, %.4f", forward[1].Close, forward[2].Close)
+	}
+
+	backward := adjustDailyBars(bars, PriceAdjustBackward)
+	if !almostEqual(backward[0].Close, 10.2) || !almostEqual(backward[2].High, 10.1*1.02) {
+		t.Errorf("后复权 = %.4f, %.4f", backward[0].Close, backward[2].High)
+	}
+	if bars[2].Close != 10.0 {
+		t.Error("复权不应修改原数据")
+	}
+}
+
+// TestMergeDailyBarsRescale 测试新数据的复权因子按重叠日期换算到本地比例
+func TestMergeDailyBarsRescale(t *testing.T) {
+	existing := []DailyBar{
+		{Date: "2025-03-12", Close: 10, Factor: 0.98},
+		{Date: "2025-03-13", Close: 10, Factor: 1},
+	}
+	// 新数据中 3-14 又有一次除权，之前的因子整体变为原来的 0.97
+	fetched := []DailyBar{
+		{Date: "2025-03-13", Close: 10, Factor: 0.97},
+		{Date: "2025-03-14", Close: 9.7, Factor: 1},
+	}
+
+	merged := mergeDailyBars(existing, fetched)
+	if len(merged) != 3 || merged[2].Date != "2025-03-14" {
+		t.Fatalf("merged = %+v", merged)
+	}
+	if !almostEqual(merged[0].Factor, 0.98) || !almostEqual(merged[1].Factor, 1) || !almostEqual(merged[2].Factor, 1/0.97) {
+		t.Errorf("factors = %.4f, %.4f, %.4f", merged[0].Factor, merged[1].Factor, merged[2].Factor)
+	}
+}
+
+// TestMergeDailyBarsMissingFactors 测试复权K线获取失败（新数据没有复权因子）时保留和沿用已知因子
+func TestMergeDailyBarsMissingFactors(t *testing.T) {
+	existing := []DailyBar{
+		{Date: "2025-03-12", Close: 10, Factor: 0.98},
+		{Date: "2025-03-13", Close: 10, Factor: 1.02},
+	}
+	fetched := []DailyBar{
+		{Date: "2025-03-13", Close: 10.1},
+		{Date: "2025-03-14", Close: 10.2},
+		{Date: "2025-03-17", Close: 10.3},
+	}
+
+	merged := mergeDailyBars(existing, fetched)
+	if len(merged) != 4 || merged[1].Close != 10.1 {
+		t.Fatalf("merged = %+v", merged)
+	}
+	for i, want := range []float64{0.98, 1.02, 1.02, 1.02} {
+		if !almostEqual(merged[i].Factor, want) {
+			t.Errorf("%s factor = %.4f, want %.4f", merged[i].Date, merged[i].Factor, want)
+		}
+	}
+}
+
+// TestUpdateDailyData 测试首次获取、增量更新和各市场数据源
+func TestUpdateDailyData(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	now := time.Now()
+	data, err := updateDailyData("SH600000", "浦发银行", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Bars) < 400 {
+		t.Fatalf("首次获取 %d 根K线", len(data.Bars))
+	}
+	last, prev := data.Bars[len(data.Bars)-1], data.Bars[len(data.Bars)-2]
+	if last.Close != 10.15 || prev.Close != 10.02 || last.Volume != 358201 {
+		t.Errorf("最新K线 = %+v, 前一根 = %+v", last, prev)
+	}
+
+	// 模拟数据在倒数第 40 根除权：前复权后最新价不变，除权前的价格按比例下调
+	exDate := data.Bars[len(data.Bars)-mockDividendBars]
+	before := data.Bars[len(data.Bars)-mockDividendBars-1]
+	if math.Abs(exDate.Factor/before.Factor-mockDividendFactor) > 1e-3 {
+		t.Errorf("除权前后因子 = %.4f, %.4f", before.Factor, exDate.Factor)
+	}
+
+	// 增量更新不产生重复日期，本地文件可重新加载
+	count := len(data.Bars)
+	data, err = updateDailyData("SH600000", "", now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Bars) != count || data.Name != "浦发银行" {
+		t.Errorf("增量更新后 %d 根（原 %d 根）, name = %s", len(data.Bars), count, data.Name)
+	}
+	loaded, err := loadDailyData("SH600000")
+	if err != nil || len(loaded.Bars) != count || !strings.HasSuffix(getDailyFilePath("SH600000"), "CN/SH600000.json") {
+		t.Errorf("加载本地数据: %v, %d 根", err, len(loaded.Bars))
+	}
+
+	// 美股使用 Yahoo：前复权收盘价与 adjclose 一致
+	us, err := updateDailyData("AAPL", "Apple", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forward := adjustDailyBars(us.Bars, PriceAdjustForward)
+	i := len(us.Bars) - mockDividendBars - 1
+	if math.Abs(forward[i].Close-us.Bars[i].Close/mockDividendFactor) > 1e-3 || forward[len(forward)-1].Close != 229.87 {
+		t.Errorf("AAPL 前复权 = %.4f (实际 %.4f)", forward[i].Close, us.Bars[i].Close)
+	}
+}
+
+func TestAggregateDailyBars(t *testing.T) {
+	var bars []DailyBar
+	for i := range 10 {
+		bars = append(bars, DailyBar{Date: "2025-03-01", Open: float64(i), High: float64(i) + 1, Low: float64(i) - 1, Close: float64(i) + 0.5, Volume: 10})
+	}
+
+	groups, perCandle := aggregateDailyBars(bars, 4)
+	if perCandle != 3 || len(groups) != 4 {
+		t.Fatalf("perCandle = %d, groups = %d", perCandle, len(groups))
+	}
+	// 从最新一根向前合并：最后一组为第 7-9 根
+	lastGroup := groups[3]
+	if lastGroup.Open != 7 || lastGroup.Close != 9.5 || lastGroup.High != 10 || lastGroup.Low != 6 || lastGroup.Volume != 30 {
+		t.Errorf("最后一组 = %+v", lastGroup)
+	}
+	if groups[0].Open != 0 || groups[0].Volume != 10 {
+		t.Errorf("第一组 = %+v", groups[0])
+	}
+}
+
+// TestKLineViewing 测试蜡烛图渲染以及平移/缩放/复权切换
+func TestKLineViewing(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+	if _, err := updateDailyData("SH600000", "浦发银行", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Model{config: getDefaultConfig(), language: English, state: Monitoring}
+	if cmd := m.enterKLineViewing("SH600000", "浦发银行"); cmd != nil {
+		t.Error("本地数据刚更新过，不应重新获取")
+	}
+	if m.state != KLineViewing || len(m.visibleDailyBars()) != 60 {
+		t.Fatalf("state = %v, visible = %d", m.state, len(m.visibleDailyBars()))
+	}
+
+	bars := m.visibleDailyBars()
+	chart, volume, layout := renderCandlestickChart(bars, nil, 100, 16, klineVolumeRows, true)
+	if lines := strings.Split(chart, "\n"); len(lines) != 18 || layout.perCandle != 1 {
+		t.Errorf("图表行数 = %d, perCandle = %d", len(lines), layout.perCandle)
+	}
+	if !strings.Contains(chart, "┃") || len(strings.Split(volume, "\n")) != klineVolumeRows {
+		t.Errorf("蜡烛图或成交量副图缺失:\n%s\n%s", chart, volume)
+	}
+
+	// 平移后窗口结束日期提前，向右平移不超过最新K线
+	latest := bars[len(bars)-1].Date
+	m.handleKLineViewing(tea.KeyMsg{Type: tea.KeyLeft})
+	if m.klineOffset != 15 || m.visibleDailyBars()[59].Date >= latest {
+		t.Errorf("向左平移后 offset = %d", m.klineOffset)
+	}
+	m.handleKLineViewing(tea.KeyMsg{Type: tea.KeyRight})
+	m.handleKLineViewing(tea.KeyMsg{Type: tea.KeyRight})
+	if m.klineOffset != 0 {
+		t.Errorf("向右平移后 offset = %d", m.klineOffset)
+	}
+
+	// 缩放到全部数据时窗口覆盖全部K线，无法继续平移
+	for range klineZoomLevels {
+		m.handleKLineViewing(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m.handleKLineViewing(tea.KeyMsg{Type: tea.KeyLeft})
+	if len(m.visibleDailyBars()) != len(m.klineData.Bars) || m.klineOffset != 0 {
+		t.Errorf("全部数据: visible = %d, offset = %d", len(m.visibleDailyBars()), m.klineOffset)
+	}
+
+	// 复权方式循环切换：前复权 → 后复权 → 不复权
+	m.handleKLineViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if m.klineAdjustment != PriceAdjustBackward {
+		t.Errorf("adjustment = %s", m.klineAdjustment)
+	}
+	if view := m.viewKLineViewing(120, 30); !strings.Contains(view, m.getText("kline.adjust.backward")) {
+		t.Errorf("标题应显示复权方式:\n%s", view)
+	}
+}
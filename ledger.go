@@ -0,0 +1,686 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ============================================================================
+// 持仓交易记录（账本）
+// ============================================================================
+
+// transactionDateLayout 交易日期格式
+const transactionDateLayout = "2006-01-02"
+
+// portfolioLotMethod 当前生效的成本结转方法（由配置 portfolio.lot_method 设置）
+var portfolioLotMethod = LotMethodAverage
+
+// PositionSummary 由交易记录推导出的持仓汇总
+type PositionSummary struct {
+	Quantity       int     // 当前持股数量
+	AverageCost    float64 // 剩余持仓的平均成本价（含买入手续费）
+	CostBasis      float64 // 当前持仓总成本
+	RealizedProfit float64 // 已实现盈亏（卖出盈亏 + 分红 - 手续费）
+	TotalFees      float64 // 累计手续费
+}
+
+// transactionTypeAliases 输入交易时支持的类型别名
+var transactionTypeAliases = map[string]TransactionType{
+	"buy":      TransactionBuy,
+	"b":        TransactionBuy,
+	"买入":       TransactionBuy,
+	"sell":     TransactionSell,
+	"s":        TransactionSell,
+	"卖出":       TransactionSell,
+	"dividend": TransactionDividend,
+	"div":      TransactionDividend,
+	"分红":       TransactionDividend,
+	"split":    TransactionSplit,
+	"拆股":       TransactionSplit,
+}
+
+// costLot 一笔买入形成的持仓批次
+type costLot struct {
+	quantity int     // 剩余数量
+	unitCost float64 // 单位成本（含买入手续费）
+}
+
+// normalizeLotMethod 校验成本结转方法，无效值返回加权平均
+func normalizeLotMethod(method LotMethod) (LotMethod, bool) {
+	switch LotMethod(strings.ToLower(string(method))) {
+	case LotMethodFIFO:
+		return LotMethodFIFO, true
+	case LotMethodLIFO:
+		return LotMethodLIFO, true
+	case LotMethodAverage:
+		return LotMethodAverage, true
+	}
+	return LotMethodAverage, false
+}
+
+// initLotMethod 应用配置中的成本结转方法
+func initLotMethod(config PortfolioConfig) {
+	portfolioLotMethod, _ = normalizeLotMethod(config.LotMethod)
+}
+
+// replayTransactions 按顺序重放交易记录，计算持仓数量、平均成本和已实现盈亏
+// 卖出时按该笔卖出记录的结转方法（没有记录时使用 method）结转成本，卖出数量超过当前持仓时返回错误
+func replayTransactions(transactions []Transaction, method LotMethod) (PositionSummary, error) {
+	var summary PositionSummary
+	var lots []costLot
+
+	for i, tx := range transactions {
+		summary.TotalFees += tx.Fee
+
+		switch tx.Type {
+		case TransactionBuy:
+			if tx.Quantity <= 0 {
+				return summary, fmt.Errorf("transaction %d: invalid buy quantity %d", i+1, tx.Quantity)
+			}
+			unitCost := (tx.Price*float64(tx.Quantity) + tx.Fee) / float64(tx.Quantity)
+			lots = append(lots, costLot{quantity: tx.Quantity, unitCost: unitCost})
+		case TransactionSell:
+			if held := lotsQuantity(lots); tx.Quantity > held {
+				return summary, fmt.Errorf("transaction %d: sell %d exceeds held quantity %d", i+1, tx.Quantity, held)
+			}
+			sellMethod := method
+			if tx.LotMethod != "" {
+				sellMethod = tx.LotMethod
+			}
+			// 加权平均在卖出时合并之前的批次，批次保留到卖出时才能按各笔卖出自己的方法结转
+			if sellMethod == LotMethodAverage {
+				lots = mergeLots(lots)
+			}
+			soldCost := consumeLots(&lots, tx.Quantity, sellMethod)
+			summary.RealizedProfit += tx.Price*float64(tx.Quantity) - tx.Fee - soldCost
+		case TransactionDividend:
+			summary.RealizedProfit += tx.Amount - tx.Fee
+		case TransactionSplit:
+			if tx.Ratio <= 0 {
+				return summary, fmt.Errorf("transaction %d: invalid split ratio %.4f", i+1, tx.Ratio)
+			}
+			// 每个批次按比例调整数量，总成本不变
+			for j := range lots {
+				newQuantity := int(math.Round(float64(lots[j].quantity) * tx.Ratio))
+				if newQuantity > 0 {
+					lots[j].unitCost = lots[j].unitCost * float64(lots[j].quantity) / float64(newQuantity)
+				}
+				lots[j].quantity = newQuantity
+			}
+		default:
+			return summary, fmt.Errorf("transaction %d: unknown type %q", i+1, tx.Type)
+		}
+	}
+
+	for _, lot := range lots {
+		summary.Quantity += lot.quantity
+		summary.CostBasis += lot.unitCost * float64(lot.quantity)
+	}
+	if summary.Quantity > 0 {
+		summary.AverageCost = summary.CostBasis / float64(summary.Quantity)
+	}
+
+	return summary, nil
+}
+
+// mergeLots 将所有批次合并为一个加权平均批次
+func mergeLots(lots []costLot) []costLot {
+	quantity := lotsQuantity(lots)
+	if quantity == 0 {
+		return nil
+	}
+	var cost float64
+	for _, lot := range lots {
+		cost += lot.unitCost * float64(lot.quantity)
+	}
+	return []costLot{{quantity: quantity, unitCost: cost / float64(quantity)}}
+}
+
+// lotsQuantity 计算批次总数量
+func lotsQuantity(lots []costLot) int {
+	total := 0
+	for _, lot := range lots {
+		total += lot.quantity
+	}
+	return total
+}
+
+// consumeLots 按结转方法从批次中扣除卖出数量，返回卖出部分的成本
+// FIFO 从最早批次开始扣除，LIFO 从最近批次开始扣除（加权平均只有一个批次）
+func consumeLots(lots *[]costLot, quantity int, method LotMethod) float64 {
+	var soldCost float64
+	for quantity > 0 && len(*lots) > 0 {
+		index := 0
+		if method == LotMethodLIFO {
+			index = len(*lots) - 1
+		}
+		lot := &(*lots)[index]
+
+		taken := min(lot.quantity, quantity)
+		soldCost += lot.unitCost * float64(taken)
+		lot.quantity -= taken
+		quantity -= taken
+
+		if lot.quantity == 0 {
+			*lots = slices.Delete(*lots, index, index+1)
+		}
+	}
+	return soldCost
+}
+
+// sortTransactions 按日期稳定排序（无日期的期初持仓排在最前）
+func sortTransactions(transactions []Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date < transactions[j].Date
+	})
+}
+
+// openingTransaction 根据成本价和数量生成期初买入记录（用于旧数据迁移）
+func openingTransaction(costPrice float64, quantity int) Transaction {
+	return Transaction{
+		Type:     TransactionBuy,
+		Price:    costPrice,
+		Quantity: quantity,
+	}
+}
+
+// migratePortfolioTransactions 将旧版只有成本价和数量的持仓迁移为一条期初买入记录，
+// 并为没有记录结转方法的旧卖出记录补上当前配置的方法（之后不再随配置改变）
+func migratePortfolioTransactions(portfolio *Portfolio) bool {
+	migrated := false
+	for i := range portfolio.Stocks {
+		stock := &portfolio.Stocks[i]
+		for j := range stock.Transactions {
+			if tx := &stock.Transactions[j]; tx.Type == TransactionSell && tx.LotMethod == "" {
+				tx.LotMethod = portfolioLotMethod
+				migrated = true
+			}
+		}
+		if len(stock.Transactions) > 0 || stock.Quantity <= 0 {
+			continue
+		}
+		stock.Transactions = []Transaction{openingTransaction(stock.CostPrice, stock.Quantity)}
+		migrated = true
+	}
+	return migrated
+}
+
+// ============================================================================
+// Stock 账本方法
+// ============================================================================
+
+// PositionSummary 获取持仓汇总（没有交易记录时使用旧版成本价和数量）
+func (s *Stock) PositionSummary() PositionSummary {
+	if s.consolidated != nil {
+		return *s.consolidated
+	}
+	if len(s.Transactions) == 0 {
+		return PositionSummary{
+			Quantity:    s.Quantity,
+			AverageCost: s.CostPrice,
+			CostBasis:   s.CostPrice * float64(s.Quantity),
+		}
+	}
+
+	summary, err := replayTransactions(s.Transactions, portfolioLotMethod)
+	if err != nil {
+		logWarn("log.ledger.replayFail", s.Code, err)
+	}
+	return summary
+}
+
+// syncFromTransactions 根据交易记录更新成本价和数量字段
+func (s *Stock) syncFromTransactions() {
+	if len(s.Transactions) == 0 {
+		return
+	}
+	summary := s.PositionSummary()
+	s.CostPrice = summary.AverageCost
+	s.Quantity = summary.Quantity
+}
+
+// AddTransaction 添加交易记录，交易后持仓无效（如卖出超过持仓）时返回错误且不修改
+// 卖出记录保存当前配置的成本结转方法
+func (s *Stock) AddTransaction(tx Transaction) error {
+	if tx.Type == TransactionSell && tx.LotMethod == "" {
+		tx.LotMethod = portfolioLotMethod
+	}
+	transactions := append(slices.Clone(s.Transactions), tx)
+	sortTransactions(transactions)
+	if _, err := replayTransactions(transactions, portfolioLotMethod); err != nil {
+		return err
+	}
+	s.Transactions = transactions
+	s.syncFromTransactions()
+	return nil
+}
+
+// RemoveTransaction 删除交易记录，删除后持仓无效时返回错误且不修改
+// 删除最后一条记录后持仓清零（否则旧的成本价和数量会被当作旧版持仓，下次加载时又迁移为期初买入）
+func (s *Stock) RemoveTransaction(index int) error {
+	if index < 0 || index >= len(s.Transactions) {
+		return fmt.Errorf("transaction index %d out of range", index)
+	}
+	transactions := slices.Delete(slices.Clone(s.Transactions), index, index+1)
+	if _, err := replayTransactions(transactions, portfolioLotMethod); err != nil {
+		return err
+	}
+	s.Transactions = transactions
+	if len(transactions) == 0 {
+		s.CostPrice = 0
+		s.Quantity = 0
+		return nil
+	}
+	s.syncFromTransactions()
+	return nil
+}
+
+// resetOpeningTransaction 直接修改成本价和数量时重写唯一的期初买入记录（保留日期和手续费）
+func (s *Stock) resetOpeningTransaction(costPrice float64, quantity int) {
+	opening := openingTransaction(costPrice, quantity)
+	if len(s.Transactions) == 1 {
+		opening.Date = s.Transactions[0].Date
+		opening.Fee = s.Transactions[0].Fee
+		// 含手续费的平均成本 = 成本价，反推成交价
+		if quantity > 0 {
+			opening.Price = costPrice - opening.Fee/float64(quantity)
+		}
+	}
+	s.Transactions = []Transaction{opening}
+	s.CostPrice = costPrice
+	s.Quantity = quantity
+}
+
+// parseTransactionInput 解析交易输入，格式:
+//
+//	buy 价格 数量 [手续费] [日期]
+//	sell 价格 数量 [手续费] [日期]
+//	dividend 金额 [手续费] [日期]
+//	split 比例 [日期]
+//
+// 日期格式 YYYY-MM-DD，省略时为今天；feeGiven 表示是否显式输入了手续费
+func parseTransactionInput(input string, today time.Time) (tx Transaction, feeGiven bool, err error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return Transaction{}, false, fmt.Errorf("empty input")
+	}
+
+	txType, ok := transactionTypeAliases[strings.ToLower(fields[0])]
+	if !ok {
+		return Transaction{}, false, fmt.Errorf("unknown transaction type %q", fields[0])
+	}
+	tx = Transaction{Type: txType, Date: today.Format(transactionDateLayout)}
+
+	// 日期可以出现在任意可选位置，先提取出来
+	var numbers []float64
+	for _, field := range fields[1:] {
+		if date, err := time.Parse(transactionDateLayout, field); err == nil {
+			tx.Date = date.Format(transactionDateLayout)
+			continue
+		}
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil || value < 0 {
+			return Transaction{}, false, fmt.Errorf("invalid number %q", field)
+		}
+		numbers = append(numbers, value)
+	}
+
+	required, optional := 1, 1
+	switch txType {
+	case TransactionBuy, TransactionSell:
+		required = 2
+	case TransactionSplit:
+		optional = 0
+	}
+	if len(numbers) < required || len(numbers) > required+optional {
+		return Transaction{}, false, fmt.Errorf("expected %d-%d numbers, got %d", required, required+optional, len(numbers))
+	}
+
+	switch txType {
+	case TransactionBuy, TransactionSell:
+		tx.Price = numbers[0]
+		if numbers[1] != math.Trunc(numbers[1]) || numbers[1] <= 0 {
+			return Transaction{}, false, fmt.Errorf("invalid quantity %v", numbers[1])
+		}
+		tx.Quantity = int(numbers[1])
+		if len(numbers) > 2 {
+			tx.Fee = numbers[2]
+			feeGiven = true
+		}
+		if tx.Price <= 0 {
+			return Transaction{}, false, fmt.Errorf("invalid price %v", tx.Price)
+		}
+	case TransactionDividend:
+		tx.Amount = numbers[0]
+		if len(numbers) > 1 {
+			tx.Fee = numbers[1]
+			feeGiven = true
+		}
+		if tx.Amount <= 0 {
+			return Transaction{}, false, fmt.Errorf("invalid amount %v", tx.Amount)
+		}
+	case TransactionSplit:
+		tx.Ratio = numbers[0]
+		if tx.Ratio <= 0 {
+			return Transaction{}, false, fmt.Errorf("invalid ratio %v", tx.Ratio)
+		}
+	}
+
+	return tx, feeGiven, nil
+}
+
+// ============================================================================
+// 交易记录界面
+// ============================================================================
+
+// enterTransactionViewing 打开当前持股的交易记录界面
+func (m *Model) enterTransactionViewing(index int) {
+	m.previousState = m.state
+	m.state = TransactionViewing
+	m.selectedStockIndex = index
+	m.transactionCursor = max(len(m.portfolio.Stocks[index].Transactions)-1, 0)
+	m.transactionInputMode = false
+	m.input = ""
+	m.inputCursor = 0
+	m.message = ""
+}
+
+func (m *Model) handleTransactionViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	stock := &m.portfolio.Stocks[m.selectedStockIndex]
+
+	if m.transactionInputMode {
+		switch msg.String() {
+		case "esc":
+			m.transactionInputMode = false
+			m.input = ""
+			m.inputCursor = 0
+			m.message = ""
+		case "enter":
+			tx, feeGiven, err := parseTransactionInput(m.input, time.Now())
+			if err != nil {
+				m.message = fmt.Sprintf(m.getText("ledger.invalidInput"), err)
+				return m, nil
+			}
+			// 未输入手续费的买卖按市场费用规则计算
+			if !feeGiven && (tx.Type == TransactionBuy || tx.Type == TransactionSell) {
+				tx.Fee = calculateTradeFee(m.feeScheduleFor(stock.Code), tx.Type, tx.Price, tx.Quantity)
+			}
+			if err := stock.AddTransaction(tx); err != nil {
+				m.message = fmt.Sprintf(m.getText("ledger.addFail"), err)
+				return m, nil
+			}
+			m.savePortfolio()
+			m.portfolioIsSorted = false
+			logInfo("log.ledger.added", stock.Code, tx.Type, tx.Date)
+			m.transactionCursor = slices.IndexFunc(stock.Transactions, func(t Transaction) bool { return t == tx })
+			m.transactionInputMode = false
+			m.input = ""
+			m.inputCursor = 0
+			m.message = m.getText("ledger.addSuccess")
+		default:
+			handleTextInput(msg, &m.input, &m.inputCursor)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.state = Monitoring
+		m.message = ""
+		m.lastUpdate = time.Now()
+		return m, m.tickCmd()
+	case "a":
+		if m.rejectInConsolidatedView() {
+			return m, nil
+		}
+		m.transactionInputMode = true
+		m.input = ""
+		m.inputCursor = 0
+		m.message = ""
+	case "d":
+		if len(stock.Transactions) == 0 {
+			m.message = m.getText("ledger.empty")
+			return m, nil
+		}
+		if m.rejectInConsolidatedView() {
+			return m, nil
+		}
+		if err := stock.RemoveTransaction(m.transactionCursor); err != nil {
+			m.message = fmt.Sprintf(m.getText("ledger.removeFail"), err)
+			return m, nil
+		}
+		m.savePortfolio()
+		m.portfolioIsSorted = false
+		if m.transactionCursor >= len(stock.Transactions) && m.transactionCursor > 0 {
+			m.transactionCursor--
+		}
+		m.message = m.getText("ledger.removeSuccess")
+	case "up", "k", "w":
+		if m.transactionCursor > 0 {
+			m.transactionCursor--
+		}
+	case "down", "j":
+		if m.transactionCursor < len(stock.Transactions)-1 {
+			m.transactionCursor++
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) viewTransactionViewing() string {
+	stock := &m.portfolio.Stocks[m.selectedStockIndex]
+	summary := stock.PositionSummary()
+
+	s := m.getText("ledger.title") + "\n\n"
+	s += fmt.Sprintf(m.getText("ledger.stock"), stock.Name, stock.Code) + "\n"
+	s += fmt.Sprintf(m.getText("ledger.summary"),
+		summary.Quantity, summary.AverageCost, m.formatProfitWithColorZeroLang(summary.RealizedProfit), summary.TotalFees) + "\n\n"
+
+	if len(stock.Transactions) == 0 {
+		s += m.getText("ledger.empty") + "\n"
+	} else {
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"",
+			m.getText("ledger.colDate"), m.getText("ledger.colType"), m.getText("ledger.colPrice"),
+			m.getText("ledger.colQuantity"), m.getText("ledger.colFee"), m.getText("ledger.colAmount"),
+		})
+		for i, tx := range stock.Transactions {
+			cursor := ""
+			if i == m.transactionCursor {
+				cursor = "►"
+			}
+			t.AppendRow(m.transactionRow(cursor, tx))
+		}
+		s += t.Render() + "\n"
+	}
+
+	if m.transactionInputMode {
+		s += "\n" + m.getText("ledger.inputFormat") + "\n"
+		s += m.getText("ledger.inputPrompt") + formatTextWithCursor(m.input, m.inputCursor) + "\n"
+		s += "\n" + m.getText("ledger.inputHelp") + "\n"
+	} else {
+		s += "\n" + m.getText("ledger.help") + "\n"
+	}
+
+	if m.message != "" {
+		s += "\n" + m.message + "\n"
+	}
+	return s
+}
+
+// transactionRow 生成交易记录表格行（不适用的字段显示为 -）
+func (m *Model) transactionRow(cursor string, tx Transaction) table.Row {
+	date := tx.Date
+	if date == "" {
+		date = m.getText("ledger.opening")
+	}
+	price, quantity, amount := "-", "-", "-"
+
+	switch tx.Type {
+	case TransactionBuy, TransactionSell:
+		price = fmt.Sprintf("%.3f", tx.Price)
+		quantity = strconv.Itoa(tx.Quantity)
+		amount = fmt.Sprintf("%.2f", tx.Price*float64(tx.Quantity))
+	case TransactionDividend:
+		amount = fmt.Sprintf("%.2f", tx.Amount)
+	case TransactionSplit:
+		quantity = fmt.Sprintf("x%g", tx.Ratio)
+	}
+
+	return table.Row{cursor, date, m.getText("ledger.type." + string(tx.Type)), price, quantity, fmt.Sprintf("%.2f", tx.Fee), amount}
+}
+
+// ============================================================================
+// 卖出流程
+// ============================================================================
+
+// enterSellingStock 进入当前持股的卖出流程（预填充现价）
+func (m *Model) enterSellingStock(index int) {
+	stock := m.portfolio.Stocks[index]
+	m.previousState = m.state
+	m.state = SellingStock
+	m.sellingStep = 1
+	m.selectedStockIndex = index
+	m.tempCost = ""
+	m.tempQuantity = ""
+	m.input = ""
+	if stock.Price > 0 {
+		m.input = fmt.Sprintf("%.*f", m.config.Display.DecimalPlaces, stock.Price)
+	}
+	m.inputCursor = len([]rune(m.input))
+	m.message = ""
+}
+
+func (m *Model) handleSellingStock(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.state = Monitoring
+		m.sellingStep = 0
+		m.input = ""
+		m.inputCursor = 0
+		m.message = ""
+		m.lastUpdate = time.Now()
+		return m, m.tickCmd()
+	case "enter":
+		return m.processSellingStep()
+	default:
+		handleTextInput(msg, &m.input, &m.inputCursor)
+	}
+	return m, nil
+}
+
+func (m *Model) processSellingStep() (tea.Model, tea.Cmd) {
+	stock := &m.portfolio.Stocks[m.selectedStockIndex]
+
+	switch m.sellingStep {
+	case 1: // 输入卖出价
+		price, err := strconv.ParseFloat(m.input, 64)
+		if err != nil || price <= 0 {
+			m.message = m.getText("invalidPrice")
+			return m, nil
+		}
+		m.tempCost = m.input
+		m.sellingStep = 2
+		m.input = strconv.Itoa(stock.CalculateTotalQuantity()) // 预填充全部持仓
+		m.inputCursor = len([]rune(m.input))
+		m.message = ""
+	case 2: // 输入卖出数量
+		quantity, err := strconv.Atoi(m.input)
+		if err != nil || quantity <= 0 {
+			m.message = m.getText("invalidQuantity")
+			return m, nil
+		}
+		if held := stock.CalculateTotalQuantity(); quantity > held {
+			m.message = fmt.Sprintf(m.getText("sell.exceedsHeld"), held)
+			return m, nil
+		}
+		m.tempQuantity = m.input
+		m.sellingStep = 3
+		// 按市场费用规则预填充手续费，可手动修改
+		price, _ := strconv.ParseFloat(m.tempCost, 64)
+		m.input = fmt.Sprintf("%.2f", calculateTradeFee(m.feeScheduleFor(stock.Code), TransactionSell, price, quantity))
+		m.inputCursor = len([]rune(m.input))
+		m.message = ""
+	case 3: // 输入手续费
+		fee := 0.0
+		if m.input != "" {
+			value, err := strconv.ParseFloat(m.input, 64)
+			if err != nil || value < 0 {
+				m.message = m.getText("sell.invalidFee")
+				return m, nil
+			}
+			fee = value
+		}
+
+		price, _ := strconv.ParseFloat(m.tempCost, 64)
+		quantity, _ := strconv.Atoi(m.tempQuantity)
+		realizedBefore := stock.CalculateRealizedProfit()
+
+		sell := Transaction{
+			Type:     TransactionSell,
+			Date:     time.Now().Format(transactionDateLayout),
+			Price:    price,
+			Quantity: quantity,
+			Fee:      fee,
+		}
+		if err := stock.AddTransaction(sell); err != nil {
+			m.message = fmt.Sprintf(m.getText("ledger.addFail"), err)
+			return m, nil
+		}
+		m.savePortfolio()
+		m.portfolioIsSorted = false
+
+		realized := stock.CalculateRealizedProfit() - realizedBefore
+		logInfo("log.ledger.sold", stock.Code, quantity, price, realized, portfolioLotMethod)
+
+		m.state = Monitoring
+		m.sellingStep = 0
+		m.input = ""
+		m.inputCursor = 0
+		m.message = fmt.Sprintf(m.getText("sell.success"), quantity, stock.Name, m.formatProfitWithColorZeroLang(realized))
+		m.lastUpdate = time.Now()
+		return m, m.tickCmd()
+	}
+	return m, nil
+}
+
+func (m *Model) viewSellingStock() string {
+	stock := &m.portfolio.Stocks[m.selectedStockIndex]
+	summary := stock.PositionSummary()
+
+	s := m.getText("sell.title") + "\n\n"
+	s += fmt.Sprintf(m.getText("ledger.stock"), stock.Name, stock.Code) + "\n"
+	s += fmt.Sprintf(m.getText("sell.position"), summary.Quantity, summary.AverageCost, m.getText("lotMethod."+string(portfolioLotMethod))) + "\n"
+	if stock.Price > 0 {
+		s += fmt.Sprintf(m.getText("currentPrice"), stock.Price) + "\n"
+	}
+	s += "\n"
+
+	switch m.sellingStep {
+	case 1:
+		s += m.getText("sell.enterPrice") + formatTextWithCursor(m.input, m.inputCursor) + "\n"
+	case 2:
+		s += fmt.Sprintf(m.getText("sell.price"), m.tempCost) + "\n"
+		s += m.getText("sell.enterQuantity") + formatTextWithCursor(m.input, m.inputCursor) + "\n"
+	case 3:
+		s += fmt.Sprintf(m.getText("sell.price"), m.tempCost) + "\n"
+		s += fmt.Sprintf(m.getText("sell.quantity"), m.tempQuantity) + "\n"
+		s += m.getText("sell.enterFee") + formatTextWithCursor(m.input, m.inputCursor) + "\n"
+	}
+
+	s += "\n" + m.getText("sell.help") + "\n"
+
+	if m.message != "" {
+		s += "\n" + m.message + "\n"
+	}
+	return s
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestReplayTransactions 测试买入、卖出、分红、拆股后的持仓推导
+func TestReplayTransactions(t *testing.T) {
+	transactions := []Transaction{
+		{Type: TransactionBuy, Date: "2025-01-02", Price: 10, Quantity: 100, Fee: 5},
+		{Type: TransactionBuy, Date: "2025-02-03", Price: 13, Quantity: 100, Fee: 5},
+		{Type: TransactionSell, Date: "2025-03-04", Price: 15, Quantity: 50, Fee: 3},
+		{Type: TransactionDividend, Date: "2025-04-05", Amount: 30},
+		{Type: TransactionSplit, Date: "2025-05-06", Ratio: 2},
+	}
+
+	summary, err := replayTransactions(transactions, LotMethodAverage)
+	if err != nil {
+		t.Fatalf("replayTransactions 返回错误: %v", err)
+	}
+
+	// 平均成本 (1000+5+1300+5)/200 = 11.55，卖出50股实现 750-3-577.5 = 169.5，分红 +30
+	if summary.Quantity != 300 {
+		t.Errorf("持股数量 = %d, expected 300", summary.Quantity)
+	}
+	if !almostEqual(summary.AverageCost, 11.55/2) {
+		t.Errorf("平均成本 = %.4f, expected %.4f", summary.AverageCost, 11.55/2)
+	}
+	if !almostEqual(summary.RealizedProfit, 199.5) {
+		t.Errorf("已实现盈亏 = %.4f, expected 199.5", summary.RealizedProfit)
+	}
+	if !almostEqual(summary.TotalFees, 13) {
+		t.Errorf("累计手续费 = %.2f, expected 13", summary.TotalFees)
+	}
+}
+
+// TestReplayLotMethods 测试不同成本结转方法下的已实现盈亏和剩余成本
+func TestReplayLotMethods(t *testing.T) {
+	transactions := []Transaction{
+		{Type: TransactionBuy, Date: "2025-01-02", Price: 10, Quantity: 100},
+		{Type: TransactionBuy, Date: "2025-02-03", Price: 12, Quantity: 100},
+		{Type: TransactionSell, Date: "2025-03-04", Price: 15, Quantity: 150},
+	}
+
+	tests := []struct {
+		method       LotMethod
+		realized     float64
+		averageCost  float64
+		expectedLeft int
+	}{
+		{LotMethodFIFO, 2250 - (100*10 + 50*12), 12, 50},
+		{LotMethodLIFO, 2250 - (100*12 + 50*10), 10, 50},
+		{LotMethodAverage, 2250 - 150*11, 11, 50},
+	}
+
+	for _, tt := range tests {
+		summary, err := replayTransactions(transactions, tt.method)
+		if err != nil {
+			t.Fatalf("%s: replayTransactions 返回错误: %v", tt.method, err)
+		}
+		if summary.Quantity != tt.expectedLeft {
+			t.Errorf("%s: 剩余数量 = %d, expected %d", tt.method, summary.Quantity, tt.expectedLeft)
+		}
+		if !almostEqual(summary.RealizedProfit, tt.realized) {
+			t.Errorf("%s: 已实现盈亏 = %.2f, expected %.2f", tt.method, summary.RealizedProfit, tt.realized)
+		}
+		if !almostEqual(summary.AverageCost, tt.averageCost) {
+			t.Errorf("%s: 剩余成本 = %.4f, expected %.4f", tt.method, summary.AverageCost, tt.averageCost)
+		}
+	}
+}
+
+// TestRecordedLotMethod 测试卖出记录保存结转方法，修改配置后已有卖出的盈亏不变
+func TestRecordedLotMethod(t *testing.T) {
+	saved := portfolioLotMethod
+	t.Cleanup(func() { portfolioLotMethod = saved })
+	portfolioLotMethod = LotMethodFIFO
+
+	stock := Stock{Code: "SH600000"}
+	for _, tx := range []Transaction{
+		{Type: TransactionBuy, Date: "2025-01-02", Price: 10, Quantity: 100},
+		{Type: TransactionBuy, Date: "2025-02-03", Price: 12, Quantity: 100},
+		{Type: TransactionSell, Date: "2025-03-04", Price: 15, Quantity: 150},
+	} {
+		if err := stock.AddTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if method := stock.Transactions[2].LotMethod; method != LotMethodFIFO {
+		t.Fatalf("卖出记录的结转方法 = %q", method)
+	}
+
+	// 改为 LIFO 后已有卖出仍按 FIFO 结转，新的卖出按 LIFO
+	portfolioLotMethod = LotMethodLIFO
+	if realized := stock.CalculateRealizedProfit(); !almostEqual(realized, 2250-(100*10+50*12)) {
+		t.Errorf("修改配置后已实现盈亏 = %.2f", realized)
+	}
+	if err := stock.AddTransaction(Transaction{Type: TransactionBuy, Date: "2025-04-01", Price: 20, Quantity: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stock.AddTransaction(Transaction{Type: TransactionSell, Date: "2025-05-01", Price: 21, Quantity: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if realized := stock.CalculateRealizedProfit(); !almostEqual(realized, 650+100) {
+		t.Errorf("LIFO 卖出后已实现盈亏 = %.2f", realized)
+	}
+
+	// 旧数据中没有结转方法的卖出在加载时补上当前配置的方法
+	portfolio := Portfolio{Stocks: []Stock{{Code: "SH600000", Transactions: []Transaction{
+		{Type: TransactionBuy, Price: 10, Quantity: 100},
+		{Type: TransactionSell, Date: "2025-03-04", Price: 15, Quantity: 50},
+	}}}}
+	if !migratePortfolioTransactions(&portfolio) || portfolio.Stocks[0].Transactions[1].LotMethod != LotMethodLIFO {
+		t.Errorf("旧卖出记录 = %+v", portfolio.Stocks[0].Transactions[1])
+	}
+}
+
+// TestMigratedLotMethodPersisted 测试加载旧数据时补上的结转方法写回文件，重新加载后修改配置不影响已实现盈亏
+func TestMigratedLotMethodPersisted(t *testing.T) {
+	useTempDataDir(t)
+	saved := portfolioLotMethod
+	t.Cleanup(func() { portfolioLotMethod = saved })
+
+	legacy := Portfolio{Stocks: []Stock{{Code: "SH600000", Name: "浦发银行", Transactions: []Transaction{
+		{Type: TransactionBuy, Date: "2025-01-02", Price: 10, Quantity: 100},
+		{Type: TransactionBuy, Date: "2025-02-03", Price: 12, Quantity: 100},
+		{Type: TransactionSell, Date: "2025-03-04", Price: 15, Quantity: 150},
+	}}}}
+	if err := writePortfolio(defaultAccountName, legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	initLotMethod(PortfolioConfig{LotMethod: LotMethodFIFO})
+	portfolio := loadPortfolio(defaultAccountName)
+	realized := portfolio.Stocks[0].CalculateRealizedProfit()
+	if !almostEqual(realized, 2250-(100*10+50*12)) {
+		t.Fatalf("FIFO 已实现盈亏 = %.2f", realized)
+	}
+
+	initLotMethod(PortfolioConfig{LotMethod: LotMethodLIFO})
+	portfolio = loadPortfolio(defaultAccountName)
+	if got := portfolio.Stocks[0].CalculateRealizedProfit(); !almostEqual(got, realized) {
+		t.Errorf("修改配置后已实现盈亏 = %.2f, want %.2f", got, realized)
+	}
+	if method := portfolio.Stocks[0].Transactions[2].LotMethod; method != LotMethodFIFO {
+		t.Errorf("保存的结转方法 = %q", method)
+	}
+}
+
+func TestNormalizeLotMethod(t *testing.T) {
+	if method, ok := normalizeLotMethod("FIFO"); !ok || method != LotMethodFIFO {
+		t.Errorf("normalizeLotMethod(FIFO) = %s, %v", method, ok)
+	}
+	if method, ok := normalizeLotMethod("random"); ok || method != LotMethodAverage {
+		t.Errorf("无效值应回退为加权平均: %s, %v", method, ok)
+	}
+}
+
+// TestAddTransactionOversell 测试卖出超过持仓时拒绝并保持原状
+func TestAddTransactionOversell(t *testing.T) {
+	stock := Stock{Code: "SH600000"}
+	if err := stock.AddTransaction(Transaction{Type: TransactionBuy, Date: "2025-01-02", Price: 10, Quantity: 100}); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if err := stock.AddTransaction(Transaction{Type: TransactionSell, Date: "2025-01-03", Price: 11, Quantity: 200}); err == nil {
+		t.Error("超额卖出应返回错误")
+	}
+	if len(stock.Transactions) != 1 || stock.Quantity != 100 || stock.CostPrice != 10 {
+		t.Errorf("失败后持仓不应改变: %+v", stock)
+	}
+
+	// 按日期插入: 早于已有卖出的买入使持仓有效
+	if err := stock.AddTransaction(Transaction{Type: TransactionSell, Date: "2025-01-05", Price: 12, Quantity: 100}); err != nil {
+		t.Fatalf("卖出失败: %v", err)
+	}
+	if err := stock.RemoveTransaction(0); err == nil {
+		t.Error("删除买入后卖出超额，应返回错误")
+	}
+}
+
+// TestRemoveLastTransaction 测试删除最后一条交易记录后持仓清零，且重新加载时不会被迁移回来
+func TestRemoveLastTransaction(t *testing.T) {
+	stock := Stock{Code: "SH600000"}
+	if err := stock.AddTransaction(Transaction{Type: TransactionBuy, Date: "2025-01-02", Price: 10, Quantity: 100}); err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if err := stock.RemoveTransaction(0); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if summary := stock.PositionSummary(); stock.Quantity != 0 || stock.CostPrice != 0 || summary.Quantity != 0 {
+		t.Errorf("删除后持仓应清零: %+v, %+v", stock, summary)
+	}
+
+	portfolio := Portfolio{Stocks: []Stock{stock}}
+	if migratePortfolioTransactions(&portfolio) || len(portfolio.Stocks[0].Transactions) != 0 {
+		t.Error("清零的持仓不应迁移为期初买入")
+	}
+}
+
+// TestMigratePortfolioTransactions 测试旧版持仓迁移为期初买入记录
+func TestMigratePortfolioTransactions(t *testing.T) {
+	portfolio := Portfolio{Stocks: []Stock{
+		{Code: "SH600000", CostPrice: 9.8, Quantity: 300},
+		{Code: "AAPL", CostPrice: 150, Quantity: 10, Transactions: []Transaction{
+			{Type: TransactionBuy, Date: "2024-06-01", Price: 150, Quantity: 10},
+		}},
+	}}
+
+	if !migratePortfolioTransactions(&portfolio) {
+		t.Fatal("应执行迁移")
+	}
+
+	migrated := portfolio.Stocks[0]
+	if len(migrated.Transactions) != 1 {
+		t.Fatalf("迁移后交易数量 = %d, expected 1", len(migrated.Transactions))
+	}
+	if tx := migrated.Transactions[0]; tx.Type != TransactionBuy || tx.Price != 9.8 || tx.Quantity != 300 || tx.Date != "" {
+		t.Errorf("期初买入记录错误: %+v", tx)
+	}
+	if migrated.CalculateWeightedAverageCost() != 9.8 || migrated.CalculateTotalQuantity() != 300 {
+		t.Error("迁移后成本价和数量应保持不变")
+	}
+	if len(portfolio.Stocks[1].Transactions) != 1 {
+		t.Error("已有交易记录的持仓不应被迁移")
+	}
+	if migratePortfolioTransactions(&portfolio) {
+		t.Error("重复迁移应无变化")
+	}
+}
+
+func TestParseTransactionInput(t *testing.T) {
+	today := time.Date(2025, 6, 30, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		input    string
+		expected Transaction
+		wantErr  bool
+	}{
+		{"buy 10.5 100", Transaction{Type: TransactionBuy, Date: "2025-06-30", Price: 10.5, Quantity: 100}, false},
+		{"sell 12 50 5 2025-06-01", Transaction{Type: TransactionSell, Date: "2025-06-01", Price: 12, Quantity: 50, Fee: 5}, false},
+		{"买入 8 200 2025-05-20 1.5", Transaction{Type: TransactionBuy, Date: "2025-05-20", Price: 8, Quantity: 200, Fee: 1.5}, false},
+		{"dividend 120", Transaction{Type: TransactionDividend, Date: "2025-06-30", Amount: 120}, false},
+		{"split 2 2025-01-01", Transaction{Type: TransactionSplit, Date: "2025-01-01", Ratio: 2}, false},
+		{"buy 10", Transaction{}, true},
+		{"buy 10 1.5", Transaction{}, true},
+		{"split 2 1", Transaction{}, true},
+		{"transfer 1 2", Transaction{}, true},
+		{"", Transaction{}, true},
+	}
+
+	for _, tt := range tests {
+		tx, _, err := parseTransactionInput(tt.input, today)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTransactionInput(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && tx != tt.expected {
+			t.Errorf("parseTransactionInput(%q) = %+v, expected %+v", tt.input, tx, tt.expected)
+		}
+	}
+}
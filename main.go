@@ -25,7 +25,9 @@ func (m *Model) getMenuItems() []string {
 		m.getText("stockList"),
 		m.getText("watchlist"),
 		m.getText("stockSearch"),
+		m.getText("accounts"),
 		m.getText("language"),
+		m.getText("settings"),
 		m.getText("exit"),
 	}
 }
@@ -53,7 +55,37 @@ func main() {
 
 	// 加载配置文件
 	config := loadConfig()
-	portfolio := loadPortfolio()
+
+	// 初始化行情数据源注册表
+	initQuoteProviderRegistry(config.Providers)
+
+	// 应用持仓成本结转方法（需在加载持仓之前）
+	initLotMethod(config.Portfolio)
+
+	// 应用交易日历（内置休市日 + 配置覆盖）
+	initMarketCalendars(config.Calendar)
+
+	// 初始化数据源接口地址（模拟模式下改为指向内置模拟行情服务器）
+	initAPIEndpoints(config.Endpoints)
+	if isMockModeEnabled(config.Endpoints) {
+		mockServer, err := startMockQuoteServer()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start mock quote server: %v\n", err)
+			os.Exit(1)
+		}
+		defer mockServer.Close()
+		apiEndpoints = mockEndpointsConfig(mockServer.URL)
+		logInfo("log.api.mockServerStarted", mockServer.URL)
+	}
+
+	// 命令行子命令：不启动 TUI，输出结果后按退出码退出（os.Exit 不执行 defer，先刷新日志）
+	if len(os.Args) > 1 {
+		exitCode := runCLI(os.Args[1:], config, os.Stdout, os.Stderr)
+		globalLogger.Sync()
+		os.Exit(exitCode)
+	}
+
+	portfolio := loadAccountPortfolio(config.Portfolio.Account)
 	watchlist := loadWatchlist()
 
 	// 根据配置和是否有股票数据决定初始状态
@@ -104,8 +136,12 @@ func main() {
 		portfolioIsSorted:  false, // 持股列表默认未排序状态
 		watchlistIsSorted:  false, // 自选列表默认未排序状态
 		// 股价缓存初始化
-		stockPriceCache:      make(map[string]*StockPriceCacheEntry),
-		stockPriceUpdateTime: time.Time{}, // 初始化为零时间
+		stockPriceCache:      loadQuoteCache(), // 上次保存的行情，刷新完成前标记为过期显示
+		stockPriceUpdateTime: time.Time{},      // 初始化为零时间
+		// 汇率缓存（离线时使用上次保存的汇率）
+		fxRates: loadFXRates(),
+		// 价格提醒规则
+		alerts: loadAlerts(),
 	}
 
 	// 根据语言设置菜单项
@@ -169,12 +205,16 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			newModel, cmd = m.handleSearchingStock(msg)
 		case SearchResult:
 			newModel, cmd = m.handleSearchResult(msg)
+		case SearchPicking:
+			newModel, cmd = m.handleSearchPicking(msg)
 		case SearchResultWithActions:
 			newModel, cmd = m.handleSearchResultWithActions(msg)
 		case WatchlistSearchConfirm:
 			newModel, cmd = m.handleWatchlistSearchConfirm(msg)
 		case LanguageSelection:
 			newModel, cmd = m.handleLanguageSelection(msg)
+		case SettingsEditing:
+			newModel, cmd = m.handleSettings(msg)
 		case WatchlistViewing:
 			newModel, cmd = m.handleWatchlistViewing(msg)
 		case WatchlistTagging:
@@ -195,6 +235,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			newModel, cmd = m.handleWatchlistSorting(msg)
 		case IntradayChartViewing:
 			newModel, cmd = m.handleIntradayChartViewing(msg)
+		case TransactionViewing:
+			newModel, cmd = m.handleTransactionViewing(msg)
+		case SellingStock:
+			newModel, cmd = m.handleSellingStock(msg)
+		case AccountSwitching:
+			newModel, cmd = m.handleAccountSwitching(msg)
+		case EquityCurveViewing:
+			newModel, cmd = m.handleEquityCurveViewing(msg)
+		case AlertManaging:
+			newModel, cmd = m.handleAlertManaging(msg)
+		case KLineViewing:
+			newModel, cmd = m.handleKLineViewing(msg)
+		case CompareSelecting:
+			newModel, cmd = m.handleCompareSelecting(msg)
+		case CompareViewing:
+			newModel, cmd = m.handleCompareViewing(msg)
 		default:
 			newModel, cmd = m, nil
 		}
@@ -211,13 +267,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, stockPriceCmd)
 			}
 
+			// 各市场收盘后写入当日持仓快照
+			m.recordMarketCloseSnapshots()
+
+			// 持股页面的多币种总计需要汇率，缓存过期时刷新
+			if m.state == Monitoring {
+				if fxCmd := m.refreshFXRatesCmd(); fxCmd != nil {
+					cmds = append(cmds, fxCmd)
+				}
+			}
+
 			newModel, cmd = m, tea.Batch(cmds...)
 		} else {
 			newModel, cmd = m, nil
 		}
-	case fetchStockPriceTriggerMsg:
-		// 触发单个股票的价格获取（两阶段更新模式）
-		newModel, cmd = m, fetchStockPriceCmd(msg.symbol)
 	case stockPriceUpdateMsg:
 		// 处理股价数据更新
 		if msg.Error == nil && msg.Data != nil {
@@ -247,8 +310,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.updatePortfolioPricesFromCache()
 				m.optimizedSortPortfolio(m.portfolioSortField, m.portfolioSortDirection)
 			}
+
+			// 检查价格提醒规则
+			now := time.Now()
+			cmd = m.handleAlertEvents(m.evaluateAlerts(msg.Symbol, msg.Data, now), now)
 		} else {
-			// 更新失败，标记为未更新状态
+			// 更新失败，保留上次的数据（显示为过期），标记为未更新状态
 			m.stockPriceMutex.Lock()
 			if entry, exists := m.stockPriceCache[msg.Symbol]; exists {
 				entry.IsUpdating = false
@@ -256,6 +323,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.stockPriceMutex.Unlock()
 			logError("log.cache.error", msg.Symbol, msg.Error)
 		}
+		// 本轮更新全部返回后保存到磁盘（失败的股票保留上次的数据）
+		if !m.quoteUpdatesPending() {
+			m.saveQuoteCache()
+		}
+		newModel = m
+	case fxRatesUpdateMsg:
+		m.handleFXRatesUpdate(msg)
+		newModel, cmd = m, nil
+	case dailyDataUpdateMsg:
+		m.handleDailyDataUpdate(msg)
+		newModel, cmd = m, nil
+	case symbolDirectoryRefreshedMsg:
+		m.handleSymbolDirectoryRefreshed(msg)
+		newModel, cmd = m, nil
+	case comparisonDataMsg:
+		m.handleComparisonData(msg)
 		newModel, cmd = m, nil
 	case checkDataAvailabilityMsg:
 		// 处理数据可用性检查during auto-collection
@@ -286,7 +369,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case searchIntradayUpdateMsg:
 		// 搜索模式分时数据更新，触发 UI 重新渲染
 		// 继续监听下一次更新
-		newModel, cmd = m, m.waitForSearchIntradayUpdate()
+		newModel, cmd = m, m.handleSearchIntradayUpdate(msg)
 	default:
 		newModel, cmd = m, nil
 	}
@@ -316,12 +399,16 @@ func (m *Model) View() string {
 		mainContent = m.viewSearchingStock()
 	case SearchResult:
 		mainContent = m.viewSearchResult()
+	case SearchPicking:
+		mainContent = m.viewSearchPicking()
 	case SearchResultWithActions:
 		mainContent = m.viewSearchResultWithActions()
 	case WatchlistSearchConfirm:
 		mainContent = m.viewWatchlistSearchConfirm()
 	case LanguageSelection:
 		mainContent = m.viewLanguageSelection()
+	case SettingsEditing:
+		mainContent = m.viewSettings()
 	case WatchlistViewing:
 		mainContent = m.viewWatchlistViewing()
 	case WatchlistTagging:
@@ -345,11 +432,28 @@ func (m *Model) View() string {
 		termWidth := 120
 		termHeight := 30
 		mainContent = m.viewIntradayChart(termWidth, termHeight)
+	case TransactionViewing:
+		mainContent = m.viewTransactionViewing()
+	case SellingStock:
+		mainContent = m.viewSellingStock()
+	case AccountSwitching:
+		mainContent = m.viewAccountSwitching()
+	case EquityCurveViewing:
+		mainContent = m.viewEquityCurveViewing(120, 30)
+	case AlertManaging:
+		mainContent = m.viewAlertManaging()
+	case KLineViewing:
+		mainContent = m.viewKLineViewing(120, 30)
+	case CompareSelecting:
+		mainContent = m.viewCompareSelecting()
+	case CompareViewing:
+		mainContent = m.viewCompareViewing(120, 30)
 	default:
 		mainContent = ""
 	}
 
-	return mainContent
+	// 价格提醒横幅显示在所有页面顶部
+	return m.viewAlertBanner() + mainContent
 }
 
 func (m *Model) handleMainMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -415,7 +519,10 @@ func (m *Model) executeMenuItem() (tea.Model, tea.Cmd) {
 		m.searchFromWatchlist = false
 		m.message = ""
 		return m, nil
-	case 3: // 语言选择页面
+	case 3: // 账户切换页面
+		m.enterAccountSwitching()
+		return m, nil
+	case 4: // 语言选择页面
 		logInfo("log.action.enterLanguage")
 		m.state = LanguageSelection
 		m.languageCursor = 0
@@ -423,7 +530,10 @@ func (m *Model) executeMenuItem() (tea.Model, tea.Cmd) {
 			m.languageCursor = 1
 		}
 		return m, nil
-	case 4: // 退出
+	case 5: // 刷新设置页面
+		m.enterSettings()
+		return m, nil
+	case 6: // 退出
 		logInfo("log.action.exit")
 		m.savePortfolio()
 		m.saveWatchlist()
@@ -441,12 +551,16 @@ func (m *Model) viewMainMenu() string {
 			prefix = "► "
 		}
 
-		if i == 3 { // 语言选择
+		if i == 3 { // 账户
+			s += fmt.Sprintf("%s%s: %s\n", prefix, item, m.accountDisplayName(m.currentAccount()))
+		} else if i == 4 { // 语言选择
 			langStatus := m.getText("english")
 			if m.language == Chinese {
 				langStatus = m.getText("chinese")
 			}
 			s += fmt.Sprintf("%s%s: %s\n", prefix, item, langStatus)
+		} else if i == 5 { // 刷新设置
+			s += fmt.Sprintf("%s%s: %s\n", prefix, item, m.updateSettingsSummary())
 		} else {
 			s += fmt.Sprintf("%s%s\n", prefix, item)
 		}
@@ -593,14 +707,16 @@ func (m *Model) processAddingStep() (tea.Model, tea.Cmd) {
 		costPrice, _ := strconv.ParseFloat(m.tempCost, 64)
 		quantity, _ := strconv.Atoi(m.tempQuantity)
 
-		stock := Stock{
-			Code:      m.tempCode,
-			Name:      m.stockInfo.Name,
-			CostPrice: costPrice,
-			Quantity:  quantity,
+		// 已持有的股票追加一笔买入记录，否则新建持仓
+		successKey := "addSuccess"
+		appended, err := m.addPortfolioBuy(m.tempCode, m.stockInfo.Name, costPrice, quantity)
+		if err != nil {
+			m.message = fmt.Sprintf(m.getText("ledger.addFail"), err)
+			return m, nil
+		}
+		if appended {
+			successKey = "addLotSuccess"
 		}
-
-		m.portfolio.Stocks = append(m.portfolio.Stocks, stock)
 		m.savePortfolio()
 		m.portfolioIsSorted = false // 添加股票后重置持股列表排序状态
 
@@ -611,14 +727,14 @@ func (m *Model) processAddingStep() (tea.Model, tea.Cmd) {
 			m.resetPortfolioCursor() // 重置游标到第一只股票
 			m.lastUpdate = time.Now()
 			m.fromSearch = false // 重置标志
-			m.message = fmt.Sprintf(m.getText("addSuccess"), m.stockInfo.Name, m.tempCode)
+			m.message = fmt.Sprintf(m.getText(successKey), m.stockInfo.Name, m.tempCode)
 			m.addingStep = 0
 			m.input = ""
 			return m, m.tickCmd() // 跳转到监控页面时启动定时器
 		} else {
 			// 从主菜单添加，返回主菜单
 			m.state = MainMenu
-			m.message = fmt.Sprintf(m.getText("addSuccess"), m.stockInfo.Name, m.tempCode)
+			m.message = fmt.Sprintf(m.getText(successKey), m.stockInfo.Name, m.tempCode)
 			m.addingStep = 0
 			m.input = ""
 			return m, nil
@@ -674,6 +790,14 @@ func (m *Model) handleMonitoring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.message = m.getText("emptyPortfolio")
 			return m, nil
 		}
+		if m.rejectInConsolidatedView() {
+			return m, nil
+		}
+		// 多笔交易的持仓需要在交易记录中修改，避免覆盖历史
+		if stock := m.portfolio.Stocks[m.portfolioCursor]; len(stock.Transactions) > 1 {
+			m.message = fmt.Sprintf(m.getText("ledger.editMultiple"), stock.Name)
+			return m, nil
+		}
 		logInfo("log.action.enterEdit")
 		m.previousState = m.state // 记录当前状态
 		m.state = EditingStock
@@ -692,6 +816,9 @@ func (m *Model) handleMonitoring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.message = m.getText("emptyPortfolio")
 			return m, nil
 		}
+		if m.rejectInConsolidatedView() {
+			return m, nil
+		}
 		// 删除当前光标指向的股票
 		removedStock := m.portfolio.Stocks[m.portfolioCursor]
 		m.portfolio.Stocks = append(m.portfolio.Stocks[:m.portfolioCursor], m.portfolio.Stocks[m.portfolioCursor+1:]...)
@@ -705,6 +832,9 @@ func (m *Model) handleMonitoring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "a":
 		// 跳转到添加股票页面
+		if m.rejectInConsolidatedView() {
+			return m, nil
+		}
 		logInfo("log.action.enterAdd")
 		m.previousState = m.state // 记录当前状态
 		m.state = AddingStock
@@ -717,6 +847,30 @@ func (m *Model) handleMonitoring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.message = ""
 		m.fromSearch = true // 设置标志，表示从持股列表进入，完成后应该回到监控页面
 		return m, nil
+	case "x":
+		// 卖出光标指向的股票（按配置的成本结转方法计算已实现盈亏）
+		if len(m.portfolio.Stocks) == 0 {
+			m.message = m.getText("emptyPortfolio")
+			return m, nil
+		}
+		if m.rejectInConsolidatedView() {
+			return m, nil
+		}
+		if m.portfolio.Stocks[m.portfolioCursor].CalculateTotalQuantity() <= 0 {
+			m.message = fmt.Sprintf(m.getText("sell.noPosition"), m.portfolio.Stocks[m.portfolioCursor].Name)
+			return m, nil
+		}
+		logInfo("log.action.enterSell")
+		m.enterSellingStock(m.portfolioCursor)
+		return m, nil
+	case "t":
+		// 查看当前股票的交易记录
+		if len(m.portfolio.Stocks) == 0 {
+			m.message = m.getText("emptyPortfolio")
+			return m, nil
+		}
+		m.enterTransactionViewing(m.portfolioCursor)
+		return m, nil
 	case "v":
 		// 查看分时图表
 		if len(m.portfolio.Stocks) == 0 {
@@ -731,7 +885,7 @@ func (m *Model) handleMonitoring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		actualDate, _, err := GetTradingDayForCollection(selectedStock.Code, m)
 		if err != nil {
 			// 如果获取失败，降级为简单逻辑
-			actualDate = getSmartChartDate()
+			actualDate = getSmartChartDate(getMarketType(selectedStock.Code))
 		}
 		m.chartViewDate = actualDate
 		m.previousState = Monitoring
@@ -765,6 +919,34 @@ func (m *Model) handleMonitoring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.chartIsCollecting = false
 		m.state = IntradayChartViewing
 		return m, nil
+	case "l":
+		// 管理当前股票的价格提醒
+		if len(m.portfolio.Stocks) == 0 {
+			m.message = m.getText("emptyPortfolio")
+			return m, nil
+		}
+		selectedStock := m.portfolio.Stocks[m.portfolioCursor]
+		m.enterAlertManaging(selectedStock.Code, selectedStock.Name)
+		return m, nil
+	case "K":
+		// 查看日K线图
+		if len(m.portfolio.Stocks) == 0 {
+			m.message = m.getText("emptyPortfolio")
+			return m, nil
+		}
+		selectedStock := m.portfolio.Stocks[m.portfolioCursor]
+		return m, m.enterKLineViewing(selectedStock.Code, selectedStock.Name)
+	case "h":
+		// 查看持仓净值曲线（每日快照）
+		m.enterEquityCurveViewing()
+		return m, nil
+	case "p":
+		// 切换账户
+		m.enterAccountSwitching()
+		return m, nil
+	case "r":
+		// 立即刷新行情（自动更新关闭时手动刷新）
+		return m, m.refreshStockPricesNow()
 	case "s":
 		// 进入排序菜单
 		logInfo("log.action.enterSort")
@@ -789,6 +971,7 @@ func (m *Model) handleMonitoring(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *Model) viewMonitoring() string {
 	s := m.getText("monitoringTitle") + "\n"
+	s += fmt.Sprintf(m.getText("account.label"), m.accountDisplayName(m.currentAccount())) + "\n"
 	s += fmt.Sprintf(m.getText("updateTime"), m.lastUpdate.Format("2006-01-02 15:04:05")) + "\n"
 	s += "\n"
 
@@ -805,9 +988,6 @@ func (m *Model) viewMonitoring() string {
 	// 获取带排序指示器的表头
 	t.AppendHeader(m.GeneratePortfolioHeader())
 
-	var totalMarketValue float64
-	var totalCost float64
-
 	// 显示滚动信息
 	totalStocks := len(m.portfolio.Stocks)
 	maxPortfolioLines := m.config.Display.MaxLines
@@ -832,7 +1012,7 @@ func (m *Model) viewMonitoring() string {
 		endIndex = len(stocks)
 	}
 
-	// 首先计算所有股票的总计（用于汇总行）
+	// 首先用缓存价格更新所有股票（用于汇总行）
 	for i := range m.portfolio.Stocks {
 		stock := &m.portfolio.Stocks[i]
 		// 从缓存获取股价数据（非阻塞）
@@ -846,14 +1026,6 @@ func (m *Model) viewMonitoring() string {
 			stock.MinPrice = stockData.MinPrice
 			stock.PrevClose = stockData.PrevClose
 		}
-
-		if stock.Price > 0 {
-			marketValue := stock.Price * float64(stock.Quantity)
-			cost := stock.CostPrice * float64(stock.Quantity)
-
-			totalMarketValue += marketValue
-			totalCost += cost
-		}
 	}
 
 	// 然后显示当前范围内的股票
@@ -870,19 +1042,27 @@ func (m *Model) viewMonitoring() string {
 		}
 	}
 
-	totalPortfolioProfit := totalMarketValue - totalCost
-	totalProfitRate := 0.0
-	if totalCost > 0 {
-		totalProfitRate = (totalPortfolioProfit / totalCost) * 100
-	}
-
+	// 多个币种时先按市场显示原币小计，总计统一换算为基准货币
+	subtotals, total, missingRates := m.calculatePortfolioTotals()
 	t.AppendSeparator()
+	if len(subtotals) > 1 {
+		for _, subtotal := range subtotals {
+			t.AppendRow(m.GeneratePortfolioTotalRow(subtotal))
+		}
+		t.AppendSeparator()
+	}
 	// 使用动态列渲染器生成总计行
-	totalRow := m.GeneratePortfolioTotalRow(totalPortfolioProfit, totalProfitRate, totalMarketValue)
-	t.AppendRow(totalRow)
+	t.AppendRow(m.GeneratePortfolioTotalRow(total))
 
 	s += t.Render() + "\n"
 
+	if len(missingRates) > 0 {
+		s += fmt.Sprintf(m.getText("fx.missingRates"), strings.Join(missingRates, ", ")) + "\n"
+	}
+	if ratesLine := m.formatFXRatesLine(); ratesLine != "" {
+		s += fmt.Sprintf(m.getText("fx.ratesUsed"), ratesLine) + "\n"
+	}
+
 	// 如果可以滚动，显示滚动指示
 	if totalStocks > maxPortfolioLines {
 		s += strings.Repeat("-", 80) + "\n"
@@ -908,27 +1088,66 @@ func (m *Model) viewMonitoring() string {
 }
 
 func (m *Model) tickCmd() tea.Cmd {
-	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+	return tea.Tick(m.quoteRefreshInterval(), func(t time.Time) tea.Msg {
 		return tickMsg{}
 	})
 }
 
 // savePortfolio, getDefaultConfig, loadConfig, saveConfig 已移动到 persistence.go
 
-// 计算股票的持仓盈亏
+// 计算股票的持仓盈亏（未实现盈亏）
 func (s *Stock) CalculatePositionProfit() float64 {
-	// 使用简化的加权平均成本价计算
-	return (s.Price - s.CostPrice) * float64(s.Quantity)
+	summary := s.PositionSummary()
+	return (s.Price - summary.AverageCost) * float64(summary.Quantity)
 }
 
-// 计算股票的加权平均成本价
+// 计算股票的加权平均成本价（由交易记录推导）
 func (s *Stock) CalculateWeightedAverageCost() float64 {
-	return s.CostPrice // 直接返回成本价
+	return s.PositionSummary().AverageCost
 }
 
-// 计算总持股数量
+// 计算总持股数量（由交易记录推导）
 func (s *Stock) CalculateTotalQuantity() int {
-	return s.Quantity // 直接返回持股数量
+	return s.PositionSummary().Quantity
+}
+
+// 计算已实现盈亏（卖出盈亏 + 分红 - 手续费）
+func (s *Stock) CalculateRealizedProfit() float64 {
+	return s.PositionSummary().RealizedProfit
+}
+
+// findPortfolioStockIndex 查找持仓中指定代码的股票索引，不存在时返回 -1
+func (m *Model) findPortfolioStockIndex(code string) int {
+	for i, stock := range m.portfolio.Stocks {
+		if stock.Code == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// addPortfolioBuy 记录一笔今天的买入（按市场费用规则计算手续费）
+// 已持有的股票追加交易记录并返回 true，否则新建持仓
+func (m *Model) addPortfolioBuy(code, name string, price float64, quantity int) (bool, error) {
+	buy := Transaction{
+		Type:     TransactionBuy,
+		Date:     time.Now().Format(transactionDateLayout),
+		Price:    price,
+		Quantity: quantity,
+		Fee:      calculateTradeFee(m.feeScheduleFor(code), TransactionBuy, price, quantity),
+	}
+
+	if index := m.findPortfolioStockIndex(code); index >= 0 {
+		return true, m.portfolio.Stocks[index].AddTransaction(buy)
+	}
+	m.portfolio.Stocks = append(m.portfolio.Stocks, Stock{
+		Code:         code,
+		Name:         name,
+		CostPrice:    price,
+		Quantity:     quantity,
+		Transactions: []Transaction{buy},
+	})
+	return false, nil
 }
 
 // loadPortfolio 已移动到 persistence.go
@@ -1021,7 +1240,8 @@ func (m *Model) processEditingStep() (tea.Model, tea.Cmd) {
 			m.inputCursor = 0
 			return m, nil
 		} else {
-			m.portfolio.Stocks[m.selectedStockIndex].Quantity = newQuantity
+			stock := &m.portfolio.Stocks[m.selectedStockIndex]
+			stock.resetOpeningTransaction(stock.CostPrice, newQuantity)
 			m.savePortfolio()
 			m.portfolioIsSorted = false // 修改股票后重置持股列表排序状态
 
@@ -1112,47 +1332,32 @@ func (m *Model) handleSearchingStock(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		logInfo("搜索股票: %s", m.searchInput)
 		m.message = m.getText("searching")
-		m.searchResult = getStockInfo(m.searchInput)
+		m.searchResult = nil
+		candidates := searchStockCandidates(m.searchInput)
+		if len(candidates) > 1 && !isExactSymbolMatch(m.searchInput, candidates[0]) {
+			// 多个候选且没有代码完全一致的，显示选择列表
+			m.enterSearchPicking(candidates)
+			return m, nil
+		}
+		if len(candidates) > 0 {
+			m.searchResult = quoteSearchCandidate(candidates[0])
+		} else {
+			m.searchResult = getStockInfo(m.searchInput)
+		}
 		if m.searchResult == nil || m.searchResult.Name == "" {
 			logInfo("搜索失败: %s", m.searchInput)
 			m.message = fmt.Sprintf(m.getText("searchNotFound"), m.searchInput)
 			return m, nil
 		}
-		logInfo("搜索成功: %s (%s)", m.searchResult.Name, m.searchResult.Symbol)
-
-		// 标记为搜索模式
-		m.isSearchMode = true
-
-		// 获取智能日期（当日或最近交易日）
-		actualDate, _, err := GetTradingDayForCollection(m.searchResult.Symbol, m)
-		if err != nil {
-			// 降级为简单逻辑
-			actualDate = getSmartChartDate()
+		return m, m.openSearchResult()
+	case "ctrl+r":
+		// 更新本地代码表（后台进行，完成后显示结果）
+		if m.symbolsRefreshing {
+			return m, nil
 		}
-
-		// 设置图表参数
-		m.chartViewStock = m.searchResult.Symbol
-		m.chartViewStockName = m.searchResult.Name
-		m.chartViewDate = actualDate
-
-		// 清理输入
-		m.searchInput = ""
-		m.searchInputCursor = 0
+		m.symbolsRefreshing = true
 		m.message = ""
-
-		// 根据来源决定下一个状态
-		if m.searchFromWatchlist {
-			m.state = WatchlistSearchConfirm
-		} else {
-			m.state = SearchResultWithActions
-		}
-
-		// 两种状态都启动临时 Worker（自动显示图表）
-		return m, m.startSearchIntradayWorker(
-			m.searchResult.Symbol,
-			m.searchResult.Name,
-			actualDate,
-		)
+		return m, refreshSymbolDirectoryCmd()
 	case "left", "ctrl+b":
 		if m.searchInputCursor > 0 {
 			m.searchInputCursor--
@@ -1185,6 +1390,45 @@ func (m *Model) handleSearchingStock(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openSearchResult 打开搜索到的股票：设置分时图参数并进入结果页
+func (m *Model) openSearchResult() tea.Cmd {
+	logInfo("搜索成功: %s (%s)", m.searchResult.Name, m.searchResult.Symbol)
+
+	// 标记为搜索模式
+	m.isSearchMode = true
+
+	// 获取智能日期（当日或最近交易日）
+	actualDate, _, err := GetTradingDayForCollection(m.searchResult.Symbol, m)
+	if err != nil {
+		// 降级为简单逻辑
+		actualDate = getSmartChartDate(getMarketType(m.searchResult.Symbol))
+	}
+
+	// 设置图表参数
+	m.chartViewStock = m.searchResult.Symbol
+	m.chartViewStockName = m.searchResult.Name
+	m.chartViewDate = actualDate
+
+	// 清理输入
+	m.searchInput = ""
+	m.searchInputCursor = 0
+	m.message = ""
+
+	// 根据来源决定下一个状态
+	if m.searchFromWatchlist {
+		m.state = WatchlistSearchConfirm
+	} else {
+		m.state = SearchResultWithActions
+	}
+
+	// 两种状态都启动临时 Worker（自动显示图表）
+	return m.startSearchIntradayWorker(
+		m.searchResult.Symbol,
+		m.searchResult.Name,
+		actualDate,
+	)
+}
+
 func (m *Model) handleSearchResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "q":
@@ -1204,11 +1448,12 @@ func (m *Model) viewSearchingStock() string {
 	s := m.getText("searchTitle") + "\n\n"
 	s += m.getText("enterSearch") + formatTextWithCursor(m.searchInput, m.searchInputCursor) + "\n\n"
 	s += m.getText("searchFormats") + "\n\n"
+	s += m.symbolDirectoryStatus() + "\n\n"
 
 	if m.language == Chinese {
-		s += "操作: ←/→移动光标, Enter搜索, ESC返回, Home/End跳转首尾\n"
+		s += "操作: ←/→移动光标, Enter搜索, ESC返回, Home/End跳转首尾, Ctrl+R更新本地代码表\n"
 	} else {
-		s += "Actions: ←/→ move cursor, Enter search, ESC back, Home/End jump\n"
+		s += "Actions: ←/→ move cursor, Enter search, ESC back, Home/End jump, Ctrl+R update symbol list\n"
 	}
 
 	if m.message != "" {
@@ -2166,7 +2411,7 @@ func (m *Model) handleSearchResultWithActions(msg tea.KeyMsg) (tea.Model, tea.Cm
 		return m, m.tickCmd()
 	case "2":
 		// 添加到持股列表（进入添加流程）
-		if m.searchResult != nil {
+		if m.searchResult != nil && !m.rejectInConsolidatedView() {
 			// 停止搜索 worker
 			if m.isSearchMode {
 				m.stopSearchIntradayWorker()
@@ -2316,6 +2561,9 @@ func (m *Model) viewSearchResultWithActions() string {
 			chartModel := m.createSearchIntradayChart(chartWidth, chartHeight)
 			if chartModel != nil {
 				s += chartModel.View() + "\n"
+				if panel := m.renderVolumePanel(m.searchIntradayData, chartModel, searchVolumeRows); panel != "" {
+					s += panel + "\n"
+				}
 
 				// 显示更新信息
 				if m.language == Chinese {
@@ -2410,7 +2658,7 @@ func (m *Model) handleWatchlistViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		actualDate, _, err := GetTradingDayForCollection(selectedStock.Code, m)
 		if err != nil {
 			// 如果获取失败，降级为简单逻辑
-			actualDate = getSmartChartDate()
+			actualDate = getSmartChartDate(getMarketType(selectedStock.Code))
 		}
 		m.chartViewDate = actualDate
 		m.previousState = WatchlistViewing
@@ -2440,6 +2688,33 @@ func (m *Model) handleWatchlistViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.chartIsCollecting = false
 		m.state = IntradayChartViewing
 		return m, nil
+	case "l":
+		// 管理当前股票的价格提醒
+		filteredStocks := m.getFilteredWatchlist()
+		if len(filteredStocks) == 0 {
+			m.message = m.getText("emptyWatchlist")
+			return m, nil
+		}
+		selectedStock := filteredStocks[m.watchlistCursor]
+		m.enterAlertManaging(selectedStock.Code, selectedStock.Name)
+		return m, nil
+	case "K":
+		// 查看日K线图
+		filteredStocks := m.getFilteredWatchlist()
+		if len(filteredStocks) == 0 {
+			m.message = m.getText("emptyWatchlist")
+			return m, nil
+		}
+		selectedStock := filteredStocks[m.watchlistCursor]
+		return m, m.enterKLineViewing(selectedStock.Code, selectedStock.Name)
+	case "C":
+		// 多股票分时对比
+		if len(m.watchlist.Stocks) == 0 {
+			m.message = m.getText("emptyWatchlist")
+			return m, nil
+		}
+		m.enterCompareSelecting()
+		return m, nil
 	case "a":
 		// 跳转到股票搜索页面
 		logInfo("log.action.watchlistSearch")
@@ -2504,6 +2779,9 @@ func (m *Model) handleWatchlistViewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.state = WatchlistGroupSelect
 		m.message = ""
 		return m, nil
+	case "r":
+		// 立即刷新行情（自动更新关闭时手动刷新）
+		return m, m.refreshStockPricesNow()
 	case "c":
 		// 清除标签过滤
 		if m.selectedTag != "" {
@@ -2816,6 +3094,9 @@ func (m *Model) viewWatchlistSearchConfirm() string {
 			chartModel := m.createSearchIntradayChart(chartWidth, chartHeight)
 			if chartModel != nil {
 				s += chartModel.View() + "\n"
+				if panel := m.renderVolumePanel(m.searchIntradayData, chartModel, searchVolumeRows); panel != "" {
+					s += panel + "\n"
+				}
 
 				// 显示更新信息
 				if m.language == Chinese {
@@ -2977,7 +3258,7 @@ func (m *Model) handlePortfolioSorting(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.portfolioSortField = SortByCode  // 重置为默认值
 		m.portfolioSortDirection = SortAsc // 重置为默认值
 		// 重新加载原始数据顺序
-		m.portfolio = loadPortfolio()
+		m.reloadPortfolio()
 		m.resetPortfolioCursor()
 		// 返回持股列表页面
 		m.state = Monitoring
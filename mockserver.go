@@ -0,0 +1,829 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// ============================================================================
+// 本地模拟行情服务器（离线开发与测试）
+// ============================================================================
+//
+// 模拟服务器以官方域名作为路径前缀区分数据源，例如:
+//   http://127.0.0.1:PORT/qt.gtimg.cn/q=sh600000
+//   http://127.0.0.1:PORT/query1.finance.yahoo.com/v8/finance/chart/AAPL
+// 所有响应都由 mock/stocks.json 中的固定行情生成，分时数据按交易时段确定性生成。
+
+//go:embed mock/stocks.json
+var mockStocksJSON []byte
+
+// mockStock 模拟行情中的一只股票
+type mockStock struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Pinyin    string  `json:"pinyin"`
+	PrevClose float64 `json:"prev_close"`
+	Price     float64 `json:"price"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Volume    int64   `json:"volume"`
+	Turnover  float64 `json:"turnover"`
+}
+
+// mockDailyBar 模拟日K线的一天（Factor 为后复权因子）
+type mockDailyBar struct {
+	Date   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume int64
+	Factor float64
+}
+
+// mockMinuteBar 模拟分时数据的一分钟
+type mockMinuteBar struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume int64
+}
+
+// mockQuoteServer 模拟行情服务器
+type mockQuoteServer struct {
+	stocks []mockStock
+	byCode map[string]*mockStock
+}
+
+// startMockQuoteServer 启动内置模拟行情服务器
+func startMockQuoteServer() (*httptest.Server, error) {
+	handler, err := newMockQuoteServer()
+	if err != nil {
+		return nil, err
+	}
+	return httptest.NewServer(handler), nil
+}
+
+// mockEndpointsConfig 返回指向模拟服务器的接口地址
+func mockEndpointsConfig(baseURL string) EndpointsConfig {
+	return EndpointsConfig{
+		Mock:             true,
+		TencentQuote:     baseURL + "/qt.gtimg.cn",
+		TencentSearch:    baseURL + "/smartbox.gtimg.cn",
+		TencentMinute:    baseURL + "/ifzq.gtimg.cn",
+		SinaSearch:       baseURL + "/suggest3.sinajs.cn",
+		SinaIntraday:     baseURL + "/money.finance.sina.com.cn",
+		EastMoney:        baseURL + "/push2.eastmoney.com",
+		EastMoneyHistory: baseURL + "/push2his.eastmoney.com",
+		Yahoo:            baseURL + "/query1.finance.yahoo.com",
+		TwelveData:       baseURL + "/api.twelvedata.com",
+		FMP:              baseURL + "/financialmodelingprep.com",
+		Nasdaq:           baseURL + "/api.nasdaq.com",
+	}
+}
+
+// newMockQuoteServer 加载内置行情数据并创建模拟服务器
+func newMockQuoteServer() (*mockQuoteServer, error) {
+	var stocks []mockStock
+	if err := json.Unmarshal(mockStocksJSON, &stocks); err != nil {
+		return nil, fmt.Errorf("parse mock stocks: %w", err)
+	}
+
+	server := &mockQuoteServer{
+		stocks: stocks,
+		byCode: make(map[string]*mockStock, len(stocks)),
+	}
+	for i := range server.stocks {
+		server.byCode[server.stocks[i].Code] = &server.stocks[i]
+	}
+	return server, nil
+}
+
+// ServeHTTP 按路径中的域名前缀分发到对应数据源的模拟实现
+func (s *mockQuoteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	path = "/" + path
+	query := r.URL.Query()
+
+	switch host {
+	case "qt.gtimg.cn":
+		s.serveTencentQuote(w, strings.TrimPrefix(path, "/q="))
+	case "smartbox.gtimg.cn":
+		s.serveTencentSearch(w, query.Get("q"))
+	case "ifzq.gtimg.cn":
+		if strings.HasPrefix(path, "/appstock/app/fqkline") {
+			s.serveTencentKLine(w, query.Get("param"))
+		} else {
+			s.serveTencentMinute(w, query.Get("code"))
+		}
+	case "suggest3.sinajs.cn":
+		_, keyword, _ := strings.Cut(path, "key=")
+		s.serveSinaSearch(w, keyword)
+	case "money.finance.sina.com.cn":
+		s.serveSinaIntraday(w, query.Get("symbol"))
+	case "push2.eastmoney.com":
+		if strings.Contains(path, "/trends2/") {
+			s.serveEastMoneyTrends(w, query.Get("secid"))
+		} else if strings.HasPrefix(path, "/api/qt/clist/") {
+			s.serveEastMoneySymbolList(w, query)
+		} else {
+			s.serveEastMoneyQuote(w, query.Get("secid"))
+		}
+	case "push2his.eastmoney.com":
+		s.serveEastMoneyKLine(w, query.Get("secid"), query.Get("fqt"), query.Get("lmt"))
+	case "query1.finance.yahoo.com":
+		if strings.HasPrefix(path, "/v7/finance/spark") {
+			s.serveYahooSpark(w, query.Get("symbols"))
+		} else {
+			s.serveYahooChart(w, strings.TrimPrefix(path, "/v8/finance/chart/"), query)
+		}
+	case "api.twelvedata.com":
+		if strings.HasPrefix(path, "/symbol_search") {
+			s.serveTwelveDataSearch(w, query.Get("symbol"))
+		} else {
+			s.serveTwelveDataQuote(w, query.Get("symbol"))
+		}
+	case "financialmodelingprep.com":
+		s.serveFMPQuote(w, strings.TrimPrefix(path, "/api/v3/quote/"))
+	case "api.nasdaq.com":
+		s.serveNasdaqScreener(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ============================================================================
+// 腾讯
+// ============================================================================
+
+// serveTencentQuote 腾讯行情: v_sh600000="1~浦发银行~600000~...";（GBK编码）
+func (s *mockQuoteServer) serveTencentQuote(w http.ResponseWriter, codes string) {
+	var sb strings.Builder
+	for _, raw := range strings.Split(codes, ",") {
+		stock := s.lookup(raw)
+		if stock == nil {
+			sb.WriteString("v_pv_none_match=\"1\";\n")
+			continue
+		}
+
+		fields := make([]string, 50)
+		for i := range fields {
+			fields[i] = "0"
+		}
+		fields[0] = "1"
+		fields[1] = stock.Name
+		fields[2] = mockDigits(stock.Code)
+		fields[3] = mockPrice(stock.Price)
+		fields[4] = mockPrice(stock.PrevClose)
+		fields[5] = mockPrice(stock.Open)
+		fields[33] = mockPrice(stock.High)
+		fields[34] = mockPrice(stock.Low)
+		fields[36] = strconv.FormatInt(stock.Volume, 10)
+		fields[38] = strconv.FormatFloat(stock.Turnover, 'f', 2, 64)
+		fmt.Fprintf(&sb, "v_%s=\"%s\";\n", raw, strings.Join(fields, "~"))
+	}
+
+	// 腾讯行情接口返回 GBK 编码
+	content, err := simplifiedchinese.GBK.NewEncoder().String(sb.String())
+	if err != nil {
+		content = sb.String()
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=GBK")
+	fmt.Fprint(w, content)
+}
+
+// serveTencentSearch 腾讯搜索: v_hint="sh~600000~浦...~pfyh~GP-A^..."
+// 名称使用 \u 转义（与真实接口一致），只返回A股和港股
+func (s *mockQuoteServer) serveTencentSearch(w http.ResponseWriter, keyword string) {
+	var hints []string
+	for _, stock := range s.search(keyword) {
+		market := getMarketType(stock.Code)
+		if market == MarketUS {
+			continue
+		}
+		escapedName := strings.Trim(strconv.QuoteToASCII(stock.Name), "\"")
+		hints = append(hints, fmt.Sprintf("%s~%s~%s~%s~GP",
+			strings.ToLower(stock.Code[:2]), mockDigits(stock.Code), escapedName, stock.Pinyin))
+	}
+
+	if len(hints) == 0 {
+		fmt.Fprint(w, "v_hint=\"N\";")
+		return
+	}
+	fmt.Fprintf(w, "v_hint=\"%s\";", strings.Join(hints, "^"))
+}
+
+// serveTencentMinute 腾讯分时: min_data_sh600000={"code":0,"data":{"sh600000":{"data":{"data":["0930 10.05 1200 12060.00"]}}}}
+func (s *mockQuoteServer) serveTencentMinute(w http.ResponseWriter, code string) {
+	stock := s.lookup(code)
+	if stock == nil {
+		fmt.Fprintf(w, "min_data_%s={\"code\":-1,\"msg\":\"param error\"}", code)
+		return
+	}
+
+	var lines []string
+	var totalVolume int64
+	var totalAmount float64
+	for _, bar := range mockIntradayBars(stock) {
+		totalVolume += bar.Volume
+		totalAmount += float64(bar.Volume) * bar.Close
+		lines = append(lines, fmt.Sprintf("%s %s %d %.2f", bar.Time.Format("1504"), mockPrice(bar.Close), totalVolume, totalAmount))
+	}
+
+	payload := map[string]any{
+		"code": 0,
+		"data": map[string]any{
+			code: map[string]any{"data": map[string]any{"data": lines}},
+		},
+	}
+	data, _ := json.Marshal(payload)
+	fmt.Fprintf(w, "min_data_%s=%s", code, data)
+}
+
+// serveTencentKLine 腾讯日K线: {"code":0,"data":{"sh600000":{"day":[["2025-03-14","开","收","高","低","量"]]}}}
+// param 格式: sh600000,day,开始日期,结束日期,数量,复权方式(qfq/hfq/空)
+func (s *mockQuoteServer) serveTencentKLine(w http.ResponseWriter, param string) {
+	parts := strings.Split(param, ",")
+	if len(parts) < 6 {
+		writeMockJSON(w, http.StatusOK, map[string]any{"code": -1, "msg": "param error"})
+		return
+	}
+	stock := s.lookup(parts[0])
+	if stock == nil {
+		writeMockJSON(w, http.StatusOK, map[string]any{"code": -1, "msg": "param error"})
+		return
+	}
+
+	count, _ := strconv.Atoi(parts[4])
+	bars := mockDailyBars(stock, count)
+	last := bars[len(bars)-1].Factor
+	rows := make([][]string, 0, len(bars))
+	for _, bar := range bars {
+		ratio := 1.0
+		switch parts[5] {
+		case "hfq":
+			ratio = bar.Factor
+		case "qfq":
+			ratio = bar.Factor / last
+		}
+		rows = append(rows, []string{
+			bar.Date.Format("2006-01-02"),
+			mockPrice(bar.Open * ratio), mockPrice(bar.Close * ratio),
+			mockPrice(bar.High * ratio), mockPrice(bar.Low * ratio),
+			fmt.Sprintf("%.3f", float64(bar.Volume)/100),
+		})
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"code": 0,
+		"msg":  "",
+		"data": map[string]any{parts[0]: map[string]any{parts[5] + "day": rows}},
+	})
+}
+
+// ============================================================================
+// 新浪
+// ============================================================================
+
+// serveSinaSearch 新浪搜索: var suggestvalue="sh600000,浦发银行;...";
+func (s *mockQuoteServer) serveSinaSearch(w http.ResponseWriter, keyword string) {
+	if decoded, err := url.PathUnescape(keyword); err == nil {
+		keyword = decoded
+	}
+
+	var items []string
+	for _, stock := range s.search(keyword) {
+		items = append(items, fmt.Sprintf("%s,%s", strings.ToLower(stock.Code), stock.Name))
+	}
+	fmt.Fprintf(w, "var suggestvalue=\"%s\";", strings.Join(items, ";"))
+}
+
+// serveSinaIntraday 新浪分钟K线: [{"day":"2025-11-26 09:31:00","open":"8.52",...}]
+func (s *mockQuoteServer) serveSinaIntraday(w http.ResponseWriter, symbol string) {
+	stock := s.lookup(symbol)
+	if stock == nil {
+		writeMockJSON(w, http.StatusOK, nil)
+		return
+	}
+
+	items := make([]map[string]string, 0)
+	for _, bar := range mockIntradayBars(stock) {
+		items = append(items, map[string]string{
+			"day":    bar.Time.Format("2006-01-02 15:04:05"),
+			"open":   mockPrice(bar.Open),
+			"high":   mockPrice(bar.High),
+			"low":    mockPrice(bar.Low),
+			"close":  mockPrice(bar.Close),
+			"volume": strconv.FormatInt(bar.Volume, 10),
+		})
+	}
+	writeMockJSON(w, http.StatusOK, items)
+}
+
+// ============================================================================
+// 东方财富
+// ============================================================================
+
+// serveEastMoneyQuote 东方财富行情字段: {"data":{"f47":成交量,"f168":换手率*100}}
+func (s *mockQuoteServer) serveEastMoneyQuote(w http.ResponseWriter, secid string) {
+	stock := s.lookup(secid)
+	if stock == nil {
+		writeMockJSON(w, http.StatusOK, map[string]any{"rc": 0, "data": nil})
+		return
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"rc": 0,
+		"data": map[string]any{
+			"f47":  stock.Volume,
+			"f168": int(math.Round(stock.Turnover * 100)),
+		},
+	})
+}
+
+// serveEastMoneyTrends 东方财富分时: {"data":{"trends":["2025-11-26 09:31,开,收,高,低,量"]}}
+func (s *mockQuoteServer) serveEastMoneyTrends(w http.ResponseWriter, secid string) {
+	stock := s.lookup(secid)
+	if stock == nil {
+		writeMockJSON(w, http.StatusOK, map[string]any{"rc": 0, "data": nil})
+		return
+	}
+
+	trends := make([]string, 0)
+	for _, bar := range mockIntradayBars(stock) {
+		trends = append(trends, fmt.Sprintf("%s,%s,%s,%s,%s,%d",
+			bar.Time.Format("2006-01-02 15:04"),
+			mockPrice(bar.Open), mockPrice(bar.Close), mockPrice(bar.High), mockPrice(bar.Low), bar.Volume))
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"rc":   0,
+		"data": map[string]any{"code": mockDigits(stock.Code), "preClose": stock.PrevClose, "trends": trends},
+	})
+}
+
+// mockSymbolListPageSize 模拟代码列表每页最多返回的条数（与真实接口一样忽略更大的 pz）
+const mockSymbolListPageSize = 3
+
+// serveEastMoneySymbolList 东方财富代码列表: {"data":{"total":N,"diff":[{"f12":"600000","f13":1,"f14":"浦发银行"}]}}
+// fs 为逗号分隔的市场筛选条件（m:1+t:2 / m:116+t:3 / m:105），只按 m: 部分匹配
+func (s *mockQuoteServer) serveEastMoneySymbolList(w http.ResponseWriter, query url.Values) {
+	markets := make(map[int]bool)
+	for _, filter := range strings.Split(query.Get("fs"), ",") {
+		marketID, _, _ := strings.Cut(strings.TrimPrefix(filter, "m:"), "+")
+		if id, err := strconv.Atoi(marketID); err == nil {
+			markets[id] = true
+		}
+	}
+
+	diff := make([]map[string]any, 0)
+	for _, stock := range s.stocks {
+		var marketID int
+		switch {
+		case strings.HasPrefix(stock.Code, "SH"):
+			marketID = 1
+		case strings.HasPrefix(stock.Code, "SZ"):
+			marketID = 0
+		case strings.HasPrefix(stock.Code, "HK"):
+			marketID = 116
+		case getMarketType(stock.Code) == MarketUS:
+			marketID = 105
+		default:
+			continue // 汇率不在代码列表中
+		}
+		if markets[marketID] {
+			diff = append(diff, map[string]any{"f12": mockDigits(stock.Code), "f13": marketID, "f14": stock.Name})
+		}
+	}
+
+	total := len(diff)
+	page, _ := strconv.Atoi(query.Get("pn"))
+	start := min(max(page-1, 0)*mockSymbolListPageSize, total)
+	end := min(start+mockSymbolListPageSize, total)
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"rc":   0,
+		"data": map[string]any{"total": total, "diff": diff[start:end]},
+	})
+}
+
+// serveEastMoneyKLine 东方财富日K线: {"data":{"klines":["2025-03-14,开,收,高,低,量,额"]}}（fqt: 0 不复权 1 前复权 2 后复权）
+func (s *mockQuoteServer) serveEastMoneyKLine(w http.ResponseWriter, secid, fqt, limit string) {
+	stock := s.lookup(secid)
+	if stock == nil {
+		writeMockJSON(w, http.StatusOK, map[string]any{"rc": 0, "data": nil})
+		return
+	}
+
+	count, _ := strconv.Atoi(limit)
+	bars := mockDailyBars(stock, count)
+	last := bars[len(bars)-1].Factor
+	klines := make([]string, 0, len(bars))
+	for _, bar := range bars {
+		ratio := 1.0
+		switch fqt {
+		case "2":
+			ratio = bar.Factor
+		case "1":
+			ratio = bar.Factor / last
+		}
+		klines = append(klines, fmt.Sprintf("%s,%s,%s,%s,%s,%d,%.2f",
+			bar.Date.Format("2006-01-02"),
+			mockPrice(bar.Open*ratio), mockPrice(bar.Close*ratio), mockPrice(bar.High*ratio), mockPrice(bar.Low*ratio),
+			bar.Volume/100, float64(bar.Volume)*bar.Close))
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"rc":   0,
+		"data": map[string]any{"code": mockDigits(stock.Code), "name": stock.Name, "klines": klines},
+	})
+}
+
+// ============================================================================
+// Yahoo Finance
+// ============================================================================
+
+// serveYahooChart Yahoo chart 接口（interval=1d 日线报价，interval=1m 分时，带 period1 时为日K线历史）
+func (s *mockQuoteServer) serveYahooChart(w http.ResponseWriter, symbol string, query url.Values) {
+	stock := s.lookup(symbol)
+	if stock == nil {
+		writeMockJSON(w, http.StatusNotFound, map[string]any{
+			"chart": map[string]any{
+				"result": nil,
+				"error":  map[string]string{"code": "Not Found", "description": "No data found, symbol may be delisted"},
+			},
+		})
+		return
+	}
+
+	var result map[string]any
+	if period1, err := strconv.ParseInt(query.Get("period1"), 10, 64); err == nil {
+		days := int(time.Since(time.Unix(period1, 0)).Hours() / 24)
+		result = mockYahooDailyResult(stock, symbol, days)
+	} else {
+		result = mockYahooResult(stock, symbol, query.Get("interval") == "1m")
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"chart": map[string]any{
+			"result": []any{result},
+			"error":  nil,
+		},
+	})
+}
+
+// serveYahooSpark Yahoo spark 多代码接口
+func (s *mockQuoteServer) serveYahooSpark(w http.ResponseWriter, symbols string) {
+	results := make([]any, 0)
+	for _, symbol := range strings.Split(symbols, ",") {
+		stock := s.lookup(symbol)
+		if stock == nil {
+			continue
+		}
+		results = append(results, map[string]any{
+			"symbol":   symbol,
+			"response": []any{mockYahooResult(stock, symbol, false)},
+		})
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{
+		"spark": map[string]any{"result": results, "error": nil},
+	})
+}
+
+// mockYahooResult 生成 Yahoo chart 结果（meta + 时间戳 + OHLCV）
+func mockYahooResult(stock *mockStock, symbol string, intraday bool) map[string]any {
+	var timestamps []int64
+	var opens, highs, lows, closes []float64
+	var volumes []int64
+
+	if intraday {
+		for _, bar := range mockIntradayBars(stock) {
+			timestamps = append(timestamps, bar.Time.Unix())
+			opens = append(opens, bar.Open)
+			highs = append(highs, bar.High)
+			lows = append(lows, bar.Low)
+			closes = append(closes, bar.Close)
+			volumes = append(volumes, bar.Volume)
+		}
+	} else {
+		timestamps = []int64{mockTradingDate(stock).Unix()}
+		opens = []float64{stock.Open}
+		highs = []float64{stock.High}
+		lows = []float64{stock.Low}
+		closes = []float64{stock.Price}
+		volumes = []int64{stock.Volume}
+	}
+
+	return map[string]any{
+		"meta": map[string]any{
+			"symbol":               symbol,
+			"longName":             stock.Name,
+			"shortName":            stock.Name,
+			"regularMarketPrice":   stock.Price,
+			"chartPreviousClose":   stock.PrevClose,
+			"regularMarketDayHigh": stock.High,
+			"regularMarketDayLow":  stock.Low,
+			"regularMarketVolume":  stock.Volume,
+		},
+		"timestamp": timestamps,
+		"indicators": map[string]any{
+			"quote": []any{map[string]any{
+				"open": opens, "high": highs, "low": lows, "close": closes, "volume": volumes,
+			}},
+		},
+	}
+}
+
+// mockYahooDailyResult 生成 Yahoo 日K线结果（close 为实际价，adjclose 为前复权价）
+func mockYahooDailyResult(stock *mockStock, symbol string, days int) map[string]any {
+	bars := mockDailyBars(stock, days)
+	last := bars[len(bars)-1].Factor
+
+	var timestamps []int64
+	var opens, highs, lows, closes, adjCloses []float64
+	var volumes []int64
+	for _, bar := range bars {
+		timestamps = append(timestamps, bar.Date.Unix())
+		opens = append(opens, bar.Open)
+		highs = append(highs, bar.High)
+		lows = append(lows, bar.Low)
+		closes = append(closes, bar.Close)
+		adjCloses = append(adjCloses, math.Round(bar.Close*bar.Factor/last*1e6)/1e6)
+		volumes = append(volumes, bar.Volume)
+	}
+
+	return map[string]any{
+		"meta":      map[string]any{"symbol": symbol, "regularMarketPrice": stock.Price},
+		"timestamp": timestamps,
+		"indicators": map[string]any{
+			"quote": []any{map[string]any{
+				"open": opens, "high": highs, "low": lows, "close": closes, "volume": volumes,
+			}},
+			"adjclose": []any{map[string]any{"adjclose": adjCloses}},
+		},
+	}
+}
+
+// ============================================================================
+// TwelveData / FMP
+// ============================================================================
+
+// serveTwelveDataQuote TwelveData 报价（数值均为字符串）
+func (s *mockQuoteServer) serveTwelveDataQuote(w http.ResponseWriter, symbol string) {
+	stock := s.lookup(symbol)
+	if stock == nil {
+		writeMockJSON(w, http.StatusOK, map[string]any{
+			"code": 404, "message": fmt.Sprintf("**symbol** %s not found", symbol), "status": "error",
+		})
+		return
+	}
+	writeMockJSON(w, http.StatusOK, map[string]string{
+		"symbol":         symbol,
+		"name":           stock.Name,
+		"open":           mockPrice(stock.Open),
+		"high":           mockPrice(stock.High),
+		"low":            mockPrice(stock.Low),
+		"close":          mockPrice(stock.Price),
+		"previous_close": mockPrice(stock.PrevClose),
+		"volume":         strconv.FormatInt(stock.Volume, 10),
+	})
+}
+
+// serveTwelveDataSearch TwelveData 代码搜索（只返回美股）
+func (s *mockQuoteServer) serveTwelveDataSearch(w http.ResponseWriter, keyword string) {
+	data := make([]map[string]string, 0)
+	for _, stock := range s.search(keyword) {
+		if getMarketType(stock.Code) != MarketUS {
+			continue
+		}
+		data = append(data, map[string]string{
+			"symbol":          stock.Code,
+			"instrument_name": stock.Name,
+			"exchange":        "NASDAQ",
+			"country":         "United States",
+		})
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{"data": data, "status": "ok"})
+}
+
+// serveNasdaqScreener Nasdaq 美股列表: {"data":{"rows":[{"symbol":"AAPL","name":"Apple Inc. Common Stock"}]}}
+func (s *mockQuoteServer) serveNasdaqScreener(w http.ResponseWriter) {
+	rows := make([]map[string]string, 0)
+	for _, stock := range s.stocks {
+		if getMarketType(stock.Code) == MarketUS {
+			rows = append(rows, map[string]string{"symbol": stock.Code, "name": stock.Name + " Common Stock"})
+		}
+	}
+	writeMockJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"rows": rows}})
+}
+
+// serveFMPQuote FMP 报价（数组格式，未找到时返回空数组）
+func (s *mockQuoteServer) serveFMPQuote(w http.ResponseWriter, symbol string) {
+	results := make([]map[string]any, 0)
+	if stock := s.lookup(symbol); stock != nil {
+		results = append(results, map[string]any{
+			"symbol":        symbol,
+			"name":          stock.Name,
+			"price":         stock.Price,
+			"previousClose": stock.PrevClose,
+			"open":          stock.Open,
+			"dayHigh":       stock.High,
+			"dayLow":        stock.Low,
+			"volume":        stock.Volume,
+		})
+	}
+	writeMockJSON(w, http.StatusOK, results)
+}
+
+// ============================================================================
+// 辅助函数
+// ============================================================================
+
+// lookup 按各数据源的代码格式查找股票（sh600000 / 1.600000 / 0700.HK / hk00700 / AAPL）
+func (s *mockQuoteServer) lookup(raw string) *mockStock {
+	return s.byCode[mockCanonicalCode(raw)]
+}
+
+// search 按代码、名称或拼音首字母匹配股票（不区分大小写）
+func (s *mockQuoteServer) search(keyword string) []*mockStock {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	if keyword == "" {
+		return nil
+	}
+
+	var result []*mockStock
+	for i := range s.stocks {
+		stock := &s.stocks[i]
+		if isFXSymbol(stock.Code) {
+			continue // 汇率不出现在股票搜索结果中
+		}
+		if strings.Contains(strings.ToLower(stock.Code), keyword) ||
+			strings.Contains(strings.ToLower(stock.Name), keyword) ||
+			strings.HasPrefix(stock.Pinyin, keyword) {
+			result = append(result, stock)
+		}
+	}
+	return result
+}
+
+// mockCanonicalCode 将各数据源的代码格式统一为标准格式（SH600000 / HK00700 / AAPL / FX:USDCNY）
+func mockCanonicalCode(raw string) string {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+
+	switch {
+	case isFXSymbol(code):
+		return code
+	case strings.HasSuffix(code, "=X"):
+		return fxSymbolPrefix + strings.TrimSuffix(code, "=X")
+	case len(code) == 7 && code[3] == '/':
+		return fxSymbolPrefix + code[:3] + code[4:]
+	case strings.HasSuffix(code, ".HK"):
+		return "HK" + padHKStockCode(strings.TrimSuffix(code, ".HK"))
+	case strings.HasPrefix(code, "116."):
+		return "HK" + padHKStockCode(strings.TrimPrefix(code, "116."))
+	case strings.HasPrefix(code, "1."):
+		return "SH" + strings.TrimPrefix(code, "1.")
+	case strings.HasPrefix(code, "0."):
+		return "SZ" + strings.TrimPrefix(code, "0.")
+	case strings.HasPrefix(code, "HK"):
+		return "HK" + padHKStockCode(strings.TrimPrefix(code, "HK"))
+	}
+
+	return convertJSONCodeToStandard(code)
+}
+
+// mockIntradayBars 按市场交易时段生成确定性的分时数据（从开盘价平滑过渡到现价）
+func mockIntradayBars(stock *mockStock) []mockMinuteBar {
+	market := getMarketType(stock.Code)
+	marketConfig := defaultMarketsConfig().China
+	switch market {
+	case MarketUS:
+		marketConfig = defaultMarketsConfig().US
+	case MarketHongKong:
+		marketConfig = defaultMarketsConfig().HongKong
+	}
+
+	date := mockTradingDate(stock).Format("20060102")
+	var minutes []time.Time
+	for _, session := range marketConfig.TradingSessions {
+		start, err := parseTimeInMarket(date, session.StartTime, marketConfig)
+		if err != nil {
+			continue
+		}
+		end, err := parseTimeInMarket(date, session.EndTime, marketConfig)
+		if err != nil {
+			continue
+		}
+		for t := start; !t.After(end); t = t.Add(time.Minute) {
+			minutes = append(minutes, t)
+		}
+	}
+
+	bars := make([]mockMinuteBar, 0, len(minutes))
+	amplitude := (stock.High - stock.Low) / 4
+	prevClose := stock.Open
+	for i, t := range minutes {
+		progress := float64(i) / float64(max(len(minutes)-1, 1))
+		price := stock.Open + (stock.Price-stock.Open)*progress
+		if i < len(minutes)-1 {
+			price += amplitude * math.Sin(float64(i)/15) * (1 - progress)
+		}
+		price = math.Max(stock.Low, math.Min(stock.High, math.Round(price*1000)/1000))
+
+		bars = append(bars, mockMinuteBar{
+			Time:   t,
+			Open:   prevClose,
+			High:   math.Max(prevClose, price),
+			Low:    math.Min(prevClose, price),
+			Close:  price,
+			Volume: stock.Volume / int64(max(len(minutes), 1)),
+		})
+		prevClose = price
+	}
+	return bars
+}
+
+// mockDividendBars 模拟除权日距最新K线的根数（除权后后复权因子从 1 变为 mockDividendFactor）
+const (
+	mockDividendBars   = 40
+	mockDividendFactor = 1.02
+)
+
+// mockDailyBars 生成截至今天（模拟交易日）的 count 根日K线（跳过周末）
+// 最新一根为当日行情，前一根收盘价为昨收；更早的价格按固定规律向前推算，除权日前价格整体高出分红比例
+func mockDailyBars(stock *mockStock, count int) []mockDailyBar {
+	count = max(count, 1)
+	bars := make([]mockDailyBar, count)
+	date := mockTradingDate(stock)
+	closePrice := stock.Price
+	for i := count - 1; i >= 0; i-- {
+		back := count - 1 - i // 距最新K线的根数
+		factor := mockDividendFactor
+		if back >= mockDividendBars {
+			factor = 1
+		}
+
+		bar := mockDailyBar{Date: date, Close: closePrice, Factor: factor}
+		if back == 0 {
+			bar.Open, bar.High, bar.Low, bar.Volume = stock.Open, stock.High, stock.Low, stock.Volume
+		} else {
+			bar.Open = math.Round(closePrice*(1-0.01*math.Sin(float64(back)/3))*1000) / 1000
+			bar.High = math.Max(bar.Open, bar.Close) * 1.008
+			bar.Low = math.Min(bar.Open, bar.Close) * 0.992
+			bar.Volume = stock.Volume * int64(80+back%40) / 100
+		}
+		bars[i] = bar
+
+		// 前一个交易日
+		date = date.AddDate(0, 0, -1)
+		for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			date = date.AddDate(0, 0, -1)
+		}
+		if back == 0 {
+			closePrice = stock.PrevClose
+		} else {
+			closePrice = math.Round(closePrice/(1+0.015*math.Sin(float64(back)/7))*1000) / 1000
+		}
+		if back+1 == mockDividendBars {
+			closePrice = math.Round(closePrice*mockDividendFactor*1000) / 1000
+		}
+	}
+	return bars
+}
+
+// mockTradingDate 返回模拟数据使用的交易日（市场时区的今天）
+func mockTradingDate(stock *mockStock) time.Time {
+	location, err := getMarketLocation(getMarketType(stock.Code))
+	if err != nil {
+		location = time.Local
+	}
+	now := time.Now().In(location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+}
+
+// mockDigits 去掉市场前缀，返回纯代码部分（SH600000 -> 600000）
+func mockDigits(code string) string {
+	if strings.HasPrefix(code, "SH") || strings.HasPrefix(code, "SZ") || strings.HasPrefix(code, "HK") {
+		return code[2:]
+	}
+	return code
+}
+
+// mockPrice 格式化价格
+func mockPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 3, 64)
+}
+
+// writeMockJSON 写入 JSON 响应
+func writeMockJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
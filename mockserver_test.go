@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// useMockQuoteServer 启动模拟行情服务器并将接口地址指向它，测试结束后恢复
+func useMockQuoteServer(t *testing.T) {
+	t.Helper()
+	server, err := startMockQuoteServer()
+	if err != nil {
+		t.Fatalf("启动模拟行情服务器失败: %v", err)
+	}
+	saved := apiEndpoints
+	apiEndpoints = mockEndpointsConfig(server.URL)
+	t.Cleanup(func() {
+		server.Close()
+		apiEndpoints = saved
+	})
+}
+
+func TestMockCanonicalCode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"sh600000", "SH600000"},
+		{"1.600000", "SH600000"},
+		{"0.000001", "SZ000001"},
+		{"116.00700", "HK00700"},
+		{"0700.HK", "HK00700"},
+		{"hk00700", "HK00700"},
+		{"600519", "SH600519"},
+		{"aapl", "AAPL"},
+	}
+
+	for _, tt := range tests {
+		if result := mockCanonicalCode(tt.input); result != tt.expected {
+			t.Errorf("mockCanonicalCode(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+// TestMockGetStockPrice 测试三个市场的报价都能离线获取
+func TestMockGetStockPrice(t *testing.T) {
+	useMockQuoteServer(t)
+	withTestRegistry(t)
+	initQuoteProviderRegistry(defaultQuoteProvidersConfig())
+
+	tests := []struct {
+		code  string
+		name  string
+		price float64
+	}{
+		{"SH600000", "浦发银行", 10.15},
+		{"HK00700", "腾讯控股", 0},
+		{"AAPL", "", 0},
+	}
+
+	for _, tt := range tests {
+		data, err := fetchQuote(context.Background(), tt.code)
+		if err != nil {
+			t.Errorf("fetchQuote(%s) 返回错误: %v", tt.code, err)
+			continue
+		}
+		if data.Price <= 0 {
+			t.Errorf("%s 价格异常: %.3f", tt.code, data.Price)
+		}
+		if tt.name != "" && data.Name != tt.name {
+			t.Errorf("%s 名称 = %q, expected %q（GBK解码）", tt.code, data.Name, tt.name)
+		}
+		if tt.price > 0 && data.Price != tt.price {
+			t.Errorf("%s 价格 = %.3f, expected %.3f", tt.code, data.Price, tt.price)
+		}
+	}
+}
+
+// TestMockBatchQuotes 测试腾讯和 Yahoo 批量接口
+func TestMockBatchQuotes(t *testing.T) {
+	useMockQuoteServer(t)
+	ctx := context.Background()
+
+	tencent, err := tryTencentBatchAPI(ctx, []string{"SH600000", "HK00700", "SZ999999"})
+	if err != nil {
+		t.Fatalf("tryTencentBatchAPI 返回错误: %v", err)
+	}
+	if tencent["SH600000"] == nil || tencent["HK00700"] == nil {
+		t.Errorf("腾讯批量结果缺失: %v", tencent)
+	}
+	if tencent["SZ999999"] != nil {
+		t.Error("未知代码不应返回结果")
+	}
+
+	yahoo, err := tryYahooBatchAPI(ctx, []string{"AAPL", "MSFT"})
+	if err != nil {
+		t.Fatalf("tryYahooBatchAPI 返回错误: %v", err)
+	}
+	if len(yahoo) != 2 {
+		t.Errorf("Yahoo 批量结果数量 = %d, expected 2", len(yahoo))
+	}
+}
+
+// TestMockSearch 测试中文名称和英文代码搜索
+func TestMockSearch(t *testing.T) {
+	useMockQuoteServer(t)
+
+	if data := searchChineseStock("浦发银行"); data == nil || data.Symbol != "SH600000" {
+		t.Errorf("searchChineseStock(浦发银行) = %+v, expected SH600000", data)
+	}
+	if data := searchStockByTwelveDataAPI("apple"); data == nil || data.Symbol != "AAPL" {
+		t.Errorf("searchStockByTwelveDataAPI(apple) = %+v, expected AAPL", data)
+	}
+}
+
+// TestMockIntraday 测试各市场分时数据离线获取
+func TestMockIntraday(t *testing.T) {
+	useMockQuoteServer(t)
+
+	for _, code := range []string{"SH600000", "SZ000001", "HK00700", "AAPL"} {
+		points, err := fetchIntradayDataFromAPI(code)
+		if err != nil {
+			t.Errorf("fetchIntradayDataFromAPI(%s) 返回错误: %v", code, err)
+			continue
+		}
+		if len(points) < 100 {
+			t.Errorf("%s 分时数据点过少: %d", code, len(points))
+		}
+	}
+}
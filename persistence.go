@@ -11,18 +11,30 @@ import (
 // Portfolio 持仓数据持久化
 // ============================================================================
 
-// savePortfolio 保存持仓数据到文件
-func (m *Model) savePortfolio() {
-	data, err := json.MarshalIndent(m.portfolio, "", "  ")
+// savePortfolio 保存当前账户的持仓数据到文件（合并视图只读，不保存），失败时记录日志并返回错误
+func (m *Model) savePortfolio() error {
+	if m.isConsolidatedView() {
+		return nil
+	}
+	if err := writePortfolio(m.currentAccount(), m.portfolio); err != nil {
+		logWarn("log.portfolio.saveFail", m.currentAccount(), err)
+		return err
+	}
+	return nil
+}
+
+// writePortfolio 将账户的持仓数据写入文件
+func writePortfolio(account string, portfolio Portfolio) error {
+	data, err := json.MarshalIndent(portfolio, "", "  ")
 	if err != nil {
-		return
+		return err
 	}
-	os.WriteFile(dataFile, data, 0644)
+	return os.WriteFile(accountFile(account), data, 0644)
 }
 
-// loadPortfolio 从文件加载持仓数据
-func loadPortfolio() Portfolio {
-	data, err := os.ReadFile(dataFile)
+// loadPortfolio 从文件加载账户的持仓数据
+func loadPortfolio(account string) Portfolio {
+	data, err := os.ReadFile(accountFile(account))
 	if err != nil {
 		return Portfolio{Stocks: []Stock{}}
 	}
@@ -32,6 +44,19 @@ func loadPortfolio() Portfolio {
 	if err != nil {
 		return Portfolio{Stocks: []Stock{}}
 	}
+
+	// 旧版持仓（只有成本价和数量）迁移为一条期初买入记录，迁移后立即保存，
+	// 使补上的结转方法固定下来（之后修改配置不影响已有卖出）
+	migrated := migratePortfolioTransactions(&portfolio)
+	for i := range portfolio.Stocks {
+		portfolio.Stocks[i].syncFromTransactions()
+	}
+	if migrated {
+		logInfo("log.ledger.migrated")
+		if err := writePortfolio(account, portfolio); err != nil {
+			logWarn("log.ledger.migrateSaveFail", account, err)
+		}
+	}
 	return portfolio
 }
 
@@ -111,13 +136,16 @@ func loadWatchlist() Watchlist {
 	return watchlist
 }
 
-// saveWatchlist 保存自选股票列表
-func (m *Model) saveWatchlist() {
+// saveWatchlist 保存自选股票列表，失败时记录日志并返回错误
+func (m *Model) saveWatchlist() error {
 	data, err := json.MarshalIndent(m.watchlist, "", "  ")
+	if err == nil {
+		err = os.WriteFile(watchlistFile, data, 0644)
+	}
 	if err != nil {
-		return
+		logWarn("log.watchlist.saveFail", err)
 	}
-	os.WriteFile(watchlistFile, data, 0644)
+	return err
 }
 
 // ============================================================================
@@ -153,6 +181,16 @@ func defaultMarketsConfig() MarketsConfig {
 	}
 }
 
+// defaultQuoteProvidersConfig 获取默认的行情数据源顺序
+func defaultQuoteProvidersConfig() QuoteProvidersConfig {
+	return QuoteProvidersConfig{
+		China:    []string{ProviderTencent, ProviderTwelveData, ProviderFMP, ProviderYahoo},
+		US:       []string{ProviderTwelveData, ProviderFMP, ProviderYahoo},
+		HongKong: []string{ProviderTencent, ProviderTwelveData, ProviderFMP, ProviderYahoo},
+		FX:       []string{ProviderYahoo, ProviderTwelveData},
+	}
+}
+
 // getDefaultConfig 获取默认配置
 func getDefaultConfig() Config {
 	return Config{
@@ -170,9 +208,9 @@ func getDefaultConfig() Config {
 			PortfolioHighlight: "yellow",       // 默认黄色
 			// 持股列表默认显示所有列（按当前顺序）
 			PortfolioColumns: []string{
-				"cursor", "code", "name", "prev_close", "open", "high",
-				"low", "price", "cost", "quantity", "today_change",
-				"position_profit", "profit_rate", "market_value",
+				"cursor", "code", "name", "currency", "prev_close", "open", "high",
+				"low", "price", "cost", "break_even", "quantity", "today_change",
+				"position_profit", "net_profit", "realized_profit", "profit_rate", "market_value",
 			},
 			// 自选列表默认显示所有列（按当前顺序）
 			WatchlistColumns: []string{
@@ -191,9 +229,45 @@ func getDefaultConfig() Config {
 			MaxConsecutiveErrors:  5,    // 最大连续错误5次
 			MinDatapoints:         20,   // 最小数据点20个
 		},
+		Providers: defaultQuoteProvidersConfig(), // 行情数据源顺序
+		Portfolio: PortfolioConfig{
+			LotMethod: LotMethodAverage,   // 默认加权平均成本
+			Account:   defaultAccountName, // 默认账户
+		},
+		Fees: defaultFeesConfig(), // 各市场交易费用
+		Currency: CurrencyConfig{
+			BaseCurrency:     CurrencyCNY, // 总计默认换算为人民币
+			FXRefreshMinutes: 30,          // 汇率每30分钟刷新
+		},
+		Alerts: AlertsConfig{
+			Bell:            true, // 触发时响铃
+			CooldownMinutes: 15,   // 同一规则15分钟内不重复提醒
+		},
+		KLine: KLineConfig{
+			Adjustment: PriceAdjustForward, // 默认前复权
+		},
+		Indicators: defaultIndicatorsConfig(),
 	}
 }
 
+// configPresence 配置文件中是否写了某些键（零值字段无法区分未配置和显式关闭）
+type configPresence struct {
+	Update *struct {
+		AutoUpdate *bool `yaml:"auto_update"`
+	} `yaml:"update"`
+	Providers *struct {
+		China    *[]string `yaml:"china"`
+		US       *[]string `yaml:"us"`
+		HongKong *[]string `yaml:"hongkong"`
+		FX       *[]string `yaml:"fx"`
+	} `yaml:"providers"`
+	Fees *struct {
+		China    *FeeSchedule `yaml:"china"`
+		US       *FeeSchedule `yaml:"us"`
+		HongKong *FeeSchedule `yaml:"hongkong"`
+	} `yaml:"fees"`
+}
+
 // loadConfig 加载配置文件
 func loadConfig() Config {
 	data, err := os.ReadFile(configFile)
@@ -216,6 +290,22 @@ func loadConfig() Config {
 		config.Display.MaxLines = 10 // 默认值
 	}
 
+	// 记录配置文件中实际写了哪些键，用来区分未配置和显式写了零值
+	var presence configPresence
+	yaml.Unmarshal(data, &presence)
+
+	// 向后兼容：未配置刷新间隔时使用默认值，超出 1-60 秒时警告并使用默认值；
+	// 未写 auto_update 时默认开启自动更新
+	if config.Update.RefreshInterval == 0 {
+		config.Update.RefreshInterval = getDefaultConfig().Update.RefreshInterval
+	} else if config.Update.RefreshInterval < minRefreshSeconds || config.Update.RefreshInterval > maxRefreshSeconds {
+		logWarn("log.config.invalidRefreshInterval", config.Update.RefreshInterval, getDefaultConfig().Update.RefreshInterval)
+		config.Update.RefreshInterval = getDefaultConfig().Update.RefreshInterval
+	}
+	if presence.Update == nil || presence.Update.AutoUpdate == nil {
+		config.Update.AutoUpdate = getDefaultConfig().Update.AutoUpdate
+	}
+
 	// 验证并设置高亮颜色的默认值
 	if config.Display.PortfolioHighlight == "" {
 		config.Display.PortfolioHighlight = "yellow" // 默认黄色背景
@@ -230,6 +320,75 @@ func loadConfig() Config {
 		logDebug("log.config.defaultMarkets", "填充默认市场配置")
 	}
 
+	// 向后兼容：未配置某个市场的数据源顺序时使用默认值（显式写成空列表表示禁用该市场的行情）
+	defaultProviders := defaultQuoteProvidersConfig()
+	if presence.Providers == nil || presence.Providers.China == nil {
+		config.Providers.China = defaultProviders.China
+	}
+	if presence.Providers == nil || presence.Providers.US == nil {
+		config.Providers.US = defaultProviders.US
+	}
+	if presence.Providers == nil || presence.Providers.HongKong == nil {
+		config.Providers.HongKong = defaultProviders.HongKong
+	}
+	if presence.Providers == nil || presence.Providers.FX == nil {
+		config.Providers.FX = defaultProviders.FX
+	}
+
+	// 验证成本结转方法（为空或无效时使用加权平均）
+	lotMethod, ok := normalizeLotMethod(config.Portfolio.LotMethod)
+	if !ok && config.Portfolio.LotMethod != "" {
+		logWarn("log.config.invalidLotMethod", config.Portfolio.LotMethod, lotMethod)
+	}
+	config.Portfolio.LotMethod = lotMethod
+
+	// 验证当前账户（账户文件已被删除时回到默认账户）
+	if config.Portfolio.Account == "" {
+		config.Portfolio.Account = defaultAccountName
+	} else if !accountExists(config.Portfolio.Account) {
+		logWarn("log.config.unknownAccount", config.Portfolio.Account)
+		config.Portfolio.Account = defaultAccountName
+	}
+
+	// 向后兼容：未配置某个市场的交易费用时使用默认费用规则（显式写成全 0 表示免费）
+	defaultFees := defaultFeesConfig()
+	if presence.Fees == nil || presence.Fees.China == nil {
+		config.Fees.China = defaultFees.China
+	}
+	if presence.Fees == nil || presence.Fees.US == nil {
+		config.Fees.US = defaultFees.US
+	}
+	if presence.Fees == nil || presence.Fees.HongKong == nil {
+		config.Fees.HongKong = defaultFees.HongKong
+	}
+
+	// 验证基准货币和汇率刷新间隔（向后兼容：未配置时使用默认值）
+	baseCurrency, ok := normalizeCurrency(config.Currency.BaseCurrency)
+	if !ok && config.Currency.BaseCurrency != "" {
+		logWarn("log.config.invalidBaseCurrency", config.Currency.BaseCurrency, baseCurrency)
+	}
+	config.Currency.BaseCurrency = baseCurrency
+	if config.Currency.FXRefreshMinutes <= 0 {
+		config.Currency.FXRefreshMinutes = getDefaultConfig().Currency.FXRefreshMinutes
+	}
+
+	// 向后兼容：未配置提醒通知时使用默认值
+	if config.Alerts == (AlertsConfig{}) {
+		config.Alerts = getDefaultConfig().Alerts
+	} else if config.Alerts.CooldownMinutes <= 0 {
+		config.Alerts.CooldownMinutes = getDefaultConfig().Alerts.CooldownMinutes
+	}
+
+	// 验证复权方式（为空或无效时使用前复权）
+	adjustment, ok := normalizePriceAdjustment(config.KLine.Adjustment)
+	if !ok && config.KLine.Adjustment != "" {
+		logWarn("log.config.invalidAdjustment", config.KLine.Adjustment, adjustment)
+	}
+	config.KLine.Adjustment = adjustment
+
+	// 验证技术指标参数（向后兼容：未配置时使用默认值）
+	config.Indicators = normalizeIndicatorsConfig(config.Indicators)
+
 	// 向后兼容：如果列配置为空，使用默认值
 	if len(config.Display.PortfolioColumns) == 0 {
 		config.Display.PortfolioColumns = getDefaultConfig().Display.PortfolioColumns
@@ -263,7 +422,9 @@ func validatePortfolioColumns(configured []string) []string {
 		"cursor": true, "code": true, "name": true, "prev_close": true,
 		"open": true, "high": true, "low": true, "price": true,
 		"cost": true, "quantity": true, "today_change": true,
-		"position_profit": true, "profit_rate": true, "market_value": true,
+		"position_profit": true, "realized_profit": true, "profit_rate": true,
+		"market_value": true, "net_profit": true, "break_even": true,
+		"currency": true,
 	}
 
 	return smartMergeRequiredColumns(configured, required, valid)
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"slices"
+)
+
+// ============================================================================
+// 行情数据源接口
+// ============================================================================
+
+// QuoteProvider 行情数据源接口
+// 新增数据源（如券商行情）只需实现该接口并注册到 quoteProviderRegistry
+type QuoteProvider interface {
+	Name() string                                                 // 数据源名称（与 config.yml 中 providers 配置对应）
+	Markets() []MarketType                                        // 支持的市场类型
+	Fetch(ctx context.Context, symbol string) (*StockData, error) // 获取单只股票报价，失败时返回错误
+}
+
+// BatchQuoteProvider 支持一次请求获取多只股票的数据源（可选接口）
+// 返回的 map 以请求时的原始代码为键，缺失的代码视为该数据源获取失败
+type BatchQuoteProvider interface {
+	QuoteProvider
+	FetchBatch(ctx context.Context, symbols []string) (map[string]*StockData, error)
+}
+
+// 内置数据源名称常量
+const (
+	ProviderTencent    = "tencent"
+	ProviderTwelveData = "twelvedata"
+	ProviderFMP        = "fmp"
+	ProviderYahoo      = "yahoo"
+)
+
+// 批量请求每次携带的最大代码数量
+const (
+	tencentBatchSize = 60 // 腾讯 qt.gtimg.cn 每次请求的代码数量
+	yahooBatchSize   = 20 // Yahoo spark 接口每次请求的代码数量
+)
+
+// ============================================================================
+// 内置数据源实现
+// ============================================================================
+
+// quoteProviderFunc 基于函数的数据源实现，用于包装现有的 tryXXXAPI 函数
+type quoteProviderFunc struct {
+	name    string
+	markets []MarketType
+	fetch   func(ctx context.Context, symbol string) (*StockData, error)
+}
+
+func (p *quoteProviderFunc) Name() string          { return p.name }
+func (p *quoteProviderFunc) Markets() []MarketType { return p.markets }
+func (p *quoteProviderFunc) Fetch(ctx context.Context, symbol string) (*StockData, error) {
+	return p.fetch(ctx, symbol)
+}
+
+// batchQuoteProviderFunc 同时支持批量获取的函数式数据源
+type batchQuoteProviderFunc struct {
+	quoteProviderFunc
+	fetchBatch func(ctx context.Context, symbols []string) (map[string]*StockData, error)
+}
+
+func (p *batchQuoteProviderFunc) FetchBatch(ctx context.Context, symbols []string) (map[string]*StockData, error) {
+	return p.fetchBatch(ctx, symbols)
+}
+
+// builtinQuoteProviders 返回所有内置数据源
+func builtinQuoteProviders() []QuoteProvider {
+	allMarkets := []MarketType{MarketChina, MarketHongKong, MarketUS, MarketFX}
+	stockMarkets := []MarketType{MarketChina, MarketHongKong, MarketUS} // FMP 不支持 FX:USDCNY 格式的汇率代码
+	return []QuoteProvider{
+		&batchQuoteProviderFunc{
+			quoteProviderFunc: quoteProviderFunc{name: ProviderTencent, markets: []MarketType{MarketChina, MarketHongKong}, fetch: tryTencentAPI},
+			fetchBatch:        tryTencentBatchAPI,
+		},
+		&quoteProviderFunc{name: ProviderTwelveData, markets: allMarkets, fetch: tryTwelveDataAPI},
+		&quoteProviderFunc{name: ProviderFMP, markets: stockMarkets, fetch: tryFMPFreeAPI},
+		&batchQuoteProviderFunc{
+			quoteProviderFunc: quoteProviderFunc{name: ProviderYahoo, markets: allMarkets, fetch: tryYahooFinanceAPI},
+			fetchBatch:        tryYahooBatchAPI,
+		},
+	}
+}
+
+// ============================================================================
+// 数据源注册表
+// ============================================================================
+
+// QuoteProviderRegistry 数据源注册表
+type QuoteProviderRegistry struct {
+	providers map[string]QuoteProvider // 按名称索引的数据源
+	order     map[MarketType][]string  // 每个市场的数据源尝试顺序
+}
+
+// 全局数据源注册表实例
+var quoteProviderRegistry *QuoteProviderRegistry
+
+// initQuoteProviderRegistry 初始化数据源注册表（注册内置数据源并应用配置顺序）
+func initQuoteProviderRegistry(config QuoteProvidersConfig) {
+	registry := &QuoteProviderRegistry{
+		providers: make(map[string]QuoteProvider),
+		order:     make(map[MarketType][]string),
+	}
+	for _, provider := range builtinQuoteProviders() {
+		registry.Register(provider)
+	}
+	registry.SetOrder(MarketChina, config.China)
+	registry.SetOrder(MarketHongKong, config.HongKong)
+	registry.SetOrder(MarketUS, config.US)
+	registry.SetOrder(MarketFX, config.FX)
+	quoteProviderRegistry = registry
+}
+
+// getQuoteProviderRegistry 获取数据源注册表（未初始化时使用默认配置）
+func getQuoteProviderRegistry() *QuoteProviderRegistry {
+	if quoteProviderRegistry == nil {
+		initQuoteProviderRegistry(defaultQuoteProvidersConfig())
+	}
+	return quoteProviderRegistry
+}
+
+// Register 注册数据源（同名数据源会被覆盖）
+func (r *QuoteProviderRegistry) Register(provider QuoteProvider) {
+	r.providers[provider.Name()] = provider
+}
+
+// SetOrder 设置某个市场的数据源尝试顺序
+func (r *QuoteProviderRegistry) SetOrder(market MarketType, names []string) {
+	r.order[market] = slices.Clone(names)
+}
+
+// ProvidersFor 按配置顺序返回某个市场可用的数据源
+// 未注册或不支持该市场的数据源会被跳过
+func (r *QuoteProviderRegistry) ProvidersFor(market MarketType) []QuoteProvider {
+	var result []QuoteProvider
+	for _, name := range r.order[market] {
+		provider, exists := r.providers[name]
+		if !exists {
+			logWarn("log.api.providerUnknown", name, market)
+			continue
+		}
+		if !slices.Contains(provider.Markets(), market) {
+			logDebug("log.api.providerUnsupported", name, market)
+			continue
+		}
+		result = append(result, provider)
+	}
+	return result
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeQuoteProvider 测试用数据源
+type fakeQuoteProvider struct {
+	name    string
+	markets []MarketType
+	data    *StockData
+	err     error
+	calls   int
+
+	mu sync.Mutex // 保护 calls（批量获取时并发调用 Fetch）
+}
+
+func (p *fakeQuoteProvider) Name() string          { return p.name }
+func (p *fakeQuoteProvider) Markets() []MarketType { return p.markets }
+func (p *fakeQuoteProvider) Fetch(ctx context.Context, symbol string) (*StockData, error) {
+	p.mu.Lock()
+	p.calls++
+	p.mu.Unlock()
+	return p.data, p.err
+}
+
+// withTestRegistry 使用仅包含指定数据源的注册表执行测试
+func withTestRegistry(t *testing.T, providers ...QuoteProvider) *QuoteProviderRegistry {
+	t.Helper()
+	saved := quoteProviderRegistry
+	t.Cleanup(func() { quoteProviderRegistry = saved })
+
+	quoteProviderRegistry = &QuoteProviderRegistry{
+		providers: make(map[string]QuoteProvider),
+		order:     make(map[MarketType][]string),
+	}
+	for _, p := range providers {
+		quoteProviderRegistry.Register(p)
+	}
+	return quoteProviderRegistry
+}
+
+// TestProvidersForOrder 测试数据源按配置顺序返回并跳过无效项
+func TestProvidersForOrder(t *testing.T) {
+	a := &fakeQuoteProvider{name: "a", markets: []MarketType{MarketUS}}
+	b := &fakeQuoteProvider{name: "b", markets: []MarketType{MarketUS, MarketChina}}
+	registry := withTestRegistry(t, a, b)
+	registry.SetOrder(MarketUS, []string{"b", "unknown", "a"})
+	registry.SetOrder(MarketChina, []string{"a", "b"})
+
+	us := registry.ProvidersFor(MarketUS)
+	if len(us) != 2 || us[0].Name() != "b" || us[1].Name() != "a" {
+		t.Errorf("美股数据源顺序错误: %v", providerNames(us))
+	}
+
+	china := registry.ProvidersFor(MarketChina)
+	if len(china) != 1 || china[0].Name() != "b" {
+		t.Errorf("A股应跳过不支持的数据源: %v", providerNames(china))
+	}
+}
+
+// TestFetchQuoteFallback 测试数据源失败时降级到下一个
+func TestFetchQuoteFallback(t *testing.T) {
+	failing := &fakeQuoteProvider{name: "failing", markets: []MarketType{MarketUS}, err: errors.New("timeout")}
+	working := &fakeQuoteProvider{name: "working", markets: []MarketType{MarketUS}, data: &StockData{Symbol: "AAPL", Price: 190.5}}
+	registry := withTestRegistry(t, failing, working)
+	registry.SetOrder(MarketUS, []string{"failing", "working"})
+
+	data, err := fetchQuote(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("fetchQuote 返回错误: %v", err)
+	}
+	if data.Price != 190.5 {
+		t.Errorf("价格 = %.2f, expected 190.50", data.Price)
+	}
+	if failing.calls != 1 || working.calls != 1 {
+		t.Errorf("调用次数异常: failing=%d, working=%d", failing.calls, working.calls)
+	}
+}
+
+// TestFetchQuoteAllFail 测试所有数据源失败时返回合并错误
+func TestFetchQuoteAllFail(t *testing.T) {
+	errA := errors.New("a down")
+	errB := errors.New("b down")
+	registry := withTestRegistry(t,
+		&fakeQuoteProvider{name: "a", markets: []MarketType{MarketUS}, err: errA},
+		&fakeQuoteProvider{name: "b", markets: []MarketType{MarketUS}, err: errB},
+	)
+	registry.SetOrder(MarketUS, []string{"a", "b"})
+
+	data, err := fetchQuote(context.Background(), "AAPL")
+	if data != nil {
+		t.Errorf("expected nil data, got %+v", data)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("错误应包含所有数据源的错误: %v", err)
+	}
+
+	registry.SetOrder(MarketUS, nil)
+	if _, err := fetchQuote(context.Background(), "AAPL"); err == nil {
+		t.Error("未配置数据源时应返回错误")
+	}
+}
+
+// fakeBatchQuoteProvider 测试用批量数据源
+type fakeBatchQuoteProvider struct {
+	fakeQuoteProvider
+	batchData  map[string]*StockData
+	batchCalls [][]string
+}
+
+func (p *fakeBatchQuoteProvider) FetchBatch(ctx context.Context, symbols []string) (map[string]*StockData, error) {
+	p.batchCalls = append(p.batchCalls, symbols)
+	result := make(map[string]*StockData)
+	for _, symbol := range symbols {
+		if data, ok := p.batchData[symbol]; ok {
+			result[symbol] = data
+		}
+	}
+	return result, nil
+}
+
+// TestFetchQuotesBatch 测试批量获取按市场分组并对缺失代码降级
+func TestFetchQuotesBatch(t *testing.T) {
+	batch := &fakeBatchQuoteProvider{
+		fakeQuoteProvider: fakeQuoteProvider{name: "batch", markets: []MarketType{MarketChina, MarketUS}},
+		batchData: map[string]*StockData{
+			"SH600000": {Symbol: "SH600000", Price: 10},
+			"SZ000001": {Symbol: "SZ000001", Price: 12},
+			"AAPL":     {Symbol: "AAPL", Price: 190},
+		},
+	}
+	single := &fakeQuoteProvider{name: "single", markets: []MarketType{MarketUS}, data: &StockData{Symbol: "MSFT", Price: 410}}
+	registry := withTestRegistry(t, batch, single)
+	registry.SetOrder(MarketChina, []string{"batch"})
+	registry.SetOrder(MarketUS, []string{"batch", "single"})
+
+	results, errs := fetchQuotesBatch(context.Background(), []string{"SH600000", "AAPL", "SZ000001", "MSFT"})
+
+	if len(batch.batchCalls) != 2 {
+		t.Fatalf("批量请求次数 = %d, expected 2 (每个市场一次)", len(batch.batchCalls))
+	}
+	for _, symbol := range []string{"SH600000", "SZ000001", "AAPL", "MSFT"} {
+		if results[symbol] == nil {
+			t.Errorf("%s 缺少结果, err=%v", symbol, errs[symbol])
+		}
+	}
+	if single.calls != 1 {
+		t.Errorf("单只数据源应只为缺失代码调用一次, got %d", single.calls)
+	}
+	if len(errs) != 0 {
+		t.Errorf("不应有错误: %v", errs)
+	}
+
+	// 单只数据源配置在前时按配置顺序使用，批量数据源不再请求已获取的代码
+	batch.batchCalls, single.calls = nil, 0
+	registry.SetOrder(MarketUS, []string{"single", "batch"})
+	fetchQuotesBatch(context.Background(), []string{"AAPL", "MSFT"})
+	if single.calls != 2 || len(batch.batchCalls) != 0 {
+		t.Errorf("应按配置顺序先使用单只数据源: single=%d, batch=%v", single.calls, batch.batchCalls)
+	}
+}
+
+func providerNames(providers []QuoteProvider) []string {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// TestLoadProvidersConfig 测试数据源配置：空列表禁用市场，未配置的市场使用默认顺序，FMP 不用于汇率
+func TestLoadProvidersConfig(t *testing.T) {
+	useTempDataDir(t)
+	if err := os.MkdirAll("cmd/conf", 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := "providers:\n  us: []\n  fx: [fmp, yahoo]\n"
+	if err := os.WriteFile(configFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	providers := loadConfig().Providers
+	defaults := defaultQuoteProvidersConfig()
+	if len(providers.US) != 0 {
+		t.Errorf("US = %v, want disabled", providers.US)
+	}
+	if !slices.Equal(providers.China, defaults.China) || !slices.Equal(providers.HongKong, defaults.HongKong) {
+		t.Errorf("未配置的市场应使用默认顺序: %+v", providers)
+	}
+
+	saved := quoteProviderRegistry
+	t.Cleanup(func() { quoteProviderRegistry = saved })
+	initQuoteProviderRegistry(providers)
+	if names := providerNames(getQuoteProviderRegistry().ProvidersFor(MarketFX)); !slices.Equal(names, []string{ProviderYahoo}) {
+		t.Errorf("FX providers = %v", names)
+	}
+	if _, err := fetchQuote(context.Background(), "AAPL"); err == nil {
+		t.Error("禁用的市场不应获取行情")
+	}
+}
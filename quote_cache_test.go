@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestQuoteCachePersistence 测试行情缓存的保存、启动加载和过期标记
+func TestQuoteCachePersistence(t *testing.T) {
+	useTempDataDir(t)
+
+	if cache := loadQuoteCache(); len(cache) != 0 {
+		t.Fatalf("没有缓存文件时应返回空缓存: %+v", cache)
+	}
+
+	quote := &StockData{Symbol: "SH600000", Name: "浦发银行", Price: 10.5, PrevClose: 10}
+	fetched := time.Now().AddDate(0, 0, -20) // 早于各市场最近一次收盘
+	m := &Model{config: getDefaultConfig(), language: English, stockPriceCache: map[string]*StockPriceCacheEntry{
+		"SH600000": {Data: quote, UpdateTime: fetched, IsUpdating: true},
+		"SZ000001": {IsUpdating: true},
+	}}
+	m.saveQuoteCache()
+
+	// 重新启动：只加载有数据的行情，且不保留更新中状态
+	cache := loadQuoteCache()
+	if len(cache) != 1 || cache["SH600000"].Data.Price != 10.5 || cache["SH600000"].IsUpdating || !cache["SH600000"].UpdateTime.Equal(fetched) {
+		t.Fatalf("loaded cache = %+v", cache)
+	}
+	m = &Model{config: getDefaultConfig(), language: English, stockPriceCache: cache}
+
+	// 过期数据仍然返回并标记获取时间，只取新数据时返回 nil
+	if m.getStockPriceFromCache("SH600000") == nil || m.getFreshStockPrice("SH600000") != nil {
+		t.Error("过期行情应继续显示，但不算作新数据")
+	}
+	if since, stale := m.quoteStaleSince("SH600000"); !stale || !since.Equal(fetched) {
+		t.Errorf("quoteStaleSince = %v, %v", since, stale)
+	}
+	watchStock := WatchlistStock{Code: "SH600000", Name: "浦发银行"}
+	row := m.GenerateWatchlistRow(&watchStock, m.getStockPriceFromCache("SH600000"), 0, 0, 1)
+	if cell := fmt.Sprint(row...); !strings.Contains(cell, "10.500") || !strings.Contains(cell, "quote.staleSince") {
+		t.Errorf("过期行情缺少标记: %s", cell)
+	}
+
+	// 更新失败时保留上次的数据
+	m.stockPriceCache["SH600000"].IsUpdating = true
+	m.Update(stockPriceUpdateMsg{Symbol: "SH600000", Error: errors.New("timeout")})
+	if data := m.getStockPriceFromCache("SH600000"); data == nil || data.Price != 10.5 {
+		t.Errorf("更新失败后 data = %+v", data)
+	}
+
+	// 更新成功后去掉标记并保存到磁盘
+	m.Update(stockPriceUpdateMsg{Symbol: "SH600000", Data: &StockData{Symbol: "SH600000", Price: 10.8, PrevClose: 10}})
+	if _, stale := m.quoteStaleSince("SH600000"); stale || m.staleQuoteMarker("SH600000") != "" {
+		t.Error("更新后不应标记为过期")
+	}
+	if cache := loadQuoteCache(); cache["SH600000"] == nil || cache["SH600000"].Data.Price != 10.8 {
+		t.Errorf("更新后未保存: %+v", cache)
+	}
+}
+
+// TestDueQuoteSymbols 测试按市场状态调度刷新：开市按间隔刷新，休市只取一次收盘价
+func TestDueQuoteSymbols(t *testing.T) {
+	quote := func(updated time.Time) *StockPriceCacheEntry {
+		return &StockPriceCacheEntry{Data: &StockData{Price: 1}, UpdateTime: updated}
+	}
+
+	// 2025-03-14 10:00 北京时间：A股、港股开市，美股（前一天 16:00 EDT 收盘）休市
+	now := time.Date(2025, 3, 14, 2, 0, 0, 0, time.UTC)
+	usClose := time.Date(2025, 3, 13, 20, 0, 0, 0, time.UTC)
+	m := &Model{config: getDefaultConfig(), stockPriceCache: map[string]*StockPriceCacheEntry{
+		"SH600000": quote(now.Add(-10 * time.Second)),
+		"AAPL":     quote(usClose.Add(5 * time.Minute)), // 已取到收盘价
+		"MSFT":     quote(usClose.Add(-time.Minute)),    // 收盘前的价格
+		"HK00700":  {Data: &StockData{Price: 1}, IsUpdating: true},
+	}}
+	due := m.dueQuoteSymbols([]string{"SH600000", "AAPL", "MSFT", "NVDA", "HK00700"}, now)
+	slices.Sort(due)
+	if !slices.Equal(due, []string{"MSFT", "NVDA", "SH600000"}) {
+		t.Errorf("due = %v", due)
+	}
+	if due := m.dueQuoteSymbols([]string{"SH600000"}, now.Add(2*time.Second)); len(due) != 0 {
+		t.Errorf("未到刷新间隔: %v", due)
+	}
+
+	// 休市后取到的收盘价不算过期，开市时超过 6 个刷新间隔算过期
+	if !m.isQuoteFresh("AAPL", usClose.Add(5*time.Minute), now) || m.isQuoteFresh("MSFT", usClose.Add(-time.Minute), now) {
+		t.Error("美股收盘价过期判断错误")
+	}
+	if m.isQuoteFresh("SH600000", now.Add(-time.Minute), now) || !m.isQuoteFresh("SH600000", now.Add(-10*time.Second), now) {
+		t.Error("A股开市时过期判断错误")
+	}
+
+	// 港股午休：上午收盘一分钟后取一次价格，之后暂停到下午开市
+	m.stockPriceCache["HK00700"] = quote(time.Date(2025, 3, 14, 3, 59, 0, 0, time.UTC))
+	if due := m.dueQuoteSymbols([]string{"HK00700"}, time.Date(2025, 3, 14, 4, 1, 30, 0, time.UTC)); len(due) != 1 {
+		t.Errorf("午休后应获取一次: %v", due)
+	}
+	m.stockPriceCache["HK00700"] = quote(time.Date(2025, 3, 14, 4, 1, 40, 0, time.UTC))
+	if due := m.dueQuoteSymbols([]string{"HK00700"}, time.Date(2025, 3, 14, 4, 30, 0, 0, time.UTC)); len(due) != 0 {
+		t.Errorf("已取到午休前价格后应暂停: %v", due)
+	}
+	if due := m.dueQuoteSymbols([]string{"HK00700"}, time.Date(2025, 3, 14, 5, 0, 0, 0, time.UTC)); len(due) != 1 {
+		t.Errorf("下午开市后应恢复刷新: %v", due)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+// ============================================================================
+// 持仓与自选数据报告（命令行 JSON 输出和 HTTP 接口共用）
+// ============================================================================
+
+// positionReport 单只持股（金额为原币）
+type positionReport struct {
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`
+	Currency       string  `json:"currency"`
+	Quantity       int     `json:"quantity"`
+	CostPrice      float64 `json:"cost_price"`
+	Price          float64 `json:"price"`
+	PrevClose      float64 `json:"prev_close"`
+	ChangePercent  float64 `json:"change_percent"`
+	MarketValue    float64 `json:"market_value"`
+	PositionProfit float64 `json:"position_profit"`
+	RealizedProfit float64 `json:"realized_profit"`
+	NetProfit      float64 `json:"net_profit"`
+	ProfitRate     float64 `json:"profit_rate"`
+}
+
+// portfolioReport 账户持仓（subtotals 为原币小计，total 为基准货币总计）
+type portfolioReport struct {
+	Account      string            `json:"account"`
+	Positions    []positionReport  `json:"positions"`
+	Subtotals    []PortfolioTotals `json:"subtotals"`
+	Total        PortfolioTotals   `json:"total"`
+	MissingRates []string          `json:"missing_rates,omitempty"`
+}
+
+// watchlistItemReport 自选股票及其行情（没有行情时 quote 为 null）
+type watchlistItemReport struct {
+	Code   string     `json:"code"`
+	Name   string     `json:"name"`
+	Market MarketType `json:"market"`
+	Tags   []string   `json:"tags"`
+	Quote  *StockData `json:"quote"`
+}
+
+// refreshFXRatesNow 同步刷新过期汇率（与持股页面相同的数据源）
+func (m *Model) refreshFXRatesNow() {
+	if fxCmd := m.refreshFXRatesCmd(); fxCmd != nil {
+		if msg, ok := fxCmd().(fxRatesUpdateMsg); ok {
+			m.handleFXRatesUpdate(msg)
+		}
+	}
+}
+
+// buildPortfolioReport 生成当前持仓报告（调用前需先用缓存更新持股价格）
+func (m *Model) buildPortfolioReport(account string) portfolioReport {
+	subtotals, total, missing := m.calculatePortfolioTotals()
+	report := portfolioReport{
+		Account:      account,
+		Positions:    []positionReport{},
+		Subtotals:    subtotals,
+		Total:        total,
+		MissingRates: missing,
+	}
+	for i := range m.portfolio.Stocks {
+		stock := &m.portfolio.Stocks[i]
+		position := positionReport{
+			Code:           stock.Code,
+			Name:           stock.Name,
+			Currency:       currencyForStock(stock.Code),
+			Quantity:       stock.Quantity,
+			CostPrice:      stock.CostPrice,
+			Price:          stock.Price,
+			PrevClose:      stock.PrevClose,
+			ChangePercent:  stock.ChangePercent,
+			RealizedProfit: stock.CalculateRealizedProfit(),
+		}
+		if stock.Price > 0 {
+			position.MarketValue = stock.Price * float64(stock.Quantity)
+			position.PositionProfit = stock.CalculatePositionProfit()
+			position.NetProfit = m.calculateNetProfit(stock)
+			if stock.CostPrice > 0 {
+				position.ProfitRate = (stock.Price - stock.CostPrice) / stock.CostPrice * 100
+			}
+		}
+		report.Positions = append(report.Positions, position)
+	}
+	return report
+}
+
+// buildWatchlistReport 生成自选股票报告（行情取自缓存）
+func (m *Model) buildWatchlistReport(stocks []WatchlistStock) []watchlistItemReport {
+	items := make([]watchlistItemReport, len(stocks))
+	for i, stock := range stocks {
+		items[i] = watchlistItemReport{
+			Code:   stock.Code,
+			Name:   stock.Name,
+			Market: stock.Market,
+			Tags:   stock.Tags,
+			Quote:  m.getStockPriceFromCache(stock.Code),
+		}
+	}
+	return items
+}
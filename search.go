@@ -0,0 +1,420 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// ============================================================================
+// 多结果股票搜索：合并各数据源的候选股票并按匹配程度排序
+// ============================================================================
+
+// SearchCandidate 搜索结果中的一只候选股票
+type SearchCandidate struct {
+	Symbol      string     `json:"symbol"`                 // 标准代码 (SH600000 / HK00700 / AAPL)
+	Name        string     `json:"name"`                   // 股票名称
+	EnglishName string     `json:"english_name,omitempty"` // 英文名称（本地代码表中的美股）
+	Market      MarketType `json:"market"`                 // 所属市场
+	Exchange    string     `json:"exchange,omitempty"`     // 交易所 (SSE / SZSE / HKEX / NASDAQ / NYSE)
+	Pinyin      string     `json:"pinyin,omitempty"`       // 名称拼音首字母，数据源未提供时为空
+}
+
+// searchQuoteAttempts 只需要一个结果时，最多尝试获取报价的候选数量
+const searchQuoteAttempts = 3
+
+// 匹配得分：代码完全一致的候选可以跳过选择列表直接打开
+const (
+	searchScoreExactSymbol = 100 // 输入规范化后与代码一致 (600000.SS → SH600000)
+	searchScoreExactName   = 90  // 名称或拼音首字母完全一致
+	searchScorePrefix      = 70  // 代码、名称或拼音首字母前缀
+	searchScoreContains    = 50  // 代码或名称包含输入
+	searchScoreFuzzy       = 20  // 输入的字符按顺序出现在代码、名称或拼音中
+	searchScoreProvider    = 1   // 数据源自己的匹配规则（英文别名等）
+)
+
+// tencentUSExchanges 腾讯美股代码后缀 → 交易所
+var tencentUSExchanges = map[string]string{"oq": "NASDAQ", "n": "NYSE", "am": "AMEX"}
+
+// searchStockCandidates 在各数据源中搜索关键词，返回去重并按匹配程度排序的候选股票
+func searchStockCandidates(keyword string) []SearchCandidate {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil
+	}
+
+	// 优先使用本地代码表，无需访问网络
+	if candidates := searchSymbolDirectory(keyword); len(candidates) > 0 {
+		logInfo("log.search.candidates", keyword, len(candidates))
+		return candidates
+	}
+
+	// 腾讯和新浪支持全部市场，TwelveData 只用于英文关键词
+	query := searchProviderQuery(keyword)
+	providers := []func(string) []SearchCandidate{fetchTencentSearchCandidates, fetchSinaSearchCandidates}
+	if !containsChineseChars(keyword) {
+		providers = append(providers, fetchTwelveDataSearchCandidates)
+	}
+
+	results := make([][]SearchCandidate, len(providers))
+	var wg sync.WaitGroup
+	for i, fetch := range providers {
+		wg.Go(func() {
+			results[i] = fetch(query)
+		})
+	}
+	wg.Wait()
+
+	candidates := mergeSearchCandidates(results...)
+
+	// 中文名称没有结果时，尝试去掉"股份"、"中国"等前后缀再搜索
+	if len(candidates) == 0 && containsChineseChars(keyword) {
+		for _, variation := range generateSearchKeywords(keyword)[1:] {
+			logDebug("log.api.tryKeywordVariation", variation)
+			if candidates = mergeSearchCandidates(fetchTencentSearchCandidates(variation)); len(candidates) > 0 {
+				break
+			}
+		}
+	}
+
+	candidates = rankSearchCandidates(keyword, candidates)
+	logInfo("log.search.candidates", keyword, len(candidates))
+	return candidates
+}
+
+// searchProviderQuery 发送给数据源的关键词：A股和港股代码只保留数字部分，兼容 600000.SS、hk700 等写法
+func searchProviderQuery(keyword string) string {
+	symbol := normalizeTickerInput(keyword)
+	if symbol != "" && getMarketType(symbol) != MarketUS {
+		return symbol[2:]
+	}
+	return keyword
+}
+
+// mergeSearchCandidates 按代码合并多个数据源的候选股票，保留先出现的顺序并补全缺失字段
+func mergeSearchCandidates(lists ...[]SearchCandidate) []SearchCandidate {
+	var merged []SearchCandidate
+	index := make(map[string]int)
+	for _, list := range lists {
+		for _, candidate := range list {
+			if candidate.Symbol == "" {
+				continue
+			}
+			i, ok := index[candidate.Symbol]
+			if !ok {
+				candidate.Market = getMarketType(candidate.Symbol)
+				index[candidate.Symbol] = len(merged)
+				merged = append(merged, candidate)
+				continue
+			}
+			existing := &merged[i]
+			if existing.Name == "" {
+				existing.Name = candidate.Name
+			}
+			if existing.Pinyin == "" {
+				existing.Pinyin = candidate.Pinyin
+			}
+			if existing.Exchange == "" {
+				existing.Exchange = candidate.Exchange
+			}
+		}
+	}
+	for i := range merged {
+		if merged[i].Exchange == "" {
+			merged[i].Exchange = exchangeForSymbol(merged[i].Symbol)
+		}
+		if merged[i].Pinyin == "" && containsChineseChars(merged[i].Name) {
+			merged[i].Pinyin = pinyinInitials(merged[i].Name)
+		}
+	}
+	return merged
+}
+
+// exchangeForSymbol 根据代码前缀推断交易所，美股无法从代码判断时返回空
+func exchangeForSymbol(symbol string) string {
+	switch {
+	case strings.HasPrefix(symbol, "SH"):
+		return "SSE"
+	case strings.HasPrefix(symbol, "SZ"):
+		return "SZSE"
+	case strings.HasPrefix(symbol, "HK"):
+		return "HKEX"
+	}
+	return ""
+}
+
+// rankSearchCandidates 按匹配得分从高到低排序，得分相同时保持数据源返回的顺序
+func rankSearchCandidates(keyword string, candidates []SearchCandidate) []SearchCandidate {
+	matcher := newSearchMatcher(keyword)
+	scores := make(map[string]int, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate.Symbol] = matcher.score(candidate)
+	}
+	slices.SortStableFunc(candidates, func(a, b SearchCandidate) int {
+		return scores[b.Symbol] - scores[a.Symbol]
+	})
+	return candidates
+}
+
+// searchMatcher 预处理后的搜索关键词（本地代码表逐条匹配时避免重复规范化）
+type searchMatcher struct {
+	keyword string // 小写、去掉空白
+	symbol  string // 规范化后的代码，不像代码时为空
+}
+
+func newSearchMatcher(keyword string) searchMatcher {
+	return searchMatcher{
+		keyword: strings.ToLower(strings.Join(strings.Fields(keyword), "")),
+		symbol:  normalizeTickerInput(keyword),
+	}
+}
+
+// score 计算候选股票与关键词的匹配得分
+// 依次比较规范化代码、名称（含英文名称）、拼音首字母，最后按字符顺序做模糊匹配
+func (s searchMatcher) score(candidate SearchCandidate) int {
+	if s.keyword == "" {
+		return 0
+	}
+	if s.symbol != "" && s.symbol == candidate.Symbol {
+		return searchScoreExactSymbol
+	}
+
+	keyword := s.keyword
+	symbol := strings.ToLower(candidate.Symbol)
+	code := strings.TrimLeft(symbol, "shzk") // 去掉市场前缀的数字代码
+	if candidate.Market == MarketUS {
+		code = symbol
+	}
+	name := strings.ToLower(candidate.Name)
+	englishName := strings.ToLower(candidate.EnglishName)
+	pinyin := strings.ToLower(candidate.Pinyin)
+
+	switch {
+	case keyword == code || keyword == name || keyword == englishName || keyword == pinyin:
+		return searchScoreExactName
+	case strings.HasPrefix(symbol, keyword) || strings.HasPrefix(code, keyword) ||
+		strings.HasPrefix(name, keyword) || strings.HasPrefix(englishName, keyword) || strings.HasPrefix(pinyin, keyword):
+		return searchScorePrefix
+	case strings.Contains(symbol, keyword) || strings.Contains(name, keyword) || strings.Contains(englishName, keyword):
+		return searchScoreContains
+	case isSubsequence(keyword, symbol) || isSubsequence(keyword, name) || isSubsequence(keyword, pinyin):
+		return searchScoreFuzzy
+	}
+	return searchScoreProvider
+}
+
+// isExactSymbolMatch 判断输入规范化后是否就是候选股票的代码
+func isExactSymbolMatch(keyword string, candidate SearchCandidate) bool {
+	symbol := normalizeTickerInput(keyword)
+	return symbol != "" && symbol == candidate.Symbol
+}
+
+// isSubsequence 判断 needle 的字符是否按顺序出现在 haystack 中（忽略中间字符）
+func isSubsequence(needle, haystack string) bool {
+	if needle == "" || haystack == "" {
+		return false
+	}
+	remaining := []rune(needle)
+	for _, r := range haystack {
+		if r == remaining[0] {
+			remaining = remaining[1:]
+			if len(remaining) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeTickerInput 将各种代码写法规范化为标准代码，不像代码的输入返回空
+// 600000.SS / 600000.SH / sh600000 / 600000 → SH600000，700.HK / hk700 → HK00700，aapl → AAPL
+func normalizeTickerInput(input string) string {
+	code := strings.ToUpper(strings.Join(strings.Fields(input), ""))
+	if code == "" || containsChineseChars(code) {
+		return ""
+	}
+
+	if base, suffix, ok := strings.Cut(code, "."); ok && isDigits(base) {
+		switch suffix {
+		case "SS", "SH":
+			code = "SH" + base
+		case "SZ":
+			code = "SZ" + base
+		case "HK":
+			code = "HK" + base
+		default:
+			return ""
+		}
+	}
+
+	switch {
+	case (strings.HasPrefix(code, "SH") || strings.HasPrefix(code, "SZ")) && len(code) == 8 && isDigits(code[2:]):
+		return code
+	case strings.HasPrefix(code, "HK") && len(code) > 2 && len(code) <= 7 && isDigits(code[2:]):
+		return "HK" + padHKStockCode(strings.TrimLeft(code[2:], "0"))
+	case len(code) == 6 && isDigits(code):
+		if symbol := convertJSONCodeToStandard(code); symbol != code {
+			return symbol
+		}
+		return ""
+	case len(code) <= 5 && isDigits(code):
+		return "HK" + padHKStockCode(code)
+	}
+
+	// 美股代码: 字母开头，可带 . 或 - (BRK.B)
+	for i, r := range code {
+		if !(unicode.IsUpper(r) || (i > 0 && (unicode.IsDigit(r) || r == '.' || r == '-'))) {
+			return ""
+		}
+	}
+	return code
+}
+
+// isDigits 判断字符串是否全部由数字组成
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteBestCandidate 按匹配程度依次获取候选股票的报价，返回第一只有效的（用于只需要一个结果的场景）
+func quoteBestCandidate(keyword string, candidates []SearchCandidate) *StockData {
+	candidates = rankSearchCandidates(keyword, mergeSearchCandidates(candidates))
+	for _, candidate := range candidates[:min(searchQuoteAttempts, len(candidates))] {
+		if data := quoteSearchCandidate(candidate); data != nil {
+			return data
+		}
+	}
+	return nil
+}
+
+// quoteSearchCandidate 获取候选股票的报价，名称使用搜索结果中的名称
+func quoteSearchCandidate(candidate SearchCandidate) *StockData {
+	logDebug("log.search.quote", candidate.Name, candidate.Symbol)
+	data := getStockPrice(candidate.Symbol)
+	if data == nil || data.Price <= 0 {
+		return nil
+	}
+	data.Symbol = candidate.Symbol
+	if candidate.Name != "" {
+		data.Name = candidate.Name
+	}
+	return data
+}
+
+// ============================================================================
+// 搜索结果选择列表
+// ============================================================================
+
+// enterSearchPicking 显示候选股票列表供用户选择
+func (m *Model) enterSearchPicking(candidates []SearchCandidate) {
+	m.searchCandidates = candidates
+	m.searchCandidateCursor = 0
+	m.searchCandidateScroll = 0
+	m.state = SearchPicking
+	m.message = ""
+}
+
+// searchPickerPageSize 选择列表每页显示的候选数量
+func (m *Model) searchPickerPageSize() int {
+	if m.config.Display.MaxLines > 0 {
+		return m.config.Display.MaxLines
+	}
+	return 10
+}
+
+// moveSearchCandidateCursor 移动选择光标并保持光标在可见范围内
+func (m *Model) moveSearchCandidateCursor(delta int) {
+	if len(m.searchCandidates) == 0 {
+		return
+	}
+	m.searchCandidateCursor = max(0, min(len(m.searchCandidates)-1, m.searchCandidateCursor+delta))
+
+	pageSize := m.searchPickerPageSize()
+	if m.searchCandidateCursor < m.searchCandidateScroll {
+		m.searchCandidateScroll = m.searchCandidateCursor
+	} else if m.searchCandidateCursor >= m.searchCandidateScroll+pageSize {
+		m.searchCandidateScroll = m.searchCandidateCursor - pageSize + 1
+	}
+}
+
+func (m *Model) handleSearchPicking(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		// 返回搜索输入，保留关键词方便修改
+		m.state = SearchingStock
+		m.searchCandidates = nil
+		m.message = ""
+	case "up", "k":
+		m.moveSearchCandidateCursor(-1)
+	case "down", "j":
+		m.moveSearchCandidateCursor(1)
+	case "pgup":
+		m.moveSearchCandidateCursor(-m.searchPickerPageSize())
+	case "pgdown":
+		m.moveSearchCandidateCursor(m.searchPickerPageSize())
+	case "home":
+		m.moveSearchCandidateCursor(-len(m.searchCandidates))
+	case "end":
+		m.moveSearchCandidateCursor(len(m.searchCandidates))
+	case "enter":
+		if len(m.searchCandidates) == 0 {
+			return m, nil
+		}
+		candidate := m.searchCandidates[m.searchCandidateCursor]
+		m.searchResult = quoteSearchCandidate(candidate)
+		if m.searchResult == nil {
+			m.message = fmt.Sprintf(m.getText("search.quoteFail"), candidate.Name, candidate.Symbol)
+			return m, nil
+		}
+		m.searchCandidates = nil
+		return m, m.openSearchResult()
+	}
+	return m, nil
+}
+
+func (m *Model) viewSearchPicking() string {
+	s := fmt.Sprintf(m.getText("search.pickTitle"), m.searchInput) + "\n\n"
+
+	pageSize := m.searchPickerPageSize()
+	end := min(len(m.searchCandidates), m.searchCandidateScroll+pageSize)
+	s += fmt.Sprintf(m.getText("search.pickCount"), len(m.searchCandidates), m.searchCandidateScroll+1, end) + "\n"
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"", m.getText("col.code"), m.getText("col.name"), m.getText("market"), m.getText("search.exchange")})
+	for i := m.searchCandidateScroll; i < end; i++ {
+		candidate := m.searchCandidates[i]
+		prefix := ""
+		if i == m.searchCandidateCursor {
+			prefix = "►"
+		}
+		name := candidate.Name
+		if candidate.EnglishName != "" && candidate.EnglishName != name {
+			name += " / " + candidate.EnglishName
+		}
+		exchange := candidate.Exchange
+		if exchange == "" {
+			exchange = "-"
+		}
+		t.AppendRow(table.Row{prefix, candidate.Symbol, name, m.getMarketTagName(candidate.Market), exchange})
+	}
+	s += t.Render() + "\n"
+
+	s += "\n" + m.getText("search.pickHelp") + "\n"
+	if m.message != "" {
+		s += "\n" + m.message + "\n"
+	}
+	return s
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestParseSearchCandidates 测试各数据源搜索结果解析为完整的候选列表
+func TestParseSearchCandidates(t *testing.T) {
+	hint := parseSearchResults(`v_hint="sh~600000~\u6d66\u53d1\u94f6\u884c~pfyh~GP-A^hk~700~\u817e\u8baf\u63a7\u80a1~txkg~GP^us~aapl.oq~\u82f9\u679c~pg~GP^jj~000001~fund~jj~JJ";`)
+	if len(hint) != 3 {
+		t.Fatalf("hint candidates = %+v", hint)
+	}
+	if hint[0].Symbol != "SH600000" || hint[0].Name != "浦发银行" || hint[0].Pinyin != "pfyh" {
+		t.Errorf("A股候选 = %+v", hint[0])
+	}
+	if hint[1].Symbol != "HK00700" || hint[2].Symbol != "AAPL" || hint[2].Exchange != "NASDAQ" {
+		t.Errorf("港股/美股候选 = %+v, %+v", hint[1], hint[2])
+	}
+	if parseSearchResults(`v_hint="N";`) != nil {
+		t.Error("无结果时不应返回候选")
+	}
+
+	// 新浪简化格式和完整格式
+	sina := parseSinaSearchResults(`var suggestvalue="sh600000,浦发银行;sz000001,平安银行";`)
+	if len(sina) != 2 || sina[0].Symbol != "SH600000" || sina[1].Name != "平安银行" {
+		t.Errorf("新浪简化格式 = %+v", sina)
+	}
+	sina = parseSinaSearchResults(`var suggestvalue="浦发银行,11,600000,sh600000,浦发银行,,浦发银行,99,1,ESG,,;腾讯控股,31,00700,00700,腾讯控股,,腾讯控股,99,1,,,;apple,41,aapl,aapl,苹果,,苹果,99,1,,,";`)
+	if len(sina) != 3 || sina[0].Symbol != "SH600000" || sina[1].Symbol != "HK00700" || sina[2].Symbol != "AAPL" {
+		t.Errorf("新浪完整格式 = %+v", sina)
+	}
+}
+
+// TestNormalizeTickerInput 测试各种代码写法的规范化
+func TestNormalizeTickerInput(t *testing.T) {
+	for input, want := range map[string]string{
+		"600000.ss": "SH600000",
+		"000001.SZ": "SZ000001",
+		"sh600000":  "SH600000",
+		"600519":    "SH600519",
+		"hk700":     "HK00700",
+		"0700.HK":   "HK00700",
+		"700":       "HK00700",
+		" aapl ":    "AAPL",
+		"BRK.B":     "BRK.B",
+		"浦发银行":      "",
+		"830799":    "",
+	} {
+		if got := normalizeTickerInput(input); got != want {
+			t.Errorf("normalizeTickerInput(%q) = %q, expected %q", input, got, want)
+		}
+	}
+}
+
+// TestSearchStockCandidates 测试多数据源合并、拼音首字母和模糊代码匹配
+func TestSearchStockCandidates(t *testing.T) {
+	useMockQuoteServer(t)
+
+	banks := searchStockCandidates("银行")
+	if len(banks) != 3 {
+		t.Fatalf("银行 candidates = %+v", banks)
+	}
+	for _, candidate := range banks {
+		if candidate.Market != MarketChina || candidate.Exchange == "" || candidate.Pinyin == "" {
+			t.Errorf("候选字段不完整: %+v", candidate)
+		}
+	}
+
+	if result := searchStockCandidates("pfyh"); len(result) == 0 || result[0].Symbol != "SH600000" {
+		t.Errorf("pfyh candidates = %+v", result)
+	}
+	if result := searchStockCandidates("apple"); len(result) == 0 || result[0].Symbol != "AAPL" || result[0].Exchange != "NASDAQ" {
+		t.Errorf("apple candidates = %+v", result)
+	}
+	for _, input := range []string{"600000.ss", "hk700"} {
+		result := searchStockCandidates(input)
+		if len(result) == 0 || !isExactSymbolMatch(input, result[0]) {
+			t.Errorf("%s candidates = %+v", input, result)
+		}
+	}
+
+	// 拼音首字母模糊匹配：gsh → 工商银行 (gsyh)
+	ranked := rankSearchCandidates("gsh", mergeSearchCandidates(banks))
+	if ranked[0].Symbol != "SH601398" {
+		t.Errorf("模糊匹配排序 = %+v", ranked)
+	}
+}
+
+// TestSearchPicking 测试搜索多个结果时的选择列表
+func TestSearchPicking(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	m := &Model{config: getDefaultConfig(), language: English, state: SearchingStock, searchInput: "银行"}
+	m.handleSearchingStock(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SearchPicking || len(m.searchCandidates) != 3 {
+		t.Fatalf("state = %v, candidates = %d", m.state, len(m.searchCandidates))
+	}
+
+	view := m.viewSearchPicking()
+	for _, want := range []string{"浦发银行", "工商银行", "平安银行", "SZSE", "►"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("选择列表缺少 %q:\n%s", want, view)
+		}
+	}
+
+	// 光标不超出列表，ESC 返回搜索并保留关键词
+	for range 5 {
+		m.handleSearchPicking(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if m.searchCandidateCursor != 2 {
+		t.Errorf("cursor = %d", m.searchCandidateCursor)
+	}
+	m.handleSearchPicking(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != SearchingStock || m.searchInput != "银行" {
+		t.Fatalf("state = %v, input = %q", m.state, m.searchInput)
+	}
+
+	// 选择第二个候选后进入搜索结果页
+	m.handleSearchingStock(tea.KeyMsg{Type: tea.KeyEnter})
+	m.handleSearchPicking(tea.KeyMsg{Type: tea.KeyDown})
+	selected := m.searchCandidates[1]
+	_, cmd := m.handleSearchPicking(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SearchResultWithActions || m.searchResult == nil || m.searchResult.Symbol != selected.Symbol || cmd == nil {
+		t.Errorf("state = %v, result = %+v", m.state, m.searchResult)
+	}
+	m.stopSearchIntradayWorker()
+
+	// 代码完全一致时直接打开，不显示选择列表
+	m.state, m.searchInput = SearchingStock, "600000.ss"
+	m.handleSearchingStock(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != SearchResultWithActions || m.searchResult.Symbol != "SH600000" {
+		t.Errorf("state = %v, result = %+v", m.state, m.searchResult)
+	}
+	m.stopSearchIntradayWorker()
+}
+
+// TestSearchIntradayWorker 测试搜索模式 worker 通过消息更新数据，停止后不阻塞且忽略旧数据
+func TestSearchIntradayWorker(t *testing.T) {
+	useMockQuoteServer(t)
+
+	m := &Model{config: getDefaultConfig(), language: English, isSearchMode: true, searchResult: &StockData{Symbol: "SH600000", PrevClose: 10}}
+	msg, ok := m.startSearchIntradayWorker("SH600000", "浦发银行", "2025-03-14")().(searchIntradayUpdateMsg)
+	if !ok || msg.data == nil {
+		t.Fatalf("首次获取 msg = %+v", msg)
+	}
+	if m.searchIntradayData != nil {
+		t.Error("worker 不应直接修改 Model")
+	}
+	m.Update(msg)
+	if data := m.searchIntradayData; data == nil || data.Code != "SH600000" || data.PrevClose != 10 || len(data.Datapoints) == 0 {
+		t.Fatalf("searchIntradayData = %+v", data)
+	}
+
+	// 停止后 worker 退出并关闭 channel，之前发出的消息不再修改数据
+	m.stopSearchIntradayWorker()
+	if next := waitForSearchIntradayUpdate(msg.updates)(); next != nil {
+		t.Errorf("停止后收到消息: %+v", next)
+	}
+	m.Update(msg)
+	if m.searchIntradayData != nil {
+		t.Error("已停止的 worker 的数据应被忽略")
+	}
+}
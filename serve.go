@@ -0,0 +1,463 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ============================================================================
+// serve: 本地 HTTP/JSON 接口（行情、持仓、自选、分时数据和 SSE 行情推送）
+// ============================================================================
+
+const (
+	defaultServeAddr     = "127.0.0.1:8421" // 默认只监听本机
+	sseHeartbeatInterval = 15 * time.Second // SSE 心跳间隔（防止代理断开空闲连接）
+	sseClientBuffer      = 64               // 每个 SSE 客户端的事件缓冲（客户端过慢时丢弃）
+	serveShutdownTimeout = 5 * time.Second  // 退出时等待请求结束的时间
+)
+
+// quoteEvent SSE 推送的行情更新
+type quoteEvent struct {
+	Symbol     string     `json:"symbol"`
+	Quote      *StockData `json:"quote"`
+	UpdateTime time.Time  `json:"update_time"`
+}
+
+// quotesResponse /quotes 响应（errors 为获取失败的代码及原因）
+type quotesResponse struct {
+	Quotes []*StockData      `json:"quotes"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// apiServer 接口服务（共用 TUI 的行情缓存、持仓计算和分时数据存储）
+type apiServer struct {
+	m  *Model
+	mu sync.RWMutex // 保护 m 的配置、持仓、自选列表、汇率和分时采集（行情缓存由 stockPriceMutex 保护）
+
+	subMu       sync.Mutex
+	subscribers map[chan quoteEvent]struct{}
+
+	intraday *intradaySupervisor // 分时采集（仅刷新循环使用）
+}
+
+// newAPIServer 创建接口服务
+func newAPIServer(m *Model) *apiServer {
+	return &apiServer{
+		m:           m,
+		subscribers: make(map[chan quoteEvent]struct{}),
+	}
+}
+
+// routes 注册接口路由
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /quotes", s.handleQuotes)
+	mux.HandleFunc("GET /portfolio", s.handlePortfolio)
+	mux.HandleFunc("GET /watchlist", s.handleWatchlist)
+	mux.HandleFunc("GET /intraday/{code}/{date}", s.handleIntraday)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	return mux
+}
+
+func (c *cliCommand) runServe(args []string) int {
+	fs := c.newFlagSet("serve")
+	addr := fs.String("addr", defaultServeAddr, "listen address")
+	accountFlag := fs.String("account", "", "account name")
+	if _, err := parseFlags(fs, args); err != nil {
+		return c.usageError(err)
+	}
+	if _, ok := c.resolveAccount(*accountFlag); !ok {
+		return exitUsage
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		c.failf("serve.listenFail", *addr, err)
+		return exitFailure
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := newAPIServer(c.m)
+	c.m.intradayManager = newIntradayManager(c.m)
+	s.intraday = newIntradaySupervisor(c.m.intradayManager)
+	server := &http.Server{
+		Handler: s.routes(),
+		// 请求上下文随退出信号取消，SSE 连接可以及时结束
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go s.run(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logInfo("log.server.started", listener.Addr())
+	fmt.Fprintf(c.stderr, c.m.getText("serve.listening")+"\n", listener.Addr())
+	err = server.Serve(listener)
+	close(c.m.intradayManager.cancelChan)
+	if !errors.Is(err, http.ErrServerClosed) {
+		c.failf("serve.listenFail", *addr, err)
+		return exitFailure
+	}
+	logInfo("log.server.stopped")
+	return exitOK
+}
+
+// ============================================================================
+// 行情刷新与推送
+// ============================================================================
+
+// run 按配置的刷新间隔刷新持仓和自选股票的行情（只刷新开市的市场），并保持分时数据采集
+func (s *apiServer) run(ctx context.Context) {
+	ticker := time.NewTicker(s.m.quoteRefreshInterval())
+	defer ticker.Stop()
+	for {
+		symbols, names := s.trackedStocks()
+		s.mu.RLock()
+		s.intraday.sync(names, time.Now())
+		// 关闭自动更新时只在请求时获取缓存中没有或已过期的行情
+		var due []string
+		if s.m.config.Update.AutoUpdate {
+			due = s.m.dueQuoteSymbols(symbols, time.Now())
+		}
+		s.mu.RUnlock()
+		if len(due) > 0 {
+			s.fetch(ctx, due)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// trackedStocks 重新加载当前账户持仓和自选列表（界面或命令行的修改随之生效），返回去重后的代码和名称
+func (s *apiServer) trackedStocks() ([]string, map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.portfolio = loadAccountPortfolio(s.m.currentAccount())
+	s.m.watchlist = loadWatchlist()
+
+	names := make(map[string]string)
+	var symbols []string
+	add := func(code, name string) {
+		if _, exists := names[code]; !exists {
+			symbols = append(symbols, code)
+		}
+		names[code] = name
+	}
+	for _, stock := range s.m.portfolio.Stocks {
+		add(stock.Code, stock.Name)
+	}
+	for _, stock := range s.m.watchlist.Stocks {
+		add(stock.Code, stock.Name)
+	}
+	return symbols, names
+}
+
+// fetch 按市场分组并发获取行情，每组返回后立即写入缓存并推送给订阅者
+func (s *apiServer) fetch(ctx context.Context, symbols []string) map[string]error {
+	ctx, cancel := context.WithTimeout(ctx, cliQuoteTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	failures := make(map[string]error)
+	for _, group := range groupSymbolsByMarket(symbols) {
+		wg.Go(func() {
+			results, errs := fetchQuotesBatch(ctx, group)
+			now := time.Now()
+			s.m.stockPriceMutex.Lock()
+			for symbol, data := range results {
+				s.m.stockPriceCache[symbol] = &StockPriceCacheEntry{Data: data, UpdateTime: now}
+			}
+			s.m.stockPriceMutex.Unlock()
+
+			for _, symbol := range group {
+				if data, ok := results[symbol]; ok {
+					s.publish(quoteEvent{Symbol: symbol, Quote: data, UpdateTime: now})
+				}
+			}
+			if len(errs) > 0 {
+				errMu.Lock()
+				for symbol, err := range errs {
+					logWarn("log.server.fetchFail", symbol, err)
+					failures[symbol] = err
+				}
+				errMu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return failures
+}
+
+// ensureQuotes 获取缓存中没有或已过期的行情
+func (s *apiServer) ensureQuotes(ctx context.Context, symbols []string) map[string]error {
+	var stale []string
+	for _, symbol := range symbols {
+		if s.m.getFreshStockPrice(symbol) == nil {
+			stale = append(stale, symbol)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return s.fetch(ctx, stale)
+}
+
+// subscribe 注册 SSE 订阅者
+func (s *apiServer) subscribe() chan quoteEvent {
+	ch := make(chan quoteEvent, sseClientBuffer)
+	s.subMu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.subMu.Unlock()
+	return ch
+}
+
+func (s *apiServer) unsubscribe(ch chan quoteEvent) {
+	s.subMu.Lock()
+	delete(s.subscribers, ch)
+	s.subMu.Unlock()
+}
+
+// publish 推送行情更新（订阅者缓冲已满时丢弃，不阻塞刷新）
+func (s *apiServer) publish(event quoteEvent) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logDebug("log.server.eventDropped", event.Symbol)
+		}
+	}
+}
+
+// ============================================================================
+// 接口处理
+// ============================================================================
+
+// writeAPIJSON 输出 JSON 响应
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		logWarn("log.server.writeFail", err)
+	}
+}
+
+// writeAPIError 输出 {"error": "..."} 错误响应
+func (s *apiServer) writeAPIError(w http.ResponseWriter, status int, key string, args ...any) {
+	writeAPIJSON(w, status, map[string]string{"error": fmt.Sprintf(s.m.getText(key), args...)})
+}
+
+// parseSymbols 解析逗号分隔的股票代码（转为大写并去重）
+func parseSymbols(value string) []string {
+	var symbols []string
+	for _, symbol := range strings.Split(value, ",") {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !slices.Contains(symbols, symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// handleQuotes GET /quotes?symbols=SH600000,AAPL（不指定代码时返回缓存中的全部行情）
+func (s *apiServer) handleQuotes(w http.ResponseWriter, r *http.Request) {
+	symbols := parseSymbols(r.URL.Query().Get("symbols"))
+	if len(symbols) == 0 {
+		s.m.stockPriceMutex.RLock()
+		for symbol := range s.m.stockPriceCache {
+			symbols = append(symbols, symbol)
+		}
+		s.m.stockPriceMutex.RUnlock()
+		slices.Sort(symbols)
+	}
+
+	response := quotesResponse{Quotes: []*StockData{}}
+	for symbol, err := range s.ensureQuotes(r.Context(), symbols) {
+		if response.Errors == nil {
+			response.Errors = make(map[string]string)
+		}
+		response.Errors[symbol] = err.Error()
+	}
+	for _, symbol := range symbols {
+		if data := s.m.getStockPriceFromCache(symbol); data != nil {
+			response.Quotes = append(response.Quotes, data)
+		}
+	}
+	writeAPIJSON(w, http.StatusOK, response)
+}
+
+// handlePortfolio GET /portfolio?account=NAME（默认为启动时的账户）
+func (s *apiServer) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	account := r.URL.Query().Get("account")
+	if account == "" {
+		s.mu.RLock()
+		account = s.m.currentAccount()
+		s.mu.RUnlock()
+	} else if !accountExists(account) {
+		s.writeAPIError(w, http.StatusNotFound, "cli.unknownAccount", account)
+		return
+	}
+
+	portfolio := loadAccountPortfolio(account)
+	symbols := make([]string, len(portfolio.Stocks))
+	for i, stock := range portfolio.Stocks {
+		symbols[i] = stock.Code
+	}
+	s.ensureQuotes(r.Context(), symbols)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := s.m.config.Portfolio.Account
+	s.m.config.Portfolio.Account = account
+	s.m.portfolio = portfolio
+	s.m.updatePortfolioPricesFromCache()
+	s.m.refreshFXRatesNow()
+	report := s.m.buildPortfolioReport(account)
+	s.m.config.Portfolio.Account = current
+	writeAPIJSON(w, http.StatusOK, report)
+}
+
+// handleWatchlist GET /watchlist?tag=TAG
+func (s *apiServer) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.m.watchlist = loadWatchlist()
+	s.m.selectedTag = strings.TrimSpace(r.URL.Query().Get("tag"))
+	stocks := s.m.getFilteredWatchlist()
+	s.mu.Unlock()
+
+	symbols := make([]string, len(stocks))
+	for i, stock := range stocks {
+		symbols[i] = stock.Code
+	}
+	s.ensureQuotes(r.Context(), symbols)
+
+	s.mu.RLock()
+	items := s.m.buildWatchlistReport(stocks)
+	s.mu.RUnlock()
+	writeAPIJSON(w, http.StatusOK, items)
+}
+
+// handleIntraday GET /intraday/{code}/{date}（日期格式 YYYYMMDD 或 YYYY-MM-DD）
+// 当日数据不存在时启动采集，客户端稍后重试即可
+func (s *apiServer) handleIntraday(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(r.PathValue("code"))
+	date := strings.ReplaceAll(r.PathValue("date"), "-", "")
+	if _, err := time.Parse("20060102", date); err != nil {
+		s.writeAPIError(w, http.StatusBadRequest, "serve.invalidDate", r.PathValue("date"))
+		return
+	}
+
+	s.mu.RLock()
+	data, err := s.m.loadIntradayDataForDate(code, "", date)
+	if err != nil && errors.Is(err, os.ErrNotExist) && date == time.Now().Format("20060102") && s.m.intradayManager != nil {
+		if err := s.m.intradayManager.StartCollection(code, ""); err != nil {
+			logWarn("log.server.intradayFail", code, err)
+		}
+	}
+	s.mu.RUnlock()
+
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logWarn("log.server.intradayLoadFail", code, date, err)
+			s.writeAPIError(w, http.StatusInternalServerError, "serve.intradayLoadFail", code, date)
+			return
+		}
+		s.writeAPIError(w, http.StatusNotFound, "serve.intradayNotFound", code, date)
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, data)
+}
+
+// handleEvents GET /events?symbols=SH600000,AAPL（Server-Sent Events，不指定代码时推送全部）
+// 连接后先推送缓存中的当前行情，之后每次行情更新推送一条 quote 事件
+func (s *apiServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeAPIError(w, http.StatusInternalServerError, "serve.streamUnsupported")
+		return
+	}
+	symbols := parseSymbols(r.URL.Query().Get("symbols"))
+	wanted := func(symbol string) bool {
+		return len(symbols) == 0 || slices.Contains(symbols, symbol)
+	}
+
+	events := s.subscribe()
+	defer s.unsubscribe(events)
+	logInfo("log.server.clientConnected", r.RemoteAddr, strings.Join(symbols, ","))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event quoteEvent) bool {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "event: quote\ndata: %s\n\n", payload); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	var initial []quoteEvent
+	s.m.stockPriceMutex.RLock()
+	for symbol, entry := range s.m.stockPriceCache {
+		if entry.Data != nil && wanted(symbol) {
+			initial = append(initial, quoteEvent{Symbol: symbol, Quote: entry.Data, UpdateTime: entry.UpdateTime})
+		}
+	}
+	s.m.stockPriceMutex.RUnlock()
+	for _, event := range initial {
+		if !send(event) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			logInfo("log.server.clientDisconnected", r.RemoteAddr)
+			return
+		case event := <-events:
+			if wanted(event.Symbol) && !send(event) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
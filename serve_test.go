@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestAPIServer 在临时数据目录中启动接口服务（使用模拟行情）
+func newTestAPIServer(t *testing.T) (*apiServer, *httptest.Server) {
+	t.Helper()
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+	withTestRegistry(t)
+	initQuoteProviderRegistry(defaultQuoteProvidersConfig())
+
+	s := newAPIServer(&Model{
+		config:          getDefaultConfig(),
+		language:        English,
+		stockPriceCache: make(map[string]*StockPriceCacheEntry),
+		fxRates:         loadFXRates(),
+	})
+	server := httptest.NewServer(s.routes())
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+// getAPIJSON 请求接口并解析 JSON 响应
+func getAPIJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s 响应无法解析: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func TestServeQuotes(t *testing.T) {
+	_, server := newTestAPIServer(t)
+
+	var result quotesResponse
+	status := getAPIJSON(t, server.URL+"/quotes?symbols=sh600000,AAPL,NOPE123", &result)
+	if status != http.StatusOK || len(result.Quotes) != 2 {
+		t.Fatalf("status = %d, quotes = %+v", status, result.Quotes)
+	}
+	if result.Quotes[0].Name != "浦发银行" || result.Quotes[1].Price <= 0 {
+		t.Errorf("行情 = %+v, %+v", result.Quotes[0], result.Quotes[1])
+	}
+	if _, failed := result.Errors["NOPE123"]; !failed || len(result.Errors) != 1 {
+		t.Errorf("errors = %v, expected NOPE123 only", result.Errors)
+	}
+
+	// 不指定代码时返回缓存中的全部行情
+	result = quotesResponse{}
+	getAPIJSON(t, server.URL+"/quotes", &result)
+	if len(result.Quotes) != 2 || result.Quotes[0].Symbol > result.Quotes[1].Symbol {
+		t.Errorf("缓存行情 = %+v", result.Quotes)
+	}
+}
+
+func TestServePortfolio(t *testing.T) {
+	s, server := newTestAPIServer(t)
+	if _, err := s.m.addPortfolioBuy("SH600000", "浦发银行", 10, 100); err != nil {
+		t.Fatal(err)
+	}
+	s.m.savePortfolio()
+
+	var report portfolioReport
+	if status := getAPIJSON(t, server.URL+"/portfolio", &report); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if len(report.Positions) != 1 || report.Positions[0].Price <= 0 || report.Account != defaultAccountName {
+		t.Fatalf("持仓 = %+v", report)
+	}
+	if !almostEqual(report.Total.MarketValue, report.Positions[0].MarketValue) {
+		t.Errorf("总市值 = %.2f, expected %.2f", report.Total.MarketValue, report.Positions[0].MarketValue)
+	}
+
+	var apiErr map[string]string
+	if status := getAPIJSON(t, server.URL+"/portfolio?account=missing", &apiErr); status != http.StatusNotFound || apiErr["error"] == "" {
+		t.Errorf("账户不存在: status = %d, body = %v", status, apiErr)
+	}
+}
+
+func TestServeIntraday(t *testing.T) {
+	_, server := newTestAPIServer(t)
+
+	dir := filepath.Join("data", "intraday", "CN", "SH600000")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	saved := &IntradayData{
+		Code: "SH600000", Name: "浦发银行", Date: "20250314", Market: MarketChina, PrevClose: 10,
+		Datapoints: []IntradayDataPoint{{Time: "09:31", Price: 10.1}, {Time: "09:32", Price: 10.2}},
+	}
+	if err := saveIntradayData(filepath.Join(dir, "20250314.json"), saved); err != nil {
+		t.Fatal(err)
+	}
+
+	var data IntradayData
+	if status := getAPIJSON(t, server.URL+"/intraday/sh600000/2025-03-14", &data); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if len(data.Datapoints) != 2 || data.Datapoints[1].Price != 10.2 {
+		t.Errorf("分时数据 = %+v", data)
+	}
+
+	var apiErr map[string]string
+	if status := getAPIJSON(t, server.URL+"/intraday/SH600000/20250313", &apiErr); status != http.StatusNotFound {
+		t.Errorf("没有数据时 status = %d, expected 404", status)
+	}
+	if status := getAPIJSON(t, server.URL+"/intraday/SH600000/yesterday", &apiErr); status != http.StatusBadRequest {
+		t.Errorf("日期格式错误时 status = %d, expected 400", status)
+	}
+}
+
+// TestServeEvents 测试刷新后通过 SSE 收到订阅代码的行情更新
+func TestServeEvents(t *testing.T) {
+	s, server := newTestAPIServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?symbols=aapl", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Content-Type = %s", contentType)
+	}
+
+	// 响应头返回时已完成订阅
+	go s.fetch(ctx, []string{"SH600000", "AAPL"})
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		payload, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event quoteEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			t.Fatalf("事件无法解析: %v\n%s", err, payload)
+		}
+		if event.Symbol != "AAPL" || event.Quote == nil || event.Quote.Price <= 0 {
+			t.Fatalf("事件 = %+v, expected AAPL quote", event)
+		}
+		return
+	}
+	t.Fatalf("没有收到行情事件: %v", scanner.Err())
+}
+
+// TestServeConcurrentRefresh 测试刷新循环运行时并发请求自选列表和分时数据（配合 go test -race）
+func TestServeConcurrentRefresh(t *testing.T) {
+	s, server := newTestAPIServer(t)
+	s.m.intradayManager = newIntradayManager(s.m)
+	t.Cleanup(func() { close(s.m.intradayManager.cancelChan) })
+	s.intraday = newIntradaySupervisor(s.m.intradayManager)
+
+	s.m.watchlist = Watchlist{Stocks: []WatchlistStock{{Code: "AAPL", Name: "Apple", Tags: []string{"科技"}}}}
+	s.m.saveWatchlist()
+	if _, err := s.m.addPortfolioBuy("SH600000", "浦发银行", 10, 100); err != nil {
+		t.Fatal(err)
+	}
+	s.m.savePortfolio()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.run(ctx)
+	}()
+
+	today := time.Now().Format("20060102")
+	for range 5 {
+		var items []watchlistItemReport
+		if status := getAPIJSON(t, server.URL+"/watchlist?tag=科技", &items); status != http.StatusOK || len(items) != 1 {
+			t.Errorf("watchlist: status = %d, items = %+v", status, items)
+		}
+		var body map[string]any
+		getAPIJSON(t, server.URL+"/intraday/SH600000/"+today, &body)
+	}
+	cancel()
+	<-done
+}
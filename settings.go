@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ============================================================================
+// 刷新设置界面
+// ============================================================================
+
+// 刷新间隔范围（秒）
+const (
+	minRefreshSeconds = 1
+	maxRefreshSeconds = 60
+)
+
+// 设置项
+const (
+	settingRefreshInterval = iota // 刷新间隔
+	settingAutoUpdate             // 自动更新
+	settingCount
+)
+
+// enterSettings 打开刷新设置界面
+func (m *Model) enterSettings() {
+	logInfo("log.action.enterSettings")
+	m.state = SettingsEditing
+	m.settingsCursor = 0
+	m.message = ""
+}
+
+// handleSettings 处理刷新设置界面的按键，修改立即生效并保存到配置文件
+func (m *Model) handleSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.state = MainMenu
+		m.message = ""
+	case "up", "k", "w":
+		if m.settingsCursor > 0 {
+			m.settingsCursor--
+		}
+	case "down", "j", "s":
+		if m.settingsCursor < settingCount-1 {
+			m.settingsCursor++
+		}
+	case "left", "h", "-":
+		m.changeSetting(-1)
+	case "right", "l", "+", "=":
+		m.changeSetting(1)
+	case "enter", " ":
+		if m.settingsCursor == settingAutoUpdate {
+			m.changeSetting(1)
+		}
+	}
+	return m, nil
+}
+
+// changeSetting 调整当前设置项：刷新间隔按 1 秒增减，自动更新切换开关
+func (m *Model) changeSetting(delta int) {
+	update := m.config.Update
+	switch m.settingsCursor {
+	case settingRefreshInterval:
+		update.RefreshInterval = min(max(update.RefreshInterval+delta, minRefreshSeconds), maxRefreshSeconds)
+	case settingAutoUpdate:
+		update.AutoUpdate = !update.AutoUpdate
+	}
+	if update == m.config.Update {
+		return
+	}
+
+	m.config.Update = update
+	logInfo("log.settings.changed", update.RefreshInterval, update.AutoUpdate)
+	if err := saveConfig(m.config); err != nil {
+		m.message = fmt.Sprintf(m.getText("settings.saveFail"), err)
+		return
+	}
+	m.message = m.getText("settings.saved")
+}
+
+// updateSettingsSummary 主菜单中显示的刷新设置摘要
+func (m *Model) updateSettingsSummary() string {
+	return fmt.Sprintf(m.getText("settings.summary"), m.config.Update.RefreshInterval, m.onOffText(m.config.Update.AutoUpdate))
+}
+
+// onOffText 开关状态文字
+func (m *Model) onOffText(on bool) string {
+	if on {
+		return m.getText("settings.on")
+	}
+	return m.getText("settings.off")
+}
+
+func (m *Model) viewSettings() string {
+	s := m.getText("settings.title") + "\n\n"
+
+	items := []string{
+		fmt.Sprintf("%s: %s", m.getText("settings.refreshInterval"), fmt.Sprintf(m.getText("settings.seconds"), m.config.Update.RefreshInterval)),
+		fmt.Sprintf("%s: %s", m.getText("settings.autoUpdate"), m.onOffText(m.config.Update.AutoUpdate)),
+	}
+	for i, item := range items {
+		prefix := "  "
+		if i == m.settingsCursor {
+			prefix = "► "
+		}
+		s += prefix + item + "\n"
+	}
+
+	s += "\n" + m.getText("settings.note") + "\n"
+	s += "\n" + m.getText("settings.help") + "\n"
+
+	if m.message != "" {
+		s += "\n" + m.message + "\n"
+	}
+	return s
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// drainCmd 执行命令并展开批量消息，返回全部结果消息
+func drainCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, drainCmd(c)...)
+	}
+	return msgs
+}
+
+// TestRefreshSettings 测试刷新设置界面的修改立即生效并保存，以及关闭自动更新后的手动刷新
+func TestRefreshSettings(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+	if err := os.MkdirAll("cmd/conf", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Model{
+		config:          getDefaultConfig(),
+		language:        English,
+		state:           MainMenu,
+		stockPriceCache: make(map[string]*StockPriceCacheEntry),
+		watchlist:       Watchlist{Stocks: []WatchlistStock{{Code: "SH600000", Name: "浦发银行"}}},
+	}
+	m.menuItems = m.getMenuItems()
+	m.currentMenuItem = 5
+	m.executeMenuItem()
+	if m.state != SettingsEditing {
+		t.Fatalf("state = %v", m.state)
+	}
+
+	key := func(msg tea.KeyMsg) {
+		m.handleSettings(msg)
+	}
+	for range 3 {
+		key(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	if m.quoteRefreshInterval() != 8*time.Second || loadConfig().Update.RefreshInterval != 8 {
+		t.Errorf("interval = %v, saved = %d", m.quoteRefreshInterval(), loadConfig().Update.RefreshInterval)
+	}
+	for range 20 {
+		key(tea.KeyMsg{Type: tea.KeyLeft})
+	}
+	if m.config.Update.RefreshInterval != minRefreshSeconds {
+		t.Errorf("刷新间隔不应小于 %d: %d", minRefreshSeconds, m.config.Update.RefreshInterval)
+	}
+
+	// 关闭自动更新后定时刷新不再获取行情
+	key(tea.KeyMsg{Type: tea.KeyDown})
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.config.Update.AutoUpdate || loadConfig().Update.AutoUpdate {
+		t.Fatal("自动更新应已关闭并保存")
+	}
+	if view := m.viewSettings(); !strings.Contains(view, "► settings.autoUpdate") {
+		t.Errorf("设置界面:\n%s", view)
+	}
+	if cmd := m.startStockPriceUpdates(); cmd != nil {
+		t.Error("自动更新关闭时不应获取行情")
+	}
+	key(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.state != MainMenu {
+		t.Errorf("state = %v", m.state)
+	}
+
+	// R 键手动刷新
+	m.state = WatchlistViewing
+	_, cmd := m.handleWatchlistViewing(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil || !strings.HasPrefix(m.message, "refresh.started") {
+		t.Fatalf("message = %q", m.message)
+	}
+	for _, msg := range drainCmd(cmd) {
+		m.Update(msg)
+	}
+	if data := m.getFreshStockPrice("SH600000"); data == nil || data.Price <= 0 {
+		t.Errorf("手动刷新后 data = %+v", data)
+	}
+}
+
+// TestLoadUpdateConfig 测试加载更新设置：显式关闭的自动更新不被默认值覆盖
+func TestLoadUpdateConfig(t *testing.T) {
+	useTempDataDir(t)
+	if err := os.MkdirAll("cmd/conf", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defaults := getDefaultConfig().Update
+
+	tests := []struct {
+		yaml string
+		want UpdateConfig
+	}{
+		{"update:\n  auto_update: false\n", UpdateConfig{RefreshInterval: defaults.RefreshInterval, AutoUpdate: false}},
+		{"update:\n  refresh_interval: 10\n", UpdateConfig{RefreshInterval: 10, AutoUpdate: true}},
+		{"update:\n  refresh_interval: 120\n  auto_update: true\n", UpdateConfig{RefreshInterval: defaults.RefreshInterval, AutoUpdate: true}},
+		{"display:\n  max_lines: 10\n", defaults},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(configFile, []byte(tt.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := loadConfig().Update; got != tt.want {
+			t.Errorf("%q: Update = %+v, want %+v", tt.yaml, got, tt.want)
+		}
+	}
+}
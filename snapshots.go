@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 每日持仓快照
+// ============================================================================
+
+// PositionSnapshot 单只持股的收盘快照（金额为原币）
+type PositionSnapshot struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Market      MarketType `json:"market"`
+	Currency    string     `json:"currency"`
+	Rate        float64    `json:"rate"` // 原币兑基准货币汇率
+	Quantity    int        `json:"quantity"`
+	Price       float64    `json:"price"`
+	PrevClose   float64    `json:"prev_close"`
+	MarketValue float64    `json:"market_value"`
+	CostBasis   float64    `json:"cost_basis"`
+	DailyProfit float64    `json:"daily_profit"` // 当日盈亏（现价 - 昨收）* 数量
+}
+
+// PortfolioSnapshot 某个交易日的持仓快照（汇总金额为基准货币）
+// 各市场收盘后分别写入该市场的持股，尚未收盘的市场沿用上一个快照的持股
+type PortfolioSnapshot struct {
+	Date        string             `json:"date"` // YYYY-MM-DD（各市场当地交易日）
+	Currency    string             `json:"currency"`
+	TotalValue  float64            `json:"total_value"`
+	CostBasis   float64            `json:"cost_basis"`
+	DailyProfit float64            `json:"daily_profit"`
+	Markets     []MarketType       `json:"markets"` // 当日已收盘写入的市场
+	Positions   []PositionSnapshot `json:"positions"`
+	UpdateTime  time.Time          `json:"update_time"`
+}
+
+// snapshotFile 获取账户的快照文件路径
+func snapshotFile(account string) string {
+	return filepath.Join(snapshotsDir, account+".json")
+}
+
+// loadSnapshots 加载账户的全部快照（按日期升序）
+func loadSnapshots(account string) []PortfolioSnapshot {
+	data, err := os.ReadFile(snapshotFile(account))
+	if err != nil {
+		return nil
+	}
+	var snapshots []PortfolioSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		logWarn("log.snapshot.loadFail", account, err)
+		return nil
+	}
+	slices.SortFunc(snapshots, func(a, b PortfolioSnapshot) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+	return snapshots
+}
+
+// saveSnapshots 保存账户的全部快照
+func saveSnapshots(account string, snapshots []PortfolioSnapshot) error {
+	if err := os.MkdirAll(snapshotsDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(snapshotFile(account), data, 0644)
+}
+
+// marketConfigFor 获取市场的交易时段配置
+func (m *Model) marketConfigFor(market MarketType) MarketConfig {
+	return m.config.Markets.forMarket(market)
+}
+
+// forMarket 获取指定市场的配置（未知市场使用 A股 配置）
+func (c MarketsConfig) forMarket(market MarketType) MarketConfig {
+	switch market {
+	case MarketUS:
+		return c.US
+	case MarketHongKong:
+		return c.HongKong
+	default:
+		return c.China
+	}
+}
+
+// marketClose 获取市场当地今天的日期（YYYY-MM-DD）和收盘时间（最后一个交易时段结束，半日市为提前收盘时间）
+// 非交易日（周末和休市日）返回 false
+func marketClose(now time.Time, market MarketType, marketConfig MarketConfig) (string, time.Time, bool) {
+	if len(marketConfig.TradingSessions) == 0 {
+		return "", time.Time{}, false
+	}
+	location, err := time.LoadLocation(marketConfig.Timezone)
+	if err != nil {
+		location = time.Local
+	}
+	local := now.In(location)
+
+	weekday := int(local.Weekday())
+	if weekday == 0 { // Sunday = 0 in Go, convert to 7
+		weekday = 7
+	}
+	if !slices.Contains(marketConfig.Weekdays, weekday) || !isTradingDay(market, local) {
+		return "", time.Time{}, false
+	}
+
+	sessions := tradingSessionsForDate(marketConfig.TradingSessions, market, local.Format("20060102"))
+	if len(sessions) == 0 {
+		return "", time.Time{}, false
+	}
+	lastSession := sessions[len(sessions)-1]
+	closeTime, err := parseTimeInMarket(local.Format("20060102"), lastSession.EndTime, marketConfig)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return local.Format("2006-01-02"), closeTime, true
+}
+
+// applyMarketSnapshot 将某个市场的收盘持股写入指定日期的快照并重算汇总
+// 当天的新快照先沿用上一个快照的持股（其他市场尚未收盘时按上次收盘价计算）
+func applyMarketSnapshot(snapshots []PortfolioSnapshot, date, currency string, market MarketType, positions []PositionSnapshot, now time.Time) []PortfolioSnapshot {
+	index := slices.IndexFunc(snapshots, func(s PortfolioSnapshot) bool { return s.Date == date })
+	if index < 0 {
+		snapshot := PortfolioSnapshot{Date: date, Currency: currency}
+		// 沿用之前最近一个快照的持股（当日盈亏清零）
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if snapshots[i].Date < date {
+				for _, position := range snapshots[i].Positions {
+					position.DailyProfit = 0
+					position.PrevClose = position.Price
+					snapshot.Positions = append(snapshot.Positions, position)
+				}
+				break
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+		slices.SortFunc(snapshots, func(a, b PortfolioSnapshot) int { return strings.Compare(a.Date, b.Date) })
+		index = slices.IndexFunc(snapshots, func(s PortfolioSnapshot) bool { return s.Date == date })
+	}
+
+	snapshot := &snapshots[index]
+	snapshot.Positions = slices.DeleteFunc(snapshot.Positions, func(p PositionSnapshot) bool { return p.Market == market })
+	snapshot.Positions = append(snapshot.Positions, positions...)
+	if !slices.Contains(snapshot.Markets, market) {
+		snapshot.Markets = append(snapshot.Markets, market)
+	}
+	snapshot.Currency = currency
+	snapshot.UpdateTime = now
+
+	snapshot.TotalValue, snapshot.CostBasis, snapshot.DailyProfit = 0, 0, 0
+	for _, position := range snapshot.Positions {
+		snapshot.TotalValue += position.MarketValue * position.Rate
+		snapshot.CostBasis += position.CostBasis * position.Rate
+		snapshot.DailyProfit += position.DailyProfit * position.Rate
+	}
+	return snapshots
+}
+
+// recordMarketCloseSnapshots 各市场收盘后写入当日快照（每个账户、市场、日期只写一次）
+// 需要收盘后更新过的价格和换算汇率，数据不全时等待下一次刷新再试
+func (m *Model) recordMarketCloseSnapshots() {
+	if len(m.portfolio.Stocks) == 0 {
+		return
+	}
+	if m.snapshotRecorded == nil {
+		m.snapshotRecorded = make(map[string]bool)
+	}
+
+	account := m.currentAccount()
+	base := m.baseCurrency()
+	now := time.Now()
+
+	for _, market := range []MarketType{MarketChina, MarketHongKong, MarketUS} {
+		date, closeTime, tradingDay := marketClose(now, market, m.marketConfigFor(market))
+		key := account + "|" + string(market) + "|" + date
+		if !tradingDay || now.Before(closeTime) || m.snapshotRecorded[key] {
+			continue
+		}
+
+		positions, ready := m.collectMarketPositions(market, base, closeTime)
+		if !ready {
+			continue
+		}
+		m.snapshotRecorded[key] = true
+
+		// 该市场没有持股且历史快照中也没有时无需写入
+		snapshots := loadSnapshots(account)
+		if len(positions) == 0 && !lastSnapshotHoldsMarket(snapshots, market) {
+			continue
+		}
+
+		snapshots = applyMarketSnapshot(snapshots, date, base, market, positions, now)
+		if err := saveSnapshots(account, snapshots); err != nil {
+			logWarn("log.snapshot.saveFail", account, err)
+			continue
+		}
+		logInfo("log.snapshot.recorded", account, market, date, len(positions))
+	}
+}
+
+// lastSnapshotHoldsMarket 最近一个快照中是否有该市场的持股
+func lastSnapshotHoldsMarket(snapshots []PortfolioSnapshot, market MarketType) bool {
+	if len(snapshots) == 0 {
+		return false
+	}
+	return slices.ContainsFunc(snapshots[len(snapshots)-1].Positions, func(p PositionSnapshot) bool { return p.Market == market })
+}
+
+// collectMarketPositions 收集某个市场的持股快照
+// 价格需在收盘之后更新过、且有换算汇率才视为就绪
+func (m *Model) collectMarketPositions(market MarketType, base string, closeTime time.Time) ([]PositionSnapshot, bool) {
+	var positions []PositionSnapshot
+	for i := range m.portfolio.Stocks {
+		stock := &m.portfolio.Stocks[i]
+		if getMarketType(stock.Code) != market {
+			continue
+		}
+		summary := stock.PositionSummary()
+		if summary.Quantity <= 0 {
+			continue
+		}
+
+		m.stockPriceMutex.RLock()
+		entry, exists := m.stockPriceCache[stock.Code]
+		m.stockPriceMutex.RUnlock()
+		if !exists || entry.Data == nil || entry.Data.Price <= 0 || entry.UpdateTime.Before(closeTime) {
+			return nil, false
+		}
+
+		currency := currencyForMarket(market)
+		rate, ok := m.fxRates.Rate(currency, base)
+		if !ok {
+			return nil, false
+		}
+
+		data := entry.Data
+		positions = append(positions, PositionSnapshot{
+			Code:        stock.Code,
+			Name:        stock.Name,
+			Market:      market,
+			Currency:    currency,
+			Rate:        rate.Rate,
+			Quantity:    summary.Quantity,
+			Price:       data.Price,
+			PrevClose:   data.PrevClose,
+			MarketValue: data.Price * float64(summary.Quantity),
+			CostBasis:   summary.CostBasis,
+			DailyProfit: (data.Price - data.PrevClose) * float64(summary.Quantity),
+		})
+	}
+	return positions, true
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarketClose(t *testing.T) {
+	markets := defaultMarketsConfig()
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+
+	// 2025-03-14 周五，A股收盘 15:00（北京时间）
+	now := time.Date(2025, 3, 14, 16, 0, 0, 0, shanghai)
+	date, closeTime, tradingDay := marketClose(now, MarketChina, markets.China)
+	if !tradingDay || date != "2025-03-14" {
+		t.Fatalf("marketClose = %s, %v, expected 2025-03-14 trading day", date, tradingDay)
+	}
+	if expected := time.Date(2025, 3, 14, 15, 0, 0, 0, shanghai); !closeTime.Equal(expected) {
+		t.Errorf("收盘时间 = %v, expected %v", closeTime, expected)
+	}
+
+	// 同一时刻美东为周五凌晨，交易日期按市场当地时间计算
+	date, _, tradingDay = marketClose(now, MarketUS, markets.US)
+	if !tradingDay || date != "2025-03-14" {
+		t.Errorf("美股日期 = %s, %v, expected 2025-03-14", date, tradingDay)
+	}
+
+	// 周六非交易日
+	if _, _, tradingDay := marketClose(now.AddDate(0, 0, 1), MarketChina, markets.China); tradingDay {
+		t.Error("周六不应为交易日")
+	}
+}
+
+// TestApplyMarketSnapshot 测试按市场写入快照、沿用前一日持股和基准货币汇总
+func TestApplyMarketSnapshot(t *testing.T) {
+	now := time.Now()
+	china := []PositionSnapshot{{Code: "SH600000", Market: MarketChina, Rate: 1, Quantity: 100,
+		Price: 11, PrevClose: 10, MarketValue: 1100, CostBasis: 1000, DailyProfit: 100}}
+	us := []PositionSnapshot{{Code: "AAPL", Market: MarketUS, Rate: 7, Quantity: 10,
+		Price: 200, PrevClose: 190, MarketValue: 2000, CostBasis: 1500, DailyProfit: 100}}
+
+	snapshots := applyMarketSnapshot(nil, "2025-03-13", CurrencyCNY, MarketChina, china, now)
+	snapshots = applyMarketSnapshot(snapshots, "2025-03-13", CurrencyCNY, MarketUS, us, now)
+	if len(snapshots) != 1 || len(snapshots[0].Markets) != 2 {
+		t.Fatalf("快照 = %+v, expected 1 snapshot with 2 markets", snapshots)
+	}
+	if got := snapshots[0]; !almostEqual(got.TotalValue, 1100+14000) || !almostEqual(got.CostBasis, 1000+10500) ||
+		!almostEqual(got.DailyProfit, 100+700) {
+		t.Errorf("汇总 = %.2f / %.2f / %.2f, expected 15100 / 11500 / 800", got.TotalValue, got.CostBasis, got.DailyProfit)
+	}
+
+	// 次日 A 股收盘，美股尚未收盘：沿用前一日美股持股，当日盈亏清零
+	china[0].Price, china[0].PrevClose, china[0].MarketValue, china[0].DailyProfit = 12, 11, 1200, 100
+	snapshots = applyMarketSnapshot(snapshots, "2025-03-14", CurrencyCNY, MarketChina, china, now)
+	if len(snapshots) != 2 || snapshots[1].Date != "2025-03-14" {
+		t.Fatalf("快照 = %+v, expected 2 snapshots", snapshots)
+	}
+	next := snapshots[1]
+	if len(next.Positions) != 2 || len(next.Markets) != 1 {
+		t.Errorf("次日快照持股 = %d, 市场 = %v", len(next.Positions), next.Markets)
+	}
+	if !almostEqual(next.TotalValue, 1200+14000) || !almostEqual(next.DailyProfit, 100) {
+		t.Errorf("次日汇总 = %.2f / %.2f, expected 15200 / 100", next.TotalValue, next.DailyProfit)
+	}
+
+	// 前一日快照不受影响
+	if !almostEqual(snapshots[0].TotalValue, 15100) {
+		t.Errorf("前一日市值被修改: %.2f", snapshots[0].TotalValue)
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// ============================================================================
+// 本地代码表：A股、港股、美股的代码、名称、拼音和交易所，搜索时优先查询
+// ============================================================================
+
+// symbolDirectoryPath 本地代码表文件
+var symbolDirectoryPath = filepath.Join("data", "symbols.json")
+
+const (
+	symbolSearchLimit    = 50  // 本地搜索最多返回的候选数量
+	symbolListPageSize   = 500 // 东方财富代码列表每页数量
+	symbolListMaxPages   = 100 // 单个市场最多请求的页数（防止接口异常时无限翻页）
+	symbolRefreshTimeout = 15 * time.Second
+)
+
+// SymbolEntry 本地代码表中的一只股票
+type SymbolEntry struct {
+	Code        string     `json:"code"`                   // 标准代码 (SH600000 / HK00700 / AAPL)
+	Name        string     `json:"name"`                   // 名称（A股、港股为中文）
+	EnglishName string     `json:"english_name,omitempty"` // 英文名称（美股）
+	Pinyin      string     `json:"pinyin,omitempty"`       // 中文名称拼音首字母
+	Exchange    string     `json:"exchange"`               // 交易所
+	Market      MarketType `json:"market"`
+}
+
+// SymbolDirectory 本地代码表
+type SymbolDirectory struct {
+	UpdatedAt time.Time     `json:"updated_at"`
+	Symbols   []SymbolEntry `json:"symbols"`
+}
+
+// candidate 转换为搜索候选
+func (e SymbolEntry) candidate() SearchCandidate {
+	return SearchCandidate{
+		Symbol:      e.Code,
+		Name:        e.Name,
+		EnglishName: e.EnglishName,
+		Market:      e.Market,
+		Exchange:    e.Exchange,
+		Pinyin:      e.Pinyin,
+	}
+}
+
+// 已加载的代码表（文件变化时重新加载）
+var (
+	symbolDirectoryMu   sync.Mutex
+	symbolDirectoryData *SymbolDirectory
+	symbolDirectoryStat os.FileInfo
+)
+
+// getSymbolDirectory 获取本地代码表，文件不存在或无法解析时返回 nil
+func getSymbolDirectory() *SymbolDirectory {
+	symbolDirectoryMu.Lock()
+	defer symbolDirectoryMu.Unlock()
+
+	info, err := os.Stat(symbolDirectoryPath)
+	if err != nil {
+		return nil
+	}
+	if symbolDirectoryStat != nil && os.SameFile(info, symbolDirectoryStat) && info.ModTime().Equal(symbolDirectoryStat.ModTime()) {
+		return symbolDirectoryData
+	}
+
+	symbolDirectoryData, symbolDirectoryStat = nil, info
+	content, err := os.ReadFile(symbolDirectoryPath)
+	if err != nil {
+		logWarn("log.symbols.loadFail", err)
+		return nil
+	}
+	var directory SymbolDirectory
+	if err := json.Unmarshal(content, &directory); err != nil {
+		logWarn("log.symbols.loadFail", err)
+		return nil
+	}
+	symbolDirectoryData = &directory
+	logDebug("log.symbols.loaded", len(directory.Symbols))
+	return symbolDirectoryData
+}
+
+// saveSymbolDirectory 保存本地代码表（先写临时文件再重命名）
+func saveSymbolDirectory(directory *SymbolDirectory) error {
+	lock := getFileLock(symbolDirectoryPath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(symbolDirectoryPath), 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(directory, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempPath := symbolDirectoryPath + ".tmp"
+	if err := os.WriteFile(tempPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, symbolDirectoryPath)
+}
+
+// searchSymbolDirectory 在本地代码表中搜索，返回按匹配程度排序的候选（不访问网络）
+// 本地代码表不存在或没有匹配时返回 nil，由调用方继续使用在线搜索
+func searchSymbolDirectory(keyword string) []SearchCandidate {
+	directory := getSymbolDirectory()
+	if directory == nil {
+		return nil
+	}
+
+	type scoredCandidate struct {
+		candidate SearchCandidate
+		score     int
+	}
+	matcher := newSearchMatcher(keyword)
+	var matches []scoredCandidate
+	for _, entry := range directory.Symbols {
+		candidate := entry.candidate()
+		if score := matcher.score(candidate); score >= searchScoreFuzzy {
+			matches = append(matches, scoredCandidate{candidate, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b scoredCandidate) int {
+		return b.score - a.score
+	})
+
+	candidates := make([]SearchCandidate, 0, min(len(matches), symbolSearchLimit))
+	for _, match := range matches[:min(len(matches), symbolSearchLimit)] {
+		candidates = append(candidates, match.candidate)
+	}
+	if len(candidates) > 0 {
+		logDebug("log.symbols.searchHit", keyword, len(matches))
+		return candidates
+	}
+	return nil
+}
+
+// lookupSymbolDirectory 在本地代码表中查找一只股票并获取报价（只需要一个结果的搜索路径）
+// 只接受名称或代码包含关键词的候选，模糊匹配的结果交给在线搜索确认
+func lookupSymbolDirectory(keyword string) *StockData {
+	matcher := newSearchMatcher(keyword)
+	candidates := slices.DeleteFunc(searchSymbolDirectory(keyword), func(candidate SearchCandidate) bool {
+		return matcher.score(candidate) < searchScoreContains
+	})
+	return quoteBestCandidate(keyword, candidates)
+}
+
+// ============================================================================
+// 从数据源更新代码表
+// ============================================================================
+
+// symbolListGroups 东方财富代码列表的市场筛选条件
+var symbolListGroups = []struct {
+	market MarketType
+	fs     string
+}{
+	{MarketChina, "m:1+t:2,m:1+t:23,m:0+t:6,m:0+t:80"}, // 沪市主板、科创板、深市主板、创业板
+	{MarketHongKong, "m:116+t:3,m:116+t:4"},            // 港股主板、创业板
+	{MarketUS, "m:105,m:106,m:107"},                    // NASDAQ、NYSE、AMEX
+}
+
+// eastMoneyMarketIDs 东方财富市场编号 → 代码前缀和交易所
+var eastMoneyMarketIDs = map[int]struct{ prefix, exchange string }{
+	0:   {"SZ", "SZSE"},
+	1:   {"SH", "SSE"},
+	116: {"HK", "HKEX"},
+	105: {"", "NASDAQ"},
+	106: {"", "NYSE"},
+	107: {"", "AMEX"},
+}
+
+// refreshSymbolDirectory 从东方财富获取全部代码和名称，从 Nasdaq 补充美股英文名称，保存为本地代码表
+// 任一市场获取失败时保留原有代码表
+func refreshSymbolDirectory() (*SymbolDirectory, error) {
+	directory := &SymbolDirectory{UpdatedAt: time.Now()}
+	for _, group := range symbolListGroups {
+		entries, err := fetchEastMoneySymbolList(group.fs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", group.market, err)
+		}
+		logDebug("log.symbols.marketFetched", group.market, len(entries))
+		directory.Symbols = append(directory.Symbols, entries...)
+	}
+
+	// 英文名称只是补充信息，获取失败不影响代码表更新
+	englishNames, err := fetchNasdaqSymbolNames()
+	if err != nil {
+		logWarn("log.symbols.englishNameFail", err)
+	}
+	for i := range directory.Symbols {
+		entry := &directory.Symbols[i]
+		if entry.Market == MarketUS {
+			entry.EnglishName = englishNames[entry.Code]
+		}
+		if containsChineseChars(entry.Name) {
+			entry.Pinyin = pinyinInitials(entry.Name)
+		}
+	}
+
+	if err := saveSymbolDirectory(directory); err != nil {
+		return nil, err
+	}
+	logInfo("log.symbols.refreshed", len(directory.Symbols))
+	return directory, nil
+}
+
+// fetchEastMoneySymbolList 分页获取东方财富代码列表
+// 响应: {"data":{"total":5000,"diff":[{"f12":"600000","f13":1,"f14":"浦发银行"},...]}}
+func fetchEastMoneySymbolList(fs string) ([]SymbolEntry, error) {
+	client := &http.Client{Timeout: symbolRefreshTimeout}
+	var entries []SymbolEntry
+	fetched := 0 // 已获取的条数（接口可能限制每页数量，按实际返回条数判断是否还有下一页）
+	for page := 1; page <= symbolListMaxPages; page++ {
+		requestURL := fmt.Sprintf("%s/api/qt/clist/get?pn=%d&pz=%d&po=0&np=1&fltt=2&fid=f12&fs=%s&fields=f12,f13,f14",
+			apiEndpoints.EastMoney, page, symbolListPageSize, url.QueryEscape(fs))
+		req, err := newKLineRequest(requestURL, "https://quote.eastmoney.com")
+		if err != nil {
+			return nil, err
+		}
+		resp, err := fetchWithRetry(client, req, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		var listResp struct {
+			Data *struct {
+				Total int `json:"total"`
+				Diff  []struct {
+					Code     string `json:"f12"`
+					MarketID int    `json:"f13"`
+					Name     string `json:"f14"`
+				} `json:"diff"`
+			} `json:"data"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&listResp)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if listResp.Data == nil || len(listResp.Data.Diff) == 0 {
+			break
+		}
+
+		for _, item := range listResp.Data.Diff {
+			market, ok := eastMoneyMarketIDs[item.MarketID]
+			name := strings.TrimSpace(item.Name)
+			if !ok || item.Code == "" || name == "" || name == "-" {
+				continue
+			}
+			code := strings.ToUpper(item.Code)
+			if market.prefix == "HK" {
+				code = padHKStockCode(code)
+			} else if market.prefix == "" {
+				code = strings.ReplaceAll(code, "_", ".") // 美股 BRK_B → BRK.B
+			}
+			code = market.prefix + code
+			entries = append(entries, SymbolEntry{
+				Code:     code,
+				Name:     name,
+				Exchange: market.exchange,
+				Market:   getMarketType(code),
+			})
+		}
+		fetched += len(listResp.Data.Diff)
+		if fetched >= listResp.Data.Total {
+			break
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("empty symbol list")
+	}
+	return entries, nil
+}
+
+// fetchNasdaqSymbolNames 获取美股代码 → 英文名称
+// 响应: {"data":{"rows":[{"symbol":"AAPL","name":"Apple Inc. Common Stock"},...]}}
+func fetchNasdaqSymbolNames() (map[string]string, error) {
+	req, err := newKLineRequest(apiEndpoints.Nasdaq+"/api/screener/stocks?tableonly=true&download=true", "https://www.nasdaq.com")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := fetchWithRetry(&http.Client{Timeout: symbolRefreshTimeout}, req, 2)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var screener struct {
+		Data *struct {
+			Rows []struct {
+				Symbol string `json:"symbol"`
+				Name   string `json:"name"`
+			} `json:"rows"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&screener); err != nil {
+		return nil, err
+	}
+	if screener.Data == nil {
+		return nil, fmt.Errorf("no screener data")
+	}
+
+	names := make(map[string]string, len(screener.Data.Rows))
+	for _, row := range screener.Data.Rows {
+		symbol := strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(row.Symbol)), "/", ".") // BRK/B → BRK.B
+		name := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(row.Name), "Common Stock"))
+		if symbol != "" && name != "" {
+			names[symbol] = name
+		}
+	}
+	return names, nil
+}
+
+// symbolDirectoryRefreshedMsg 代码表更新完成消息
+type symbolDirectoryRefreshedMsg struct {
+	count int
+	err   error
+}
+
+// refreshSymbolDirectoryCmd 在后台更新本地代码表
+func refreshSymbolDirectoryCmd() tea.Cmd {
+	return func() tea.Msg {
+		directory, err := refreshSymbolDirectory()
+		if err != nil {
+			logWarn("log.symbols.refreshFail", err)
+			return symbolDirectoryRefreshedMsg{err: err}
+		}
+		return symbolDirectoryRefreshedMsg{count: len(directory.Symbols)}
+	}
+}
+
+// handleSymbolDirectoryRefreshed 显示代码表更新结果
+func (m *Model) handleSymbolDirectoryRefreshed(msg symbolDirectoryRefreshedMsg) {
+	m.symbolsRefreshing = false
+	if msg.err != nil {
+		m.message = fmt.Sprintf(m.getText("symbols.refreshFail"), msg.err)
+		return
+	}
+	m.message = fmt.Sprintf(m.getText("symbols.refreshed"), msg.count)
+}
+
+// symbolDirectoryStatus 搜索页显示的本地代码表状态
+func (m *Model) symbolDirectoryStatus() string {
+	if m.symbolsRefreshing {
+		return m.getText("symbols.refreshing")
+	}
+	directory := getSymbolDirectory()
+	if directory == nil {
+		return m.getText("symbols.missing")
+	}
+	return fmt.Sprintf(m.getText("symbols.status"), len(directory.Symbols), directory.UpdatedAt.Format("2006-01-02 15:04"))
+}
+
+// ============================================================================
+// 拼音首字母
+// ============================================================================
+
+// pinyinBound 某个拼音首字母对应的第一个 GB2312 编码
+type pinyinBound struct {
+	code    int
+	initial byte
+}
+
+// gb2312InitialBounds GB2312 一级汉字按拼音排序，每个首字母的第一个汉字编码
+var gb2312InitialBounds = []pinyinBound{
+	{0xB0A1, 'a'}, {0xB0C5, 'b'}, {0xB2C1, 'c'}, {0xB4EE, 'd'}, {0xB6EA, 'e'},
+	{0xB7A2, 'f'}, {0xB8C1, 'g'}, {0xB9FE, 'h'}, {0xBBF7, 'j'}, {0xBFA6, 'k'},
+	{0xC0AC, 'l'}, {0xC2E8, 'm'}, {0xC4C3, 'n'}, {0xC5B6, 'o'}, {0xC5BE, 'p'},
+	{0xC6DA, 'q'}, {0xC8BB, 'r'}, {0xC8F6, 's'}, {0xCBFA, 't'}, {0xCDDA, 'w'},
+	{0xCEF4, 'x'}, {0xD1B9, 'y'}, {0xD4D1, 'z'},
+}
+
+// gb2312Level1End GB2312 一级汉字之后的第一个编码（二级汉字按部首排序，无法推算拼音）
+const gb2312Level1End = 0xD7FA
+
+// pinyinPhraseOverrides 股票名称中常见的多音字词
+var pinyinPhraseOverrides = map[string]string{
+	"银行": "yh",
+	"重庆": "cq",
+	"西藏": "xz",
+	"厦门": "xm",
+}
+
+// pinyinInitials 计算名称的拼音首字母（浦发银行 → pfyh），字母和数字原样保留（小写）
+func pinyinInitials(name string) string {
+	for phrase, initials := range pinyinPhraseOverrides {
+		name = strings.ReplaceAll(name, phrase, initials)
+	}
+
+	encoder := simplifiedchinese.GBK.NewEncoder()
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII:
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(unicode.ToLower(r))
+			}
+		case unicode.Is(unicode.Han, r):
+			encoded, err := encoder.String(string(r))
+			if err != nil || len(encoded) != 2 {
+				continue
+			}
+			code := int(encoded[0])<<8 | int(encoded[1])
+			if code < gb2312InitialBounds[0].code || code >= gb2312Level1End {
+				continue
+			}
+			i, found := slices.BinarySearchFunc(gb2312InitialBounds, code, func(bound pinyinBound, target int) int {
+				return bound.code - target
+			})
+			if !found {
+				i--
+			}
+			b.WriteByte(gb2312InitialBounds[i].initial)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPinyinInitials(t *testing.T) {
+	for name, want := range map[string]string{
+		"浦发银行":  "pfyh",
+		"贵州茅台":  "gzmt",
+		"腾讯控股":  "txkg",
+		"*ST康美": "stkm",
+		"重庆啤酒":  "cqpj",
+		"Apple": "apple",
+	} {
+		if got := pinyinInitials(name); got != want {
+			t.Errorf("pinyinInitials(%s) = %q, expected %q", name, got, want)
+		}
+	}
+}
+
+// TestSymbolDirectory 测试代码表下载、离线搜索和命令行子命令
+func TestSymbolDirectory(t *testing.T) {
+	useMockQuoteServer(t)
+	useTempDataDir(t)
+
+	if getSymbolDirectory() != nil || searchSymbolDirectory("pfyh") != nil {
+		t.Fatal("未下载代码表时不应有本地结果")
+	}
+
+	// 模拟接口每页只返回 3 条，需要翻页获取全部股票
+	directory, err := refreshSymbolDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]SymbolEntry)
+	for _, entry := range directory.Symbols {
+		entries[entry.Code] = entry
+	}
+	if len(entries) != 11 || len(directory.Symbols) != 11 {
+		t.Errorf("代码表 %d 只股票: %+v", len(directory.Symbols), directory.Symbols)
+	}
+	if entry := entries["SH600000"]; entry.Name != "浦发银行" || entry.Pinyin != "pfyh" || entry.Exchange != "SSE" {
+		t.Errorf("SH600000 = %+v", entry)
+	}
+	if entry := entries["HK00700"]; entry.Exchange != "HKEX" || entry.Market != MarketHongKong {
+		t.Errorf("HK00700 = %+v", entry)
+	}
+	if entry := entries["AAPL"]; entry.EnglishName != "Apple Inc." || entry.Exchange != "NASDAQ" {
+		t.Errorf("AAPL = %+v", entry)
+	}
+
+	// 在线搜索接口全部不可用时仍能从本地代码表搜索
+	apiEndpoints.TencentSearch = "http://127.0.0.1:1"
+	apiEndpoints.SinaSearch = "http://127.0.0.1:1"
+	apiEndpoints.TwelveData = "http://127.0.0.1:1"
+	if result := searchStockCandidates("pfyh"); len(result) == 0 || result[0].Symbol != "SH600000" {
+		t.Errorf("pfyh = %+v", result)
+	}
+	if result := searchStockCandidates("银行"); len(result) != 3 {
+		t.Errorf("银行 = %+v", result)
+	}
+	if result := searchStockCandidates("hk700"); len(result) == 0 || result[0].Symbol != "HK00700" {
+		t.Errorf("hk700 = %+v", result)
+	}
+	if data := searchChineseStock("贵州茅台"); data == nil || data.Symbol != "SH600519" {
+		t.Errorf("searchChineseStock(贵州茅台) = %+v", data)
+	}
+
+	code, stdout, stderr := runCLIForTest(t, "symbols", "search", "apple", "--json")
+	var candidates []SearchCandidate
+	if err := json.Unmarshal([]byte(stdout), &candidates); err != nil || code != exitOK {
+		t.Fatalf("symbols search: code %d, %v, %s", code, err, stderr)
+	}
+	if len(candidates) == 0 || candidates[0].Symbol != "AAPL" || candidates[0].EnglishName != "Apple Inc." {
+		t.Errorf("symbols search apple = %+v", candidates)
+	}
+	if code, _, _ := runCLIForTest(t, "symbols"); code != exitUsage {
+		t.Errorf("缺少操作时退出码 = %d", code)
+	}
+}
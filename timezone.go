@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -57,9 +58,10 @@ func parseTimeInMarket(date string, timeStr string, marketConfig MarketConfig) (
 
 // isMarketOpenForConfig 检查指定市场在指定时间是否开市
 // checkTime: 要检查的时间
+// market: 市场类型（用于查询休市日历）
 // marketConfig: 市场配置
 // 返回：true 表示开市，false 表示休市
-func isMarketOpenForConfig(checkTime time.Time, marketConfig MarketConfig) bool {
+func isMarketOpenForConfig(checkTime time.Time, market MarketType, marketConfig MarketConfig) bool {
 	// 转换检查时间到市场时区
 	location, err := time.LoadLocation(marketConfig.Timezone)
 	if err != nil {
@@ -83,14 +85,14 @@ func isMarketOpenForConfig(checkTime time.Time, marketConfig MarketConfig) bool
 		}
 	}
 
-	if !isWeekday {
+	if !isWeekday || !isTradingDay(market, marketTime) {
 		return false
 	}
 
-	// 检查是否在交易时段内
+	// 检查是否在交易时段内（半日市只到提前收盘时间）
 	currentMinutes := marketTime.Hour()*60 + marketTime.Minute()
 
-	for _, session := range marketConfig.TradingSessions {
+	for _, session := range tradingSessionsForDate(marketConfig.TradingSessions, market, marketTime.Format("20060102")) {
 		startParts := strings.Split(session.StartTime, ":")
 		endParts := strings.Split(session.EndTime, ":")
 
@@ -120,6 +122,36 @@ func isMarketOpenForConfig(checkTime time.Time, marketConfig MarketConfig) bool
 	return false
 }
 
+// lastSessionEnd 获取 now 之前最近一个已结束交易时段的结束时间
+// 午休前的上午时段也算一个时段，半日市按提前收盘时间；找不到时返回零值
+func lastSessionEnd(now time.Time, market MarketType, marketConfig MarketConfig) time.Time {
+	location, err := time.LoadLocation(marketConfig.Timezone)
+	if err != nil {
+		logDebug("log.timezone.loadFail", marketConfig.Timezone, err)
+		location = time.Local
+	}
+
+	day := now.In(location)
+	for range calendarSearchDays {
+		weekday := int(day.Weekday())
+		if weekday == 0 { // Sunday = 0 in Go, convert to 7
+			weekday = 7
+		}
+		if slices.Contains(marketConfig.Weekdays, weekday) && isTradingDay(market, day) {
+			date := day.Format("20060102")
+			sessions := tradingSessionsForDate(marketConfig.TradingSessions, market, date)
+			for i := len(sessions) - 1; i >= 0; i-- {
+				end, err := parseTimeInMarket(date, sessions[i].EndTime, marketConfig)
+				if err == nil && !end.After(now) {
+					return end
+				}
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}
+}
+
 // getCurrentDateForMarket 获取指定市场的当前日期（考虑时区）
 // market: 市场类型
 // m: Model 指针（用于访问配置）
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -18,6 +19,41 @@ type Stock struct {
 	MaxPrice      float64 `json:"max_price"`
 	MinPrice      float64 `json:"min_price"`
 	PrevClose     float64 `json:"prev_close"`
+
+	Transactions []Transaction `json:"transactions,omitempty"` // 交易记录（成本价和数量由此推导）
+
+	consolidated *PositionSummary // 合并视图中各账户持仓汇总（不保存）
+}
+
+// TransactionType 持仓交易类型
+type TransactionType string
+
+const (
+	TransactionBuy      TransactionType = "buy"      // 买入
+	TransactionSell     TransactionType = "sell"     // 卖出
+	TransactionDividend TransactionType = "dividend" // 现金分红
+	TransactionSplit    TransactionType = "split"    // 拆股/送股
+)
+
+// LotMethod 卖出时的成本结转方法
+type LotMethod string
+
+const (
+	LotMethodFIFO    LotMethod = "fifo"    // 先进先出
+	LotMethodLIFO    LotMethod = "lifo"    // 后进先出
+	LotMethodAverage LotMethod = "average" // 加权平均
+)
+
+// Transaction 持仓交易记录
+type Transaction struct {
+	Type      TransactionType `json:"type"`
+	Date      string          `json:"date,omitempty"`       // 交易日期 YYYY-MM-DD（迁移的期初持仓为空）
+	Price     float64         `json:"price,omitempty"`      // 成交价（买入/卖出）
+	Quantity  int             `json:"quantity,omitempty"`   // 成交数量（买入/卖出）
+	Fee       float64         `json:"fee,omitempty"`        // 手续费及税费
+	Amount    float64         `json:"amount,omitempty"`     // 分红现金总额
+	Ratio     float64         `json:"ratio,omitempty"`      // 拆股比例（2 表示 1 股拆为 2 股）
+	LotMethod LotMethod       `json:"lot_method,omitempty"` // 卖出时的成本结转方法（记录时确定，修改配置不影响已有卖出）
 }
 
 // StockData 股票市场数据（来自API）
@@ -67,6 +103,7 @@ const (
 	MarketChina    MarketType = "china"
 	MarketUS       MarketType = "us"
 	MarketHongKong MarketType = "hongkong"
+	MarketFX       MarketType = "fx" // 汇率（FX:USDCNY）
 )
 
 // TradingSession 交易时段
@@ -96,6 +133,15 @@ type Config struct {
 	Update             UpdateConfig             `yaml:"update"`              // 更新设置
 	Markets            MarketsConfig            `yaml:"markets"`             // 市场配置
 	IntradayCollection IntradayCollectionConfig `yaml:"intraday_collection"` // 分时数据采集配置
+	Providers          QuoteProvidersConfig     `yaml:"providers"`           // 行情数据源配置
+	Endpoints          EndpointsConfig          `yaml:"endpoints"`           // 数据源接口地址配置
+	Portfolio          PortfolioConfig          `yaml:"portfolio"`           // 持仓核算配置
+	Fees               FeesConfig               `yaml:"fees"`                // 交易费用配置
+	Currency           CurrencyConfig           `yaml:"currency"`            // 多币种汇总配置
+	Alerts             AlertsConfig             `yaml:"alerts"`              // 价格提醒通知配置
+	KLine              KLineConfig              `yaml:"kline"`               // 日K线配置
+	Indicators         IndicatorsConfig         `yaml:"indicators"`          // 技术指标参数
+	Calendar           CalendarConfig           `yaml:"calendar"`            // 交易日历（追加/覆盖内置休市日）
 }
 
 // SystemConfig 系统设置
@@ -123,6 +169,98 @@ type UpdateConfig struct {
 	AutoUpdate      bool `yaml:"auto_update"`      // 是否自动更新
 }
 
+// PortfolioConfig 持仓核算设置
+type PortfolioConfig struct {
+	LotMethod LotMethod `yaml:"lot_method"` // 卖出成本结转方法 "fifo", "lifo", "average"
+	Account   string    `yaml:"account"`    // 当前账户 "default"、自定义账户名或 "all"(合并视图)
+}
+
+// CurrencyConfig 多币种汇总设置
+type CurrencyConfig struct {
+	BaseCurrency     string `yaml:"base_currency"`      // 总计使用的基准货币 "CNY", "HKD", "USD"
+	FXRefreshMinutes int    `yaml:"fx_refresh_minutes"` // 汇率缓存刷新间隔（分钟）
+}
+
+// AlertsConfig 价格提醒通知设置（规则保存在 data/alerts.json）
+type AlertsConfig struct {
+	Bell            bool   `yaml:"bell"`             // 触发时终端响铃
+	WebhookURL      string `yaml:"webhook_url"`      // 触发时 POST JSON 的本地 webhook 地址（为空不发送）
+	CooldownMinutes int    `yaml:"cooldown_minutes"` // 规则默认冷却时间（分钟）
+}
+
+// KLineConfig 日K线设置（数据保存在 data/daily/<MARKET>/<CODE>.json）
+type KLineConfig struct {
+	Adjustment PriceAdjustment `yaml:"adjustment"` // 默认复权方式 "none", "forward", "backward"
+}
+
+// MarketCalendarConfig 单个市场的休市日和半日市（日期格式 "2006-01-02" 或 "20060102"）
+type MarketCalendarConfig struct {
+	Holidays    []string          `yaml:"holidays" json:"holidays"`         // 休市日
+	TradingDays []string          `yaml:"trading_days" json:"trading_days"` // 照常交易的日期（从内置休市日中移除）
+	HalfDays    map[string]string `yaml:"half_days" json:"half_days"`       // 半日市：日期 → 提前收盘时间 "HH:MM"
+}
+
+// CalendarConfig 交易日历配置（内置日历见 calendar/holidays.json）
+type CalendarConfig struct {
+	China    MarketCalendarConfig `yaml:"china" json:"china"`
+	US       MarketCalendarConfig `yaml:"us" json:"us"`
+	HongKong MarketCalendarConfig `yaml:"hongkong" json:"hongkong"`
+}
+
+// IndicatorsConfig 技术指标参数（分时图按分钟、日K线按日计算周期）
+type IndicatorsConfig struct {
+	Enabled         []Indicator `yaml:"enabled"`          // 默认显示的指标 "ma", "ema", "vwap", "boll", "macd", "rsi"
+	MAPeriods       []int       `yaml:"ma_periods"`       // 移动平均线周期
+	EMAPeriods      []int       `yaml:"ema_periods"`      // 指数移动平均线周期
+	BollingerPeriod int         `yaml:"bollinger_period"` // 布林带周期
+	BollingerWidth  float64     `yaml:"bollinger_width"`  // 布林带宽度（标准差倍数）
+	MACDFast        int         `yaml:"macd_fast"`        // MACD 快线 EMA 周期
+	MACDSlow        int         `yaml:"macd_slow"`        // MACD 慢线 EMA 周期
+	MACDSignal      int         `yaml:"macd_signal"`      // MACD 信号线 EMA 周期
+	RSIPeriod       int         `yaml:"rsi_period"`       // RSI 周期
+}
+
+// FeeSchedule 单个市场的交易费用规则（费率均按成交金额计算）
+type FeeSchedule struct {
+	CommissionRate     float64 `yaml:"commission_rate"`      // 佣金费率（0.00025 即万分之2.5）
+	MinCommission      float64 `yaml:"min_commission"`       // 单笔最低佣金
+	CommissionPerShare float64 `yaml:"commission_per_share"` // 每股佣金（美股按股收费的券商）
+	StampDutyRate      float64 `yaml:"stamp_duty_rate"`      // 印花税率
+	StampDutyOnBuy     bool    `yaml:"stamp_duty_on_buy"`    // 买入是否收取印花税（A股仅卖出收取，港股双边收取）
+	LevyRate           float64 `yaml:"levy_rate"`            // 其他规费费率（过户费、交易征费、交易所费用等，双边收取）
+}
+
+// FeesConfig 各市场交易费用配置
+type FeesConfig struct {
+	China    FeeSchedule `yaml:"china"`
+	US       FeeSchedule `yaml:"us"`
+	HongKong FeeSchedule `yaml:"hongkong"`
+}
+
+// QuoteProvidersConfig 行情数据源配置（每个市场按顺序尝试，列表中省略即禁用）
+type QuoteProvidersConfig struct {
+	China    []string `yaml:"china"`    // A股数据源顺序
+	US       []string `yaml:"us"`       // 美股数据源顺序
+	HongKong []string `yaml:"hongkong"` // 港股数据源顺序
+	FX       []string `yaml:"fx"`       // 汇率数据源顺序
+}
+
+// EndpointsConfig 数据源接口基础地址（scheme://host，留空使用官方地址）
+type EndpointsConfig struct {
+	Mock             bool   `yaml:"mock"`              // 启用内置模拟行情服务器（离线开发/测试）
+	TencentQuote     string `yaml:"tencent_quote"`     // 腾讯行情 https://qt.gtimg.cn
+	TencentSearch    string `yaml:"tencent_search"`    // 腾讯搜索 https://smartbox.gtimg.cn
+	TencentMinute    string `yaml:"tencent_minute"`    // 腾讯分时/日K线 http://ifzq.gtimg.cn
+	SinaSearch       string `yaml:"sina_search"`       // 新浪搜索 https://suggest3.sinajs.cn
+	SinaIntraday     string `yaml:"sina_intraday"`     // 新浪分时 http://money.finance.sina.com.cn
+	EastMoney        string `yaml:"eastmoney"`         // 东方财富 https://push2.eastmoney.com
+	EastMoneyHistory string `yaml:"eastmoney_history"` // 东方财富历史K线 https://push2his.eastmoney.com
+	Yahoo            string `yaml:"yahoo"`             // Yahoo Finance https://query1.finance.yahoo.com
+	TwelveData       string `yaml:"twelvedata"`        // TwelveData https://api.twelvedata.com
+	FMP              string `yaml:"fmp"`               // FMP https://financialmodelingprep.com
+	Nasdaq           string `yaml:"nasdaq"`            // Nasdaq 美股代码表 https://api.nasdaq.com
+}
+
 // TextMap 文本映射结构（用于i18n）
 type TextMap map[string]string
 
@@ -156,15 +294,57 @@ type Model struct {
 	editingStep        int
 	selectedStockIndex int
 
+	// For transaction ledger - 持仓交易记录
+	transactionCursor    int  // 交易记录列表光标位置
+	transactionInputMode bool // 是否正在输入新交易
+	sellingStep          int  // 卖出流程步骤（1:价格 2:数量 3:手续费）
+
+	// For multiple accounts - 账户切换
+	accountCursor    int            // 账户列表光标位置
+	accountInputMode bool           // 是否正在输入新账户名称
+	accountCounts    map[string]int // 各账户持仓数量
+
+	// For daily snapshots - 每日快照与净值曲线
+	snapshotRecorded map[string]bool     // 已写入的快照（账户|市场|日期）
+	equitySnapshots  []PortfolioSnapshot // 净值曲线使用的快照
+	equityRange      int                 // 日期范围索引（equityRanges）
+	equityOffset     int                 // 日期窗口向前平移的半窗口数
+
+	// For price alerts - 价格提醒
+	alerts          AlertList                 // 提醒规则
+	alertPrevPrices map[string]float64        // 上一次更新的价格（检测上穿/下穿成本价）
+	alertVolumes    map[string]*volumeTracker // 成交量增量（检测成交量放大）
+	alertBanner     []string                  // 提醒横幅内容
+	alertBannerTime time.Time                 // 最近一次触发时间
+	alertStockCode  string                    // 规则管理界面的股票代码
+	alertStockName  string                    // 规则管理界面的股票名称
+	alertCursor     int                       // 规则列表光标位置
+	alertInputMode  bool                      // 是否正在新建规则
+	alertTypeIndex  int                       // 新建规则的类型索引（alertTypes）
+
+	// For multi-currency totals - 汇率缓存
+	fxRates       FXRateTable // 货币对 -> 汇率
+	fxIsUpdating  bool        // 是否正在刷新汇率
+	fxLastAttempt time.Time   // 上次刷新汇率的时间（失败后限制重试频率）
+
 	// For stock searching
 	searchInput         string
 	searchInputCursor   int // 搜索输入光标位置
 	searchResult        *StockData
 	searchFromWatchlist bool // 标记是否从自选列表进入搜索
 
+	// 搜索结果候选列表
+	searchCandidates      []SearchCandidate
+	searchCandidateCursor int  // 当前选中的候选
+	searchCandidateScroll int  // 列表第一行对应的候选索引
+	symbolsRefreshing     bool // 是否正在更新本地代码表
+
 	// For language selection
 	languageCursor int
 
+	// For settings - 刷新设置
+	settingsCursor int
+
 	// For monitoring
 	lastUpdate time.Time
 
@@ -211,26 +391,49 @@ type Model struct {
 	stockPriceCache      map[string]*StockPriceCacheEntry // 股价数据缓存
 	stockPriceMutex      sync.RWMutex                     // 股价数据读写锁
 	stockPriceUpdateTime time.Time                        // 上次更新股价数据的时间
+	marketRefreshTime    map[MarketType]time.Time         // 各市场上次获取行情的时间（按市场调度刷新）
 
 	// For intraday data collection - 分时数据采集
 	intradayManager *IntradayManager // 分时数据管理器
 
 	// For intraday chart viewing - 分时图表查看
-	chartViewStock        string        // 正在查看的股票代码
-	chartViewStockName    string        // 股票名称
-	chartViewDate         string        // 正在查看的日期 (YYYYMMDD)
-	chartData             *IntradayData // 加载的分时数据
-	chartLoadError        error         // 加载错误(如有)
-	chartIsCollecting     bool          // 是否正在自动采集数据
-	chartCollectStartTime time.Time     // 开始采集的时间
+	chartViewStock        string             // 正在查看的股票代码
+	chartViewStockName    string             // 股票名称
+	chartViewDate         string             // 正在查看的日期 (YYYYMMDD)
+	chartData             *IntradayData      // 加载的分时数据
+	chartLoadError        error              // 加载错误(如有)
+	chartIsCollecting     bool               // 是否正在自动采集数据
+	chartCollectStartTime time.Time          // 开始采集的时间
+	chartHideVolume       bool               // 隐藏成交量副图（V 键切换）
+	chartCursor           int                // 十字光标所在的数据点索引
+	chartCursorVisible    bool               // 是否显示十字光标（H/L 键移动，ESC 隐藏）
+	chartIndicators       map[Indicator]bool // 显示的技术指标（数字键切换，分时图和日K线共用）
+
+	// For daily K-line viewing - 日K线图查看
+	klineStock      string          // 正在查看的股票代码
+	klineStockName  string          // 股票名称
+	klineData       *DailyData      // 本地日K线数据（不复权）
+	klineUpdating   bool            // 是否正在后台更新
+	klineError      error           // 最近一次更新失败的原因
+	klineAdjustment PriceAdjustment // 当前复权方式（F 键切换）
+	klineZoom       int             // 缩放级别索引（klineZoomLevels）
+	klineOffset     int             // 可见窗口相对最新K线向前平移的根数
+
+	// For multi-stock comparison - 多股票分时对比
+	compareSelected map[string]bool    // 选中的股票代码
+	compareCursor   int                // 选择界面光标（自选列表索引）
+	compareTag      string             // 最近一次按标签批量选择的标签
+	compareSeries   []comparisonSeries // 已加载的对比数据
+	compareMissing  []string           // 没有分时数据的股票名称
+	compareLoading  bool               // 是否正在加载
 
 	// For search mode intraday - 搜索模式临时分时数据
-	isSearchMode           bool          // 是否处于搜索模式（用于区分数据来源）
-	searchIntradayData     *IntradayData // 搜索模式的临时分时数据(仅内存)
-	searchIntradayWorker   chan struct{} // 临时 worker 停止信号
-	searchIntradayUpdateCh chan struct{} // 数据更新通知 channel
-	searchChartWidth       int           // 搜索图表宽度（响应式布局）
-	searchChartHeight      int           // 搜索图表高度
+	isSearchMode           bool                           // 是否处于搜索模式（用于区分数据来源）
+	searchIntradayData     *IntradayData                  // 搜索模式的临时分时数据(仅内存)
+	searchIntradayCancel   context.CancelFunc             // 停止临时 worker
+	searchIntradayUpdateCh <-chan searchIntradayUpdateMsg // 当前临时 worker 的数据更新 channel
+	searchChartWidth       int                            // 搜索图表宽度（响应式布局）
+	searchChartHeight      int                            // 搜索图表高度
 }
 
 // tickMsg 定时刷新消息
@@ -250,7 +453,10 @@ type checkDataAvailabilityMsg struct {
 }
 
 // searchIntradayUpdateMsg 搜索模式分时数据更新消息
-type searchIntradayUpdateMsg struct{}
+type searchIntradayUpdateMsg struct {
+	data    *IntradayData
+	updates <-chan searchIntradayUpdateMsg // 发送消息的 worker（用于忽略已停止的 worker 的数据）
+}
 
 // TimePoint 图表时间点数据
 type TimePoint struct {
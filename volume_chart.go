@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/linechart"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// 分时成交量副图
+// ============================================================================
+
+// volumeBarRunes 柱高按 1/8 格递增
+var volumeBarRunes = []rune(" ▁▂▃▄▅▆▇█")
+
+const (
+	intradayVolumeRows = 5 // 全屏分时图的成交量副图行数
+	searchVolumeRows   = 3 // 搜索模式嵌入图的成交量副图行数
+)
+
+// hasVolumeData 分时数据是否包含成交量（旧文件和部分数据源没有）
+func hasVolumeData(data *IntradayData) bool {
+	if data == nil {
+		return false
+	}
+	for _, dp := range data.Datapoints {
+		if dp.Volume > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// formatVolumeAxisLabel 副图纵轴标签（宽度有限，使用 K/M/B）
+func formatVolumeAxisLabel(volume int64) string {
+	switch {
+	case volume >= 1_000_000_000:
+		return fmt.Sprintf("%.1fB", float64(volume)/1e9)
+	case volume >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(volume)/1e6)
+	case volume >= 1_000:
+		return fmt.Sprintf("%.1fK", float64(volume)/1e3)
+	}
+	return fmt.Sprintf("%d", volume)
+}
+
+// trendStyle 涨跌颜色：A股红涨绿跌，非A股绿涨红跌
+func trendStyle(isAShare, up bool) lipgloss.Style {
+	if up == isAShare {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")) // 红色
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("10")) // 绿色
+}
+
+// volumeColumn 副图一列（可能包含多分钟）的成交量和涨跌
+type volumeColumn struct {
+	volume int64
+	up     bool
+}
+
+// buildVolumeColumns 将时间框架上的每分钟成交量按价格图绘图区宽度分列
+// 列与价格图的横坐标对应（时间框架索引 0..n-1 均匀映射到 width 列）；
+// 每列的涨跌按该列最后一分钟收盘价与前一列收盘价（单分钟有开盘价时与开盘价）比较
+func (m *Model) buildVolumeColumns(data *IntradayData, width int) []volumeColumn {
+	timeFramework := m.createFixedTimeRange(data.Date, data.Market)
+	n := len(timeFramework)
+	if n == 0 || width <= 0 {
+		return nil
+	}
+
+	points := make(map[string]IntradayDataPoint, len(data.Datapoints))
+	for _, dp := range data.Datapoints {
+		points[dp.Time] = dp
+	}
+
+	reference := data.PrevClose
+	if reference == 0 && len(data.Datapoints) > 0 {
+		reference = data.Datapoints[0].Price
+	}
+
+	columns := make([]volumeColumn, width)
+	for c := range columns {
+		start := c * n / width
+		end := max((c+1)*n/width, start+1)
+
+		column := volumeColumn{up: true}
+		var first, last IntradayDataPoint
+		found := false
+		for i := start; i < end && i < n; i++ {
+			dp, exists := points[timeFramework[i].Time.Format("15:04")]
+			if !exists {
+				continue
+			}
+			if !found {
+				first = dp
+				found = true
+			}
+			last = dp
+			column.volume += dp.Volume
+		}
+		if found {
+			base := reference
+			if start+1 == end && first.HasOHLC() {
+				base = first.Open
+			}
+			column.up = last.Price >= base
+			reference = last.Price
+		}
+		columns[c] = column
+	}
+	return columns
+}
+
+// renderVolumePanel 渲染价格图下方的成交量副图（纵轴和绘图区与价格图对齐）
+// 没有成交量数据时返回空字符串
+func (m *Model) renderVolumePanel(data *IntradayData, chart *linechart.Model, rows int) string {
+	if chart == nil || rows <= 0 || !hasVolumeData(data) {
+		return ""
+	}
+
+	columns := m.buildVolumeColumns(data, chart.GraphWidth())
+	isAShare := strings.HasPrefix(data.Code, "SH") || strings.HasPrefix(data.Code, "SZ")
+	return renderVolumeBars(columns, chart.Origin().X, rows, isAShare)
+}
+
+// renderVolumeBars 绘制成交量柱（左侧留出 origin 宽度的纵轴标签，柱高按最大量归一）
+// 分时图和日K线共用，全部为 0 时返回空字符串
+func renderVolumeBars(columns []volumeColumn, origin, rows int, isAShare bool) string {
+	var maxVolume int64
+	for _, column := range columns {
+		maxVolume = max(maxVolume, column.volume)
+	}
+	if maxVolume == 0 {
+		return ""
+	}
+
+	axisStyle := lipgloss.NewStyle()
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	// 纵轴顶部标注最大量（放不下时省略）
+	label := formatVolumeAxisLabel(maxVolume)
+	if len(label) > origin {
+		label = ""
+	}
+
+	lines := make([]string, rows)
+	for r := range lines {
+		var b strings.Builder
+		if r == 0 {
+			b.WriteString(labelStyle.Render(fmt.Sprintf("%*s", origin, label)))
+		} else {
+			b.WriteString(strings.Repeat(" ", origin))
+		}
+		b.WriteString(axisStyle.Render("│"))
+
+		// 本行底部对应的高度（以 1/8 格为单位）
+		rowBase := (rows - 1 - r) * 8
+		for _, column := range columns {
+			height := int(float64(column.volume) / float64(maxVolume) * float64(rows*8))
+			if column.volume > 0 {
+				height = max(height, 1)
+			}
+			fill := min(max(height-rowBase, 0), 8)
+			if fill == 0 {
+				b.WriteByte(' ')
+				continue
+			}
+			b.WriteString(trendStyle(isAShare, column.up).Render(string(volumeBarRunes[fill])))
+		}
+		lines[r] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// totalVolume 分时数据的总成交量
+func totalVolume(data *IntradayData) int64 {
+	var total int64
+	for _, dp := range data.Datapoints {
+		total += dp.Volume
+	}
+	return total
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRenderVolumePanel 测试成交量副图与价格图对齐，涨跌分列
+func TestRenderVolumePanel(t *testing.T) {
+	m := &Model{config: getDefaultConfig()}
+	m.chartData = &IntradayData{
+		Code: "SH600000", Name: "浦发银行", Date: "20250314", Market: MarketChina, PrevClose: 10,
+		Datapoints: []IntradayDataPoint{
+			{Time: "09:30", Price: 10.1, Volume: 1000},
+			{Time: "09:31", Price: 10.0, Volume: 4000},
+			{Time: "14:59", Price: 10.2, Volume: 2000},
+		},
+	}
+
+	chart := m.createIntradayChart(80, 30)
+	if chart == nil {
+		t.Fatal("分时图创建失败")
+	}
+
+	panel := m.renderVolumePanel(m.chartData, chart, intradayVolumeRows)
+	lines := strings.Split(panel, "\n")
+	if len(lines) != intradayVolumeRows {
+		t.Fatalf("副图行数 = %d, expected %d", len(lines), intradayVolumeRows)
+	}
+	if !strings.ContainsRune(lines[intradayVolumeRows-1], '█') {
+		t.Errorf("最底行应包含满格柱:\n%s", panel)
+	}
+
+	columns := m.buildVolumeColumns(m.chartData, chart.GraphWidth())
+	if len(columns) != chart.GraphWidth() {
+		t.Fatalf("列数 = %d, expected %d", len(columns), chart.GraphWidth())
+	}
+	if !columns[0].up || columns[0].volume == 0 {
+		t.Errorf("首列 = %+v, expected up with volume", columns[0])
+	}
+
+	// 没有成交量时不显示副图
+	m.chartData.Datapoints = []IntradayDataPoint{{Time: "09:30", Price: 10.1}}
+	if panel := m.renderVolumePanel(m.chartData, chart, intradayVolumeRows); panel != "" {
+		t.Errorf("无成交量时副图应为空:\n%s", panel)
+	}
+}
@@ -410,8 +410,17 @@ func (m *Model) isStockInWatchlist(code string) bool {
 	return false
 }
 
-// addToWatchlist 添加股票到自选列表
+// addToWatchlist 添加股票到自选列表并保存
 func (m *Model) addToWatchlist(code, name string) bool {
+	if !m.insertWatchlistStock(code, name) {
+		return false
+	}
+	m.saveWatchlist()
+	return true
+}
+
+// insertWatchlistStock 将股票插入自选列表首位（不保存），已在列表中时返回 false
+func (m *Model) insertWatchlistStock(code, name string) bool {
 	if m.isStockInWatchlist(code) {
 		return false // 已在列表中
 	}
@@ -429,18 +438,18 @@ func (m *Model) addToWatchlist(code, name string) bool {
 	m.watchlist.Stocks = append([]WatchlistStock{watchStock}, m.watchlist.Stocks...)
 	m.invalidateWatchlistCache() // 使缓存失效
 	m.watchlistIsSorted = false  // 添加自选股票后重置自选列表排序状态
-	m.saveWatchlist()
 	return true
 }
 
-// removeFromWatchlist 从自选列表删除股票
-func (m *Model) removeFromWatchlist(index int) {
-	if index >= 0 && index < len(m.watchlist.Stocks) {
-		m.watchlist.Stocks = append(m.watchlist.Stocks[:index], m.watchlist.Stocks[index+1:]...)
-		m.invalidateWatchlistCache() // 使缓存失效
-		m.saveWatchlist()
-		m.watchlistIsSorted = false // 删除自选股票后重置自选列表排序状态
+// removeFromWatchlist 从自选列表删除股票并保存
+func (m *Model) removeFromWatchlist(index int) error {
+	if index < 0 || index >= len(m.watchlist.Stocks) {
+		return nil
 	}
+	m.watchlist.Stocks = append(m.watchlist.Stocks[:index], m.watchlist.Stocks[index+1:]...)
+	m.invalidateWatchlistCache() // 使缓存失效
+	m.watchlistIsSorted = false  // 删除自选股票后重置自选列表排序状态
+	return m.saveWatchlist()
 }
 
 // ============================================================================